@@ -0,0 +1,175 @@
+/*
+Package aura converts between Go values and the text forms Hoon gives atoms
+of its other common auras, as its scot and slav do.
+
+Each aura has a type holding the atom, with a String method that renders it as
+scot does and a Parse function that reads it as slav does, accepting the same
+non-canonical forms slav accepts.
+*/
+package aura
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strings"
+)
+
+// scanner reads the text form of an atom from left to right.
+type scanner struct {
+	s string
+	i int
+}
+
+func (sc *scanner) done() bool {
+
+	return sc.i == len(sc.s)
+}
+
+// literal consumes lit if it is next.
+func (sc *scanner) literal(lit string) bool {
+
+	if len(sc.s)-sc.i < len(lit) || sc.s[sc.i:sc.i+len(lit)] != lit {
+		return false
+	}
+	sc.i += len(lit)
+
+	return true
+}
+
+// digits consumes a run of decimal digits and returns it.
+func (sc *scanner) digits() string {
+
+	start := sc.i
+	for sc.i < len(sc.s) && sc.s[sc.i] >= '0' && sc.s[sc.i] <= '9' {
+		sc.i++
+	}
+
+	return sc.s[start:sc.i]
+}
+
+// frac consumes the fraction of a second that may end a @da or @dr, two dots
+// and one to four groups of four hex digits, and returns it as a 64-bit
+// fraction. It returns zero if there is none.
+func (sc *scanner) frac() (uint64, bool) {
+
+	if !sc.literal("..") {
+		return 0, true
+	}
+
+	var f uint64
+	for i := 0; i == 0 || sc.literal("."); i++ {
+		q, ok := sc.hexQuad()
+		if !ok || i == 4 {
+			return 0, false
+		}
+		f |= q << uint(48-16*i)
+	}
+
+	return f, true
+}
+
+// hexQuad consumes exactly four lower case hex digits and returns their value.
+func (sc *scanner) hexQuad() (uint64, bool) {
+
+	if len(sc.s)-sc.i < 4 {
+		return 0, false
+	}
+
+	var v uint64
+	for _, c := range []byte(sc.s[sc.i : sc.i+4]) {
+		switch {
+		case c >= '0' && c <= '9':
+			v = v<<4 | uint64(c-'0')
+		case c >= 'a' && c <= 'f':
+			v = v<<4 | uint64(c-'a'+10)
+		default:
+			return 0, false
+		}
+	}
+	sc.i += 4
+
+	return v, true
+}
+
+// decimal returns the value of a run of digits, or false if it does not fit
+// in a uint64.
+func decimal(ds string) (uint64, bool) {
+
+	if ds == "" {
+		return 0, false
+	}
+
+	var v uint64
+	for _, c := range []byte(ds) {
+		d := uint64(c - '0')
+		if v > (1<<64-1-d)/10 {
+			return 0, false
+		}
+		v = v*10 + d
+	}
+
+	return v, true
+}
+
+// writeFrac writes a 64-bit fraction of a second as scot does: two dots and
+// groups of four hex digits, leaving out those that are zero at the end.
+// Nothing is written for zero.
+func writeFrac(b *strings.Builder, f uint64) {
+
+	if f == 0 {
+		return
+	}
+
+	b.WriteString(".")
+	for ; f != 0; f <<= 16 {
+		fmt.Fprintf(b, ".%04x", f>>48)
+	}
+}
+
+// fracFromNanos returns ns nanoseconds as a 64-bit fraction of a second: ns
+// times 2^64 over 10^9, rounded.
+func fracFromNanos(ns uint64) uint64 {
+
+	f, rem := bits.Div64(ns, 0, 1e9)
+	if rem >= 5e8 {
+		f++
+	}
+
+	return f
+}
+
+// nanosFromFrac returns a 64-bit fraction of a second in nanoseconds,
+// rounded, which for fractions very near a second is 10^9.
+func nanosFromFrac(f uint64) uint64 {
+
+	ns, lo := bits.Mul64(f, 1e9)
+	if lo >= 1<<63 {
+		ns++
+	}
+
+	return ns
+}
+
+// splitAtom returns the high and low 64 bits of a 128-bit atom, or false if a
+// is not one.
+func splitAtom(a *big.Int) (hi, lo uint64, ok bool) {
+
+	if a == nil || a.Sign() < 0 || a.BitLen() > 128 {
+		return 0, 0, false
+	}
+
+	l := big.NewInt(0).SetUint64(1<<64 - 1)
+	l.And(l, a)
+
+	return big.NewInt(0).Rsh(a, 64).Uint64(), l.Uint64(), true
+}
+
+// joinAtom returns the 128-bit atom with the given high and low 64 bits.
+func joinAtom(hi, lo uint64) *big.Int {
+
+	a := big.NewInt(0).SetUint64(hi)
+	a.Lsh(a, 64)
+
+	return a.Or(a, big.NewInt(0).SetUint64(lo))
+}
@@ -0,0 +1,240 @@
+package aura
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strings"
+	"time"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+const (
+	auraDa string = "da"
+
+	// yearOffset is the year Hoon counts 1 BC as, counting from the first year
+	// of its dates, 292,277,024,401 BC, as year 0.
+	yearOffset int64 = 292277024400
+
+	secondsPerDay uint64 = 86400
+)
+
+// Da is an absolute date, a Hoon @da: a 128-bit atom whose high 64 bits count
+// the seconds since the start of 292,277,024,401 BC and whose low 64 bits are
+// the fraction of a second, in units of 2^-64 seconds. Dates follow the
+// Gregorian calendar, extended back before its adoption.
+type Da struct {
+	Sec  uint64
+	Frac uint64
+}
+
+// UnixEpoch is the @da of the start of 1970 in UTC, ~1970.1.1.
+var UnixEpoch = Da{Sec: 0x8000000cce9e0d80}
+
+// DaFromTime returns the @da of t. Times from before 292,277,024,401 BC have
+// none. Nanoseconds are rounded to the nearest fraction of a second, so that
+// Time gives t back.
+func DaFromTime(t time.Time) (Da, error) {
+
+	t = t.UTC()
+	y, m, d := t.Date()
+	year := int64(y) + yearOffset
+	if year < 0 {
+		return Da{}, fmt.Errorf(ugi.ErrAuraRange, t, "@"+auraDa)
+	}
+
+	days := uint64(daysFromCivil(year, int64(m), int64(d)))
+	hi, sec := bits.Mul64(days, secondsPerDay)
+	sec, carry := bits.Add64(sec, uint64(t.Hour()*3600+t.Minute()*60+t.Second()), 0)
+	if hi != 0 || carry != 0 {
+		return Da{}, fmt.Errorf(ugi.ErrAuraRange, t, "@"+auraDa)
+	}
+
+	return Da{sec, fracFromNanos(uint64(t.Nanosecond()))}, nil
+}
+
+// Time returns d as a time in UTC, rounded to the nearest nanosecond. The
+// earliest dates, before 292,277,022,401 BC, are beyond the range of
+// time.Time.
+func (d Da) Time() (time.Time, error) {
+
+	sec, ns := d.Sec, nanosFromFrac(d.Frac)
+	if ns == 1e9 {
+		if sec == 1<<64-1 {
+			return time.Time{}, fmt.Errorf(ugi.ErrAuraRange, d, "time.Time")
+		}
+		sec, ns = sec+1, 0
+	}
+
+	year, m, day := civilFromDays(int64(sec / secondsPerDay))
+	rem := int(sec % secondsPerDay)
+	t := time.Date(int(year-yearOffset), time.Month(m), int(day), rem/3600, rem/60%60, rem%60, int(ns), time.UTC)
+
+	// time.Date wraps around silently beyond its range.
+	if back, err := DaFromTime(t); err != nil || back.Sec != sec {
+		return time.Time{}, fmt.Errorf(ugi.ErrAuraRange, d, "time.Time")
+	}
+
+	return t, nil
+}
+
+// DaFromAtom returns the @da of a, which must fit in 128 bits.
+func DaFromAtom(a *big.Int) (Da, error) {
+
+	sec, frac, ok := splitAtom(a)
+	if !ok {
+		return Da{}, fmt.Errorf(ugi.ErrAuraRange, a, "@"+auraDa)
+	}
+
+	return Da{sec, frac}, nil
+}
+
+// Atom returns d as an integer.
+func (d Da) Atom() *big.Int {
+
+	return joinAtom(d.Sec, d.Frac)
+}
+
+// String renders d as scot does, such as ~2024.3.15..08.30.00..1a2b. Years BC
+// end in a dash, as in ~44-.3.15. The time is left out at midnight, and the
+// fraction of a second, in groups of four hex digits without those that are
+// zero at the end, when it is zero.
+func (d Da) String() string {
+
+	year, m, day := civilFromDays(int64(d.Sec / secondsPerDay))
+
+	var b strings.Builder
+	if year > yearOffset {
+		fmt.Fprintf(&b, "~%d.%d.%d", year-yearOffset, m, day)
+	} else {
+		fmt.Fprintf(&b, "~%d-.%d.%d", yearOffset+1-year, m, day)
+	}
+
+	if rem := d.Sec % secondsPerDay; rem != 0 || d.Frac != 0 {
+		fmt.Fprintf(&b, "..%02d.%02d.%02d", rem/3600, rem/60%60, rem%60)
+	}
+	writeFrac(&b, d.Frac)
+
+	return b.String()
+}
+
+// ParseDa reads a @da as slav does. As well as the form String gives it takes
+// a zero time, hours, minutes and seconds of any number of digits, and days
+// past the end of the month, all of which carry over, and year 0, which is
+// 1 BC.
+func ParseDa(s string) (Da, error) {
+
+	invalid := fmt.Errorf(ugi.ErrInvalidAura, auraDa, s)
+	sc := scanner{s: s}
+
+	if !sc.literal("~") {
+		return Da{}, invalid
+	}
+	ys := sc.digits()
+	y, ok := decimal(ys)
+	if !ok || len(ys) > 1 && ys[0] == '0' {
+		return Da{}, invalid
+	}
+	ad := !sc.literal("-")
+
+	// Months are 1 to 12 and days 1 to 99, without leading zeros.
+	var md [2]uint64
+	for i, most := range []uint64{12, 99} {
+		if !sc.literal(".") {
+			return Da{}, invalid
+		}
+		ds := sc.digits()
+		v, ok := decimal(ds)
+		if !ok || ds[0] == '0' || v > most {
+			return Da{}, invalid
+		}
+		md[i] = v
+	}
+
+	var hms [3]uint64
+	var frac uint64
+	if sc.literal("..") {
+		for i := range hms {
+			if i > 0 && !sc.literal(".") {
+				return Da{}, invalid
+			}
+			if hms[i], ok = decimal(sc.digits()); !ok {
+				return Da{}, invalid
+			}
+		}
+		if frac, ok = sc.frac(); !ok {
+			return Da{}, invalid
+		}
+	}
+	if !sc.done() {
+		return Da{}, invalid
+	}
+
+	// Years are bounded well beyond the last @da, so that the days from the
+	// first year fit in an int64.
+	var year int64
+	switch {
+	case ad && y <= 1<<40:
+		year = yearOffset + int64(y)
+	case !ad && y >= 1 && y-1 <= uint64(yearOffset):
+		year = yearOffset - int64(y-1)
+	default:
+		return Da{}, invalid
+	}
+
+	sec := big.NewInt(daysFromCivil(year, int64(md[0]), int64(md[1])))
+	sec.Mul(sec, big.NewInt(int64(secondsPerDay)))
+	for i, unit := range []int64{3600, 60, 1} {
+		part := big.NewInt(0).SetUint64(hms[i])
+		sec.Add(sec, part.Mul(part, big.NewInt(unit)))
+	}
+	if sec.BitLen() > 64 {
+		return Da{}, invalid
+	}
+
+	return Da{sec.Uint64(), frac}, nil
+}
+
+// daysFromCivil returns the number of days from the start of Hoon's year 0 to
+// the given date. Days past the end of the month carry over.
+func daysFromCivil(year, month, day int64) int64 {
+
+	// Years are counted from March, so that leap days fall at the end, and
+	// from 400 years before year 0, so that they are never negative.
+	y := year + 400
+	if month <= 2 {
+		y--
+	}
+	era := y / 400
+	yoe := y - era*400
+	doy := (153*((month+9)%12)+2)/5 + day - 1
+	doe := yoe*365 + yoe/4 - yoe/100 + doy
+
+	// Year 0 is a leap year, so its 1 March is day 60.
+	return (era-1)*146097 + doe + 60
+}
+
+// civilFromDays returns the date the given number of days after the start of
+// Hoon's year 0.
+func civilFromDays(days int64) (year, month, day int64) {
+
+	z := days - 60 + 146097
+	era := z / 146097
+	doe := z - era*146097
+	yoe := (doe - doe/1460 + doe/36524 - doe/146096) / 365
+	doy := doe - (365*yoe + yoe/4 - yoe/100)
+	mp := (5*doy + 2) / 153
+
+	day = doy - (153*mp+2)/5 + 1
+	month = mp + 3
+	if month > 12 {
+		month -= 12
+	}
+	year = yoe + era*400 - 400
+	if month <= 2 {
+		year++
+	}
+
+	return year, month, day
+}
@@ -0,0 +1,145 @@
+package aura
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestDaString(t *testing.T) {
+
+	tests := []struct {
+		d    Da
+		want string
+	}{
+		{Da{}, "~292277024401-.1.1"},
+		{UnixEpoch, "~1970.1.1"},
+		{Da{UnixEpoch.Sec + 1, 0x8000 << 48}, "~1970.1.1..00.00.01..8000"},
+		{Da{UnixEpoch.Sec + 86399, 0x1a2b << 32}, "~1970.1.1..23.59.59..0000.1a2b"},
+		{Da{UnixEpoch.Sec - 1, 1}, "~1969.12.31..23.59.59..0000.0000.0000.0001"},
+		{Da{UnixEpoch.Sec + 11016*86400, 0}, "~2000.2.29"},
+		{Da{1<<64 - 1, 1<<64 - 1}, "~292277024853.11.8..07.00.15..ffff.ffff.ffff.ffff"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.String(); got != tt.want {
+			t.Errorf("%#v: got %s, want %s", tt.d, got, tt.want)
+		}
+		d, err := ParseDa(tt.want)
+		if err != nil || d != tt.d {
+			t.Errorf("%s: parsed as %#v, %v, want %#v", tt.want, d, err, tt.d)
+		}
+	}
+}
+
+func TestParseDa(t *testing.T) {
+
+	// Forms slav accepts that scot does not give.
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"~2024.3.15..00.00.00", "~2024.3.15"},
+		{"~2024.3.15..8.30.0", "~2024.3.15..08.30.00"},
+		{"~2024.3.15..23.59.60", "~2024.3.16"},
+		{"~2024.3.15..48.00.00", "~2024.3.17"},
+		{"~2024.2.30", "~2024.3.1"},
+		{"~2023.12.99", "~2024.3.8"},
+		{"~2024.3.15..00.00.00..1a2b.0000", "~2024.3.15..00.00.00..1a2b"},
+		{"~2024.3.15..00.00.00..0000", "~2024.3.15"},
+		{"~0.1.1", "~1-.1.1"},
+		{"~44-.3.15", "~44-.3.15"},
+	}
+	for _, tt := range tests {
+		d, err := ParseDa(tt.s)
+		if err != nil {
+			t.Errorf("%s: %v", tt.s, err)
+			continue
+		}
+		if got := d.String(); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.s, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{
+		"", "~", "2024.3.15", "~2024", "~2024.3", "~2024-3-15", "~2024.3.15 ",
+		"~02024.3.15", "~2024.03.15", "~2024.13.1", "~2024.0.1", "~2024.3.05",
+		"~2024.3.0", "~2024.3.100", "~0-.1.1", "~292277024402-.1.1",
+		"~292277024853.11.9", "~2024.3.15..", "~2024.3.15..08.30",
+		"~2024.3.15..08.30.00..", "~2024.3.15..08.30.00..1a2",
+		"~2024.3.15..08.30.00..1A2B", "~2024.3.15..08.30.00..1a2b.",
+		"~2024.3.15..08.30.00..0000.0000.0000.0000.0001",
+		"~2024.3.15..99999999999999999999.00.00",
+	} {
+		if d, err := ParseDa(s); err == nil {
+			t.Errorf("%q: got %s, expected an error", s, d)
+		}
+	}
+}
+
+func TestDaTime(t *testing.T) {
+
+	times := []time.Time{
+		time.Unix(0, 0),
+		time.Unix(-1, 999999999),
+		time.Date(2024, 3, 15, 8, 30, 0, 102203369, time.UTC),
+		time.Date(2024, 3, 15, 8, 30, 0, 1, time.FixedZone("UTC+5", 5*3600)),
+		time.Date(-43, 3, 15, 12, 0, 0, 0, time.UTC),
+		time.Date(1e9, 1, 1, 0, 0, 0, 999999999, time.UTC),
+	}
+	for _, tm := range times {
+		d, err := DaFromTime(tm)
+		if err != nil {
+			t.Errorf("%v: %v", tm, err)
+			continue
+		}
+		back, err := d.Time()
+		if err != nil || !back.Equal(tm) || back.Location() != time.UTC {
+			t.Errorf("%v: became %s and %v, %v", tm, d, back, err)
+		}
+	}
+
+	d, err := DaFromTime(time.Date(2024, 3, 15, 8, 30, 0, 0, time.FixedZone("UTC-1", -3600)))
+	if err != nil || d.String() != "~2024.3.15..09.30.00" {
+		t.Errorf("zone: got %s, %v", d, err)
+	}
+
+	// The fraction 0.5 is exactly 500ms; 2^-64 seconds rounds to nothing.
+	tm, err := Da{UnixEpoch.Sec, 1 << 63}.Time()
+	if err != nil || !tm.Equal(time.Unix(0, 5e8)) {
+		t.Errorf("half a second: got %v, %v", tm, err)
+	}
+	tm, err = Da{UnixEpoch.Sec, 1}.Time()
+	if err != nil || !tm.Equal(time.Unix(0, 0)) {
+		t.Errorf("2^-64 seconds: got %v, %v", tm, err)
+	}
+	tm, err = Da{UnixEpoch.Sec, 1<<64 - 1}.Time()
+	if err != nil || !tm.Equal(time.Unix(1, 0)) {
+		t.Errorf("almost a second: got %v, %v", tm, err)
+	}
+
+	if tm, err := (Da{}).Time(); err == nil {
+		t.Errorf("first @da: got %v, expected an error", tm)
+	}
+}
+
+func TestDaAtom(t *testing.T) {
+
+	epoch, _ := big.NewInt(0).SetString("8000000cce9e0d800000000000000000", 16)
+	if got := UnixEpoch.Atom(); got.Cmp(epoch) != 0 {
+		t.Errorf("epoch: got %x", got)
+	}
+
+	max, _ := big.NewInt(0).SetString("ffffffffffffffffffffffffffffffff", 16)
+	for _, a := range []*big.Int{big.NewInt(0), big.NewInt(1), epoch, max} {
+		d, err := DaFromAtom(a)
+		if err != nil || d.Atom().Cmp(a) != 0 {
+			t.Errorf("%x: got %#v, %v", a, d, err)
+		}
+	}
+
+	for _, a := range []*big.Int{nil, big.NewInt(-1), big.NewInt(0).Add(max, big.NewInt(1))} {
+		if _, err := DaFromAtom(a); err == nil {
+			t.Errorf("%v: expected an error", a)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package aura
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+const auraDr string = "dr"
+
+// Dr is a relative duration, a Hoon @dr: a 128-bit atom whose high 64 bits
+// count seconds and whose low 64 bits are the fraction of a second, in units
+// of 2^-64 seconds.
+type Dr struct {
+	Sec  uint64
+	Frac uint64
+}
+
+// DrFromDuration returns the @dr of d, which must not be negative.
+// Nanoseconds are rounded to the nearest fraction of a second, so that
+// Duration gives d back.
+func DrFromDuration(d time.Duration) (Dr, error) {
+
+	if d < 0 {
+		return Dr{}, fmt.Errorf(ugi.ErrAuraRange, d, "@"+auraDr)
+	}
+
+	return Dr{uint64(d / time.Second), fracFromNanos(uint64(d % time.Second))}, nil
+}
+
+// Duration returns d rounded to the nearest nanosecond. Durations of more than
+// about 292 years are beyond the range of time.Duration.
+func (d Dr) Duration() (time.Duration, error) {
+
+	ns := nanosFromFrac(d.Frac)
+	if d.Sec > (math.MaxInt64-ns)/1e9 {
+		return 0, fmt.Errorf(ugi.ErrAuraRange, d, "time.Duration")
+	}
+
+	return time.Duration(d.Sec)*time.Second + time.Duration(ns), nil
+}
+
+// DrFromAtom returns the @dr of a, which must fit in 128 bits.
+func DrFromAtom(a *big.Int) (Dr, error) {
+
+	sec, frac, ok := splitAtom(a)
+	if !ok {
+		return Dr{}, fmt.Errorf(ugi.ErrAuraRange, a, "@"+auraDr)
+	}
+
+	return Dr{sec, frac}, nil
+}
+
+// Atom returns d as an integer.
+func (d Dr) Atom() *big.Int {
+
+	return joinAtom(d.Sec, d.Frac)
+}
+
+// drUnits are the units of a @dr in seconds, largest first.
+var drUnits = []struct {
+	name byte
+	sec  uint64
+}{
+	{'d', 86400},
+	{'h', 3600},
+	{'m', 60},
+	{'s', 1},
+}
+
+// String renders d as scot does, such as ~h1.m30 or ~s10..8000: the days,
+// hours, minutes and seconds that are not zero, then the fraction of a second
+// as for a @da. Zero is ~s0.
+func (d Dr) String() string {
+
+	var b strings.Builder
+	b.WriteString("~")
+
+	sec := d.Sec
+	for _, u := range drUnits {
+		n := sec / u.sec
+		sec %= u.sec
+		if n == 0 {
+			continue
+		}
+		if b.Len() > 1 {
+			b.WriteString(".")
+		}
+		fmt.Fprintf(&b, "%c%d", u.name, n)
+	}
+	if b.Len() == 1 {
+		b.WriteString("s0")
+	}
+	writeFrac(&b, d.Frac)
+
+	return b.String()
+}
+
+// ParseDr reads a @dr as slav does. As well as the form String gives it takes
+// units in any order, repeated and beyond the next larger unit, such as ~m90
+// or ~s1.s1, which add up, and units of zero.
+func ParseDr(s string) (Dr, error) {
+
+	invalid := fmt.Errorf(ugi.ErrInvalidAura, auraDr, s)
+	sc := scanner{s: s}
+
+	if !sc.literal("~") {
+		return Dr{}, invalid
+	}
+
+	sec := big.NewInt(0)
+	for {
+		var unit uint64
+		for _, u := range drUnits {
+			if sc.literal(string(u.name)) {
+				unit = u.sec
+				break
+			}
+		}
+		ds := sc.digits()
+		n, ok := decimal(ds)
+		if unit == 0 || !ok || len(ds) > 1 && ds[0] == '0' {
+			return Dr{}, invalid
+		}
+		part := big.NewInt(0).SetUint64(n)
+		sec.Add(sec, part.Mul(part, big.NewInt(0).SetUint64(unit)))
+
+		// A single dot comes before the next unit, and two before the
+		// fraction.
+		if strings.HasPrefix(sc.s[sc.i:], "..") || !sc.literal(".") {
+			break
+		}
+	}
+
+	frac, ok := sc.frac()
+	if !ok || !sc.done() || sec.BitLen() > 64 {
+		return Dr{}, invalid
+	}
+
+	return Dr{sec.Uint64(), frac}, nil
+}
@@ -0,0 +1,130 @@
+package aura
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestDrString(t *testing.T) {
+
+	tests := []struct {
+		d    Dr
+		want string
+	}{
+		{Dr{}, "~s0"},
+		{Dr{0, 0x8000 << 48}, "~s0..8000"},
+		{Dr{10, 0x8000 << 48}, "~s10..8000"},
+		{Dr{5400, 0}, "~h1.m30"},
+		{Dr{3600, 0x1a2b << 32}, "~h1..0000.1a2b"},
+		{Dr{86400 + 1, 0}, "~d1.s1"},
+		{Dr{86400*400 + 3600*23 + 60*59 + 59, 1}, "~d400.h23.m59.s59..0000.0000.0000.0001"},
+		{Dr{1<<64 - 1, 1<<64 - 1}, "~d213503982334601.h7.s15..ffff.ffff.ffff.ffff"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.String(); got != tt.want {
+			t.Errorf("%#v: got %s, want %s", tt.d, got, tt.want)
+		}
+		d, err := ParseDr(tt.want)
+		if err != nil || d != tt.d {
+			t.Errorf("%s: parsed as %#v, %v, want %#v", tt.want, d, err, tt.d)
+		}
+	}
+}
+
+func TestParseDr(t *testing.T) {
+
+	// Forms slav accepts that scot does not give.
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"~m90", "~h1.m30"},
+		{"~s1.s1", "~s2"},
+		{"~s30.m1", "~m1.s30"},
+		{"~h0.m0", "~s0"},
+		{"~d1.s0", "~d1"},
+		{"~h25", "~d1.h1"},
+		{"~s1..8000.0000", "~s1..8000"},
+		{"~s1..0000", "~s1"},
+	}
+	for _, tt := range tests {
+		d, err := ParseDr(tt.s)
+		if err != nil {
+			t.Errorf("%s: %v", tt.s, err)
+			continue
+		}
+		if got := d.String(); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.s, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{
+		"", "~", "s1", "~s", "~1", "~x1", "~S1", "~s01", "~s1.", "~s1.m", "~s1..",
+		"~..8000", "~s1..800", "~s1..8000.", "~s1...8000", "~s1..8000.0000.0000.0000.0001",
+		"~s1 ", "~s18446744073709551616", "~d213503982334602",
+	} {
+		if d, err := ParseDr(s); err == nil {
+			t.Errorf("%q: got %s, expected an error", s, d)
+		}
+	}
+}
+
+func TestDrDuration(t *testing.T) {
+
+	durations := []time.Duration{
+		0,
+		time.Nanosecond,
+		500 * time.Millisecond,
+		90 * time.Minute,
+		36*time.Hour + 999999999,
+		math.MaxInt64,
+	}
+	for _, dur := range durations {
+		d, err := DrFromDuration(dur)
+		if err != nil {
+			t.Errorf("%v: %v", dur, err)
+			continue
+		}
+		back, err := d.Duration()
+		if err != nil || back != dur {
+			t.Errorf("%v: became %s and %v, %v", dur, d, back, err)
+		}
+	}
+
+	if d, err := DrFromDuration(90 * time.Minute); err != nil || d.String() != "~h1.m30" {
+		t.Errorf("90m: got %s, %v", d, err)
+	}
+	if d, err := DrFromDuration(10*time.Second + 500*time.Millisecond); err != nil || d.String() != "~s10..8000" {
+		t.Errorf("10.5s: got %s, %v", d, err)
+	}
+	if d, err := DrFromDuration(-time.Second); err == nil {
+		t.Errorf("-1s: got %s, expected an error", d)
+	}
+
+	dur, err := Dr{0, 1<<64 - 1}.Duration()
+	if err != nil || dur != time.Second {
+		t.Errorf("almost a second: got %v, %v", dur, err)
+	}
+	for _, d := range []Dr{{1 << 63, 0}, {math.MaxInt64 / 1000000000, 1<<64 - 1}} {
+		if dur, err := d.Duration(); err == nil {
+			t.Errorf("%s: got %v, expected an error", d, dur)
+		}
+	}
+}
+
+func TestDrAtom(t *testing.T) {
+
+	a, _ := big.NewInt(0).SetString("5a8800000000000000", 16)
+	d, err := DrFromAtom(a)
+	if err != nil || d != (Dr{0x5a, 0x88 << 56}) || d.Atom().Cmp(a) != 0 {
+		t.Errorf("got %#v, %v", d, err)
+	}
+
+	for _, a := range []*big.Int{nil, big.NewInt(-1), big.NewInt(0).Lsh(big.NewInt(1), 128)} {
+		if _, err := DrFromAtom(a); err == nil {
+			t.Errorf("%v: expected an error", a)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package aura
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+// number describes one of the auras that write an unsigned integer in dotted
+// groups of digits: after the prefix, the first group has one to size digits
+// and no leading zeros, and each group after it exactly size. Zero is written
+// as the prefix and a single 0.
+type number struct {
+	aura   string
+	prefix string
+	base   int
+	digits string
+	size   int
+}
+
+var (
+	ud = number{"ud", "", 10, "0123456789", 3}
+	ux = number{"ux", "0x", 16, "0123456789abcdef", 4}
+	ub = number{"ub", "0b", 2, "01", 4}
+	uv = number{"uv", "0v", 32, "0123456789abcdefghijklmnopqrstuv", 5}
+	uw = number{"uw", "0w", 64, "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ-~", 5}
+)
+
+// text returns the digits of n in the aura's alphabet. Up to base 36 it is
+// the one big.Int uses.
+func (a number) text(n *big.Int) string {
+
+	if a.base <= 36 {
+		return n.Text(a.base)
+	}
+	if n.Sign() == 0 {
+		return a.digits[:1]
+	}
+
+	var ds []byte
+	v, d := big.NewInt(0).Set(n), big.NewInt(0)
+	base := big.NewInt(int64(a.base))
+	for v.Sign() > 0 {
+		v.DivMod(v, base, d)
+		ds = append(ds, a.digits[d.Int64()])
+	}
+	for i, j := 0, len(ds)-1; i < j; i, j = i+1, j-1 {
+		ds[i], ds[j] = ds[j], ds[i]
+	}
+
+	return string(ds)
+}
+
+// value returns the number written by digits in the aura's alphabet.
+func (a number) value(digits string) (*big.Int, bool) {
+
+	if a.base <= 36 {
+		return big.NewInt(0).SetString(digits, a.base)
+	}
+
+	v := big.NewInt(0)
+	base := big.NewInt(int64(a.base))
+	for _, c := range []byte(digits) {
+		d := strings.IndexByte(a.digits, c)
+		if d < 0 {
+			return nil, false
+		}
+		v.Mul(v, base).Add(v, big.NewInt(int64(d)))
+	}
+
+	return v, true
+}
+
+// format writes n, which must not be negative, in the aura's dotted form.
+func (a number) format(n *big.Int) string {
+
+	digits := a.text(n)
+
+	var b strings.Builder
+	b.WriteString(a.prefix)
+	first := len(digits) % a.size
+	if first == 0 {
+		first = a.size
+	}
+	b.WriteString(digits[:first])
+	for i := first; i < len(digits); i += a.size {
+		b.WriteString(".")
+		b.WriteString(digits[i : i+a.size])
+	}
+
+	return b.String()
+}
+
+// parse reads s in the aura's dotted form, rejecting any other grouping.
+func (a number) parse(s string) (*big.Int, error) {
+
+	invalid := fmt.Errorf(ugi.ErrInvalidAura, a.aura, s)
+	if !strings.HasPrefix(s, a.prefix) {
+		return nil, invalid
+	}
+
+	groups := strings.Split(s[len(a.prefix):], ".")
+	first := groups[0]
+	if first == "" || len(first) > a.size || first[0] == '0' && (len(first) > 1 || len(groups) > 1) {
+		return nil, invalid
+	}
+	for _, g := range groups[1:] {
+		if len(g) != a.size {
+			return nil, invalid
+		}
+	}
+
+	digits := strings.Join(groups, "")
+	if strings.Trim(digits, a.digits) != "" {
+		return nil, invalid
+	}
+	v, ok := a.value(digits)
+	if !ok {
+		return nil, invalid
+	}
+
+	return v, nil
+}
+
+// parseUint64 reads s as parse does, requiring that it fit in a uint64.
+func (a number) parseUint64(s string) (uint64, error) {
+
+	v, err := a.parse(s)
+	if err != nil {
+		return 0, err
+	}
+	if !v.IsUint64() {
+		return 0, fmt.Errorf(ugi.ErrAuraRange, s, "uint64")
+	}
+
+	return v.Uint64(), nil
+}
+
+// atom checks that n can be written as an atom of the aura.
+func (a number) atom(n *big.Int) error {
+
+	if n == nil || n.Sign() < 0 {
+		return fmt.Errorf(ugi.ErrAuraRange, n, "@"+a.aura)
+	}
+
+	return nil
+}
+
+// FormatUd writes n as a @ud, in groups of three digits such as 1.000.000.
+// Negative numbers have no @ud.
+func FormatUd(n *big.Int) (string, error) {
+
+	if err := ud.atom(n); err != nil {
+		return "", err
+	}
+
+	return ud.format(n), nil
+}
+
+// FormatUdUint64 writes n as a @ud.
+func FormatUdUint64(n uint64) string {
+
+	return ud.format(big.NewInt(0).SetUint64(n))
+}
+
+// ParseUd reads a @ud. Digits must be grouped as FormatUd groups them, so
+// 1000 is rejected as well as 10.00.
+func ParseUd(s string) (*big.Int, error) {
+
+	return ud.parse(s)
+}
+
+// ParseUdUint64 reads a @ud that fits in a uint64.
+func ParseUdUint64(s string) (uint64, error) {
+
+	return ud.parseUint64(s)
+}
+
+// FormatUx writes n as a @ux, in groups of four lower case hex digits after
+// 0x, such as 0x1.0000.ffff. Negative numbers have no @ux.
+func FormatUx(n *big.Int) (string, error) {
+
+	if err := ux.atom(n); err != nil {
+		return "", err
+	}
+
+	return ux.format(n), nil
+}
+
+// FormatUxUint64 writes n as a @ux.
+func FormatUxUint64(n uint64) string {
+
+	return ux.format(big.NewInt(0).SetUint64(n))
+}
+
+// ParseUx reads a @ux. Digits must be lower case and grouped as FormatUx
+// groups them, so 0x10000 is rejected as well as 0x1.0.0000.
+func ParseUx(s string) (*big.Int, error) {
+
+	return ux.parse(s)
+}
+
+// ParseUxUint64 reads a @ux that fits in a uint64.
+func ParseUxUint64(s string) (uint64, error) {
+
+	return ux.parseUint64(s)
+}
+
+// parseBytes reads s as parse does and returns the bytes of the number.
+func (a number) parseBytes(s string) ([]byte, error) {
+
+	v, err := a.parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return atomBytes(v), nil
+}
+
+// bytesAtom returns the atom whose bytes, least significant first, are b, as
+// Hoon reads a byte string.
+func bytesAtom(b []byte) *big.Int {
+
+	be := make([]byte, len(b))
+	for i, c := range b {
+		be[len(b)-1-i] = c
+	}
+
+	return big.NewInt(0).SetBytes(be)
+}
+
+// atomBytes returns the bytes of n, least significant first, up to its most
+// significant byte that is not zero. Zero has none.
+func atomBytes(n *big.Int) []byte {
+
+	b := n.Bytes()
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+
+	return b
+}
+
+// FormatUb writes n as a @ub, in groups of four binary digits after 0b, such
+// as 0b1.0000. Negative numbers have no @ub.
+func FormatUb(n *big.Int) (string, error) {
+
+	if err := ub.atom(n); err != nil {
+		return "", err
+	}
+
+	return ub.format(n), nil
+}
+
+// FormatUbBytes writes the atom whose bytes, least significant first, are b
+// as a @ub.
+func FormatUbBytes(b []byte) string {
+
+	return ub.format(bytesAtom(b))
+}
+
+// ParseUb reads a @ub. Digits must be grouped as FormatUb groups them.
+func ParseUb(s string) (*big.Int, error) {
+
+	return ub.parse(s)
+}
+
+// ParseUbBytes reads a @ub and returns its bytes, least significant first,
+// without zero bytes at the end.
+func ParseUbBytes(s string) ([]byte, error) {
+
+	return ub.parseBytes(s)
+}
+
+// FormatUv writes n as a @uv, in groups of five base 32 digits, 0 to 9 and a
+// to v, after 0v, such as 0v1f.3abcd. Negative numbers have no @uv.
+func FormatUv(n *big.Int) (string, error) {
+
+	if err := uv.atom(n); err != nil {
+		return "", err
+	}
+
+	return uv.format(n), nil
+}
+
+// FormatUvBytes writes the atom whose bytes, least significant first, are b
+// as a @uv. This is how Hoon prints a hash.
+func FormatUvBytes(b []byte) string {
+
+	return uv.format(bytesAtom(b))
+}
+
+// ParseUv reads a @uv. Digits must be grouped as FormatUv groups them.
+func ParseUv(s string) (*big.Int, error) {
+
+	return uv.parse(s)
+}
+
+// ParseUvBytes reads a @uv and returns its bytes, least significant first,
+// without zero bytes at the end.
+func ParseUvBytes(s string) ([]byte, error) {
+
+	return uv.parseBytes(s)
+}
+
+// FormatUw writes n as a @uw, in groups of five base 64 digits after 0w. The
+// digits are 0 to 9, a to z, A to Z, - and ~, in that order. Negative numbers
+// have no @uw.
+func FormatUw(n *big.Int) (string, error) {
+
+	if err := uw.atom(n); err != nil {
+		return "", err
+	}
+
+	return uw.format(n), nil
+}
+
+// FormatUwBytes writes the atom whose bytes, least significant first, are b
+// as a @uw, as for a keyfile.
+func FormatUwBytes(b []byte) string {
+
+	return uw.format(bytesAtom(b))
+}
+
+// ParseUw reads a @uw. Digits must be grouped as FormatUw groups them.
+func ParseUw(s string) (*big.Int, error) {
+
+	return uw.parse(s)
+}
+
+// ParseUwBytes reads a @uw and returns its bytes, least significant first,
+// without zero bytes at the end.
+func ParseUwBytes(s string) ([]byte, error) {
+
+	return uw.parseBytes(s)
+}
@@ -0,0 +1,224 @@
+package aura
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestUd(t *testing.T) {
+
+	big20, _ := big.NewInt(0).SetString("100000000000000000000", 10)
+	tests := []struct {
+		n    *big.Int
+		want string
+	}{
+		{big.NewInt(0), "0"},
+		{big.NewInt(7), "7"},
+		{big.NewInt(999), "999"},
+		{big.NewInt(1000), "1.000"},
+		{big.NewInt(65536), "65.536"},
+		{big.NewInt(1000000), "1.000.000"},
+		{big.NewInt(123456789), "123.456.789"},
+		{big20, "100.000.000.000.000.000.000"},
+	}
+	for _, tt := range tests {
+		got, err := FormatUd(tt.n)
+		if err != nil || got != tt.want {
+			t.Errorf("%v: got %q, %v, want %q", tt.n, got, err, tt.want)
+		}
+		n, err := ParseUd(tt.want)
+		if err != nil || n.Cmp(tt.n) != 0 {
+			t.Errorf("%s: parsed as %v, %v", tt.want, n, err)
+		}
+		if tt.n.IsUint64() {
+			if got := FormatUdUint64(tt.n.Uint64()); got != tt.want {
+				t.Errorf("%v: got %q from the uint64", tt.n, got)
+			}
+			if n, err := ParseUdUint64(tt.want); err != nil || n != tt.n.Uint64() {
+				t.Errorf("%s: parsed as %d, %v", tt.want, n, err)
+			}
+		}
+	}
+
+	if got := FormatUdUint64(math.MaxUint64); got != "18.446.744.073.709.551.615" {
+		t.Errorf("max: got %s", got)
+	}
+	for _, n := range []*big.Int{nil, big.NewInt(-1)} {
+		if s, err := FormatUd(n); err == nil {
+			t.Errorf("%v: got %s, expected an error", n, s)
+		}
+	}
+
+	for _, s := range []string{
+		"", ".", "1000", "10.00", "1.0000", "1.00", "0.000", "01.000", "00", "1.000.",
+		".000", "1..000", "1,000", "+1", "-1", "1.-00", "1.0x0", "0x1", "1 000",
+	} {
+		if n, err := ParseUd(s); err == nil {
+			t.Errorf("%q: got %v, expected an error", s, n)
+		}
+	}
+	if n, err := ParseUdUint64("18.446.744.073.709.551.616"); err == nil {
+		t.Errorf("max + 1: got %d, expected an error", n)
+	}
+}
+
+func TestUx(t *testing.T) {
+
+	tests := []struct {
+		n    *big.Int
+		want string
+	}{
+		{big.NewInt(0), "0x0"},
+		{big.NewInt(0xf), "0xf"},
+		{big.NewInt(0xffff), "0xffff"},
+		{big.NewInt(0x10000), "0x1.0000"},
+		{big.NewInt(0x1a2b3c4d), "0x1a2b.3c4d"},
+		{big.NewInt(0x10000ffff), "0x1.0000.ffff"},
+	}
+	for _, tt := range tests {
+		got, err := FormatUx(tt.n)
+		if err != nil || got != tt.want {
+			t.Errorf("%v: got %q, %v, want %q", tt.n, got, err, tt.want)
+		}
+		n, err := ParseUx(tt.want)
+		if err != nil || n.Cmp(tt.n) != 0 {
+			t.Errorf("%s: parsed as %v, %v", tt.want, n, err)
+		}
+		if got := FormatUxUint64(tt.n.Uint64()); got != tt.want {
+			t.Errorf("%v: got %q from the uint64", tt.n, got)
+		}
+		if n, err := ParseUxUint64(tt.want); err != nil || n != tt.n.Uint64() {
+			t.Errorf("%s: parsed as %d, %v", tt.want, n, err)
+		}
+	}
+
+	if got := FormatUxUint64(math.MaxUint64); got != "0xffff.ffff.ffff.ffff" {
+		t.Errorf("max: got %s", got)
+	}
+	if s, err := FormatUx(big.NewInt(-1)); err == nil {
+		t.Errorf("-1: got %s, expected an error", s)
+	}
+
+	for _, s := range []string{
+		"", "0x", "0", "10000", "0x10000", "0x1.000", "0x1.00000", "0x01.0000", "0x00",
+		"0x0.0000", "0xA", "0X1", "0x1.FFFF", "0xg", "0x-1", "0x1.0000.", "1.0000",
+	} {
+		if n, err := ParseUx(s); err == nil {
+			t.Errorf("%q: got %v, expected an error", s, n)
+		}
+	}
+	if n, err := ParseUxUint64("0x1.0000.0000.0000.0000"); err == nil {
+		t.Errorf("max + 1: got %d, expected an error", n)
+	}
+}
+
+func TestUbUvUw(t *testing.T) {
+
+	two := func(e uint) *big.Int { return big.NewInt(0).Lsh(big.NewInt(1), e) }
+	tests := []struct {
+		n      *big.Int
+		ub     string
+		uv, uw string
+	}{
+		{big.NewInt(0), "0b0", "0v0", "0w0"},
+		{big.NewInt(5), "0b101", "0v5", "0w5"},
+		{big.NewInt(16), "0b1.0000", "0vg", "0wg"},
+		{big.NewInt(31), "0b1.1111", "0vv", "0wv"},
+		{big.NewInt(32), "0b10.0000", "0v10", "0ww"},
+		{big.NewInt(36), "0b10.0100", "0v14", "0wA"},
+		{big.NewInt(62), "0b11.1110", "0v1u", "0w-"},
+		{big.NewInt(63), "0b11.1111", "0v1v", "0w~"},
+		{big.NewInt(64), "0b100.0000", "0v20", "0w10"},
+		{big.NewInt(0x1234), "0b1.0010.0011.0100", "0v4hk", "0w18Q"},
+		{big.NewInt(1000000), "0b1111.0100.0010.0100.0000", "0vugi0", "0w3Q90"},
+		{two(25), "0b10.0000.0000.0000.0000.0000.0000", "0v1.00000", "0w20000"},
+		{two(30), "0b100.0000.0000.0000.0000.0000.0000.0000", "0v10.00000", "0w1.00000"},
+	}
+	for _, tt := range tests {
+		for _, c := range []struct {
+			want   string
+			format func(*big.Int) (string, error)
+			parse  func(string) (*big.Int, error)
+		}{
+			{tt.ub, FormatUb, ParseUb},
+			{tt.uv, FormatUv, ParseUv},
+			{tt.uw, FormatUw, ParseUw},
+		} {
+			got, err := c.format(tt.n)
+			if err != nil || got != c.want {
+				t.Errorf("%v: got %q, %v, want %q", tt.n, got, err, c.want)
+			}
+			n, err := c.parse(c.want)
+			if err != nil || n.Cmp(tt.n) != 0 {
+				t.Errorf("%s: parsed as %v, %v", c.want, n, err)
+			}
+		}
+	}
+
+	for _, f := range []func(*big.Int) (string, error){FormatUb, FormatUv, FormatUw} {
+		if s, err := f(big.NewInt(-1)); err == nil {
+			t.Errorf("-1: got %s, expected an error", s)
+		}
+	}
+
+	bad := map[string]func(string) (*big.Int, error){
+		"0b": ParseUb, "0b00": ParseUb, "0b2": ParseUb, "0b10000": ParseUb, "0b1.000": ParseUb,
+		"0B1": ParseUb, "0b0.0000": ParseUb, "101": ParseUb,
+		"0v": ParseUv, "0v00": ParseUv, "0vw": ParseUv, "0vV": ParseUv, "0v100000": ParseUv,
+		"0v1.0000": ParseUv, "0v0.00000": ParseUv, "0v-1": ParseUv, "0w1": ParseUv,
+		"0w": ParseUw, "0w00": ParseUw, "0w!": ParseUw, "0w100000": ParseUw, "0w1.0000": ParseUw,
+		"0w~.00000.": ParseUw, "0w0.00000": ParseUw, "0v1": ParseUw,
+	}
+	for s, parse := range bad {
+		if n, err := parse(s); err == nil {
+			t.Errorf("%q: got %v, expected an error", s, n)
+		}
+	}
+}
+
+func TestNumberBytes(t *testing.T) {
+
+	// Bytes are least significant first, as Hoon reads a byte string.
+	b := []byte{0x34, 0x12}
+	if got := FormatUvBytes(b); got != "0v4hk" {
+		t.Errorf("uv: got %s", got)
+	}
+	if got := FormatUwBytes(b); got != "0w18Q" {
+		t.Errorf("uw: got %s", got)
+	}
+	if got := FormatUbBytes(b); got != "0b1.0010.0011.0100" {
+		t.Errorf("ub: got %s", got)
+	}
+	if got := FormatUwBytes(nil); got != "0w0" {
+		t.Errorf("no bytes: got %s", got)
+	}
+
+	// Random bytes survive each form, less any zeros at the end.
+	data := make([]byte, 65)
+	for i := range data {
+		data[i] = byte(i*131 + 7)
+	}
+	data[len(data)-1] = 0
+	for _, c := range []struct {
+		format func([]byte) string
+		parse  func(string) ([]byte, error)
+	}{
+		{FormatUbBytes, ParseUbBytes},
+		{FormatUvBytes, ParseUvBytes},
+		{FormatUwBytes, ParseUwBytes},
+	} {
+		s := c.format(data)
+		got, err := c.parse(s)
+		if err != nil || string(got) != string(data[:len(data)-1]) {
+			t.Errorf("%s: got %x, %v", s, got, err)
+		}
+	}
+
+	if got, err := ParseUwBytes("0w0"); err != nil || len(got) != 0 {
+		t.Errorf("0w0: got %x, %v", got, err)
+	}
+	if _, err := ParseUvBytes("0v00"); err == nil {
+		t.Errorf("0v00: expected an error")
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deelawn/urbit-gob/co"
+	"github.com/deelawn/urbit-gob/ob/analysis"
+)
+
+func main() {
+	var (
+		samples    int
+		workers    int
+		seed       int64
+		lo, hi     string
+		full       bool
+		showMatrix bool
+	)
+
+	flag.IntVar(&samples, "n", analysis.DefaultSamples, "Number of random points to analyse")
+	flag.BoolVar(&full, "all", false, "Analyse every point in the range instead of sampling")
+	flag.IntVar(&workers, "workers", runtime.NumCPU(), "Number of parallel workers")
+	flag.Int64Var(&seed, "seed", 1, "Seed for the random samples")
+	flag.StringVar(&lo, "lo", "0x10000", "Lowest point to analyse")
+	flag.StringVar(&hi, "hi", "0xffffffff", "Highest point to analyse")
+	flag.BoolVar(&showMatrix, "matrix", false, "Print the full 32x32 avalanche matrix")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nMeasure how well the Urbit @p scrambler hides the sequence of planets\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExample:\n")
+		fmt.Fprintf(os.Stderr, "  %s -n 1000000\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -all -lo 0x10000 -hi 0x1ffff -matrix\n", os.Args[0])
+	}
+
+	flag.Parse()
+
+	opts := analysis.Options{
+		Samples: samples,
+		Full:    full,
+		Seed:    seed,
+		Workers: workers,
+	}
+
+	var err error
+	if opts.Lo, err = strconv.ParseUint(lo, 0, 64); err != nil {
+		log.Fatalf("invalid -lo: %v", err)
+	}
+	if opts.Hi, err = strconv.ParseUint(hi, 0, 64); err != nil {
+		log.Fatalf("invalid -hi: %v", err)
+	}
+
+	if samples <= 0 {
+		samples = analysis.DefaultSamples
+	}
+	what := fmt.Sprintf("%d random points", samples)
+	if full {
+		what = "every point"
+	}
+	fmt.Printf("Analysing %s in [%#x, %#x] using %d workers...\n", what, opts.Lo, opts.Hi, workers)
+
+	start := time.Now()
+	report, err := analysis.Analyze(opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	printAvalanche(report.Avalanche, showMatrix)
+	printSyllables(report.Syllables)
+	printSimilarity(report.Similarity)
+
+	fmt.Printf("\nTime elapsed: %v\n", time.Since(start))
+}
+
+func printAvalanche(r analysis.AvalancheReport, showMatrix bool) {
+
+	fmt.Printf("\n=== Avalanche ===\n")
+	fmt.Printf("Single-bit flips measured: %d\n", r.Flips)
+	fmt.Printf("Mean output bits flipped:  %.4f (ideal 0.5000)\n", r.MeanFlipRate)
+	fmt.Printf("Mean bias from 0.5:        %.4f\n", r.MeanBias)
+	fmt.Printf("Max bias from 0.5:         %.4f\n", r.MaxBias)
+
+	fmt.Printf("\nOutput bits changed per flip:\n")
+	for d, n := range r.Distance {
+		if n == 0 {
+			continue
+		}
+		fmt.Printf("  %2d: %6.2f%%\n", d, float64(n)/float64(r.Flips)*100)
+	}
+
+	if !showMatrix {
+		return
+	}
+
+	fmt.Printf("\nFlip probability (rows: input bit, columns: output bit, 31 first), as %% - 50:\n")
+	for i := 31; i >= 0; i-- {
+		cells := make([]string, 0, 32)
+		for j := 31; j >= 0; j-- {
+			cells = append(cells, fmt.Sprintf("%+3.0f", (r.Matrix[i][j]-0.5)*100))
+		}
+		fmt.Printf("  %2d: %s\n", i, strings.Join(cells, " "))
+	}
+}
+
+func printSyllables(r analysis.SyllableReport) {
+
+	fmt.Printf("\n=== Syllable distribution ===\n")
+	fmt.Printf("Names counted: %d\n", r.Names)
+	fmt.Printf("  %-8s %-6s %10s %10s %12s %10s\n", "position", "kind", "min", "max", "chi-square", "p-value")
+	for pos, p := range r.Positions {
+		kind, syls := "suffix", co.Suffixes
+		if p.Prefix {
+			kind, syls = "prefix", co.Prefixes
+		}
+		fmt.Printf("  %-8d %-6s %10d %10d %12.2f %10.4f\n", pos+1, kind, p.Min, p.Max, p.ChiSquare, p.PValue)
+
+		rare, common := 0, 0
+		for i, n := range p.Counts {
+			if n < p.Counts[rare] {
+				rare = i
+			}
+			if n > p.Counts[common] {
+				common = i
+			}
+		}
+		fmt.Printf("  %-8s rarest %s, most common %s\n", "", syls[rare], syls[common])
+	}
+}
+
+func printSimilarity(r analysis.SimilarityReport) {
+
+	fmt.Printf("\n=== Adjacent name similarity ===\n")
+	fmt.Printf("Pairs compared: %d\n", r.Pairs)
+	fmt.Printf("  %-10s %18s %16s\n", "", "adjacent (x, x+1)", "random")
+	fmt.Printf("  %-10s %18.4f %16.4f\n", "syllables", r.Adjacent.MeanSharedSyllables, r.Random.MeanSharedSyllables)
+	fmt.Printf("  %-10s %18.4f %16.4f\n", "edits", r.Adjacent.MeanEditDistance, r.Random.MeanEditDistance)
+	for n := range r.Adjacent.Shared {
+		fmt.Printf("  %d shared %18d %16d\n", n, r.Adjacent.Shared[n], r.Random.Shared[n])
+	}
+	fmt.Printf("(unrelated names share 4/256 = %.4f syllables on average)\n", 4.0/256)
+}
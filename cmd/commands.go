@@ -0,0 +1,233 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/deelawn/urbit-gob/co"
+)
+
+const (
+	// Commands
+	cmdPatp       string = "patp"
+	cmdPatp2Dec   string = "patp2dec"
+	cmdPatp2Hex   string = "patp2hex"
+	cmdPatp2Point string = "patp2point"
+
+	cmdPatq       string = "patq"
+	cmdPatq2Dec   string = "patq2dec"
+	cmdPatq2Hex   string = "patq2hex"
+	cmdPatq2Point string = "patq2point"
+
+	cmdPoint2Patp string = "point2patp"
+	cmdPoint2Patq string = "point2patq"
+
+	cmdHex2Patp string = "hex2patp"
+	cmdHex2Patq string = "hex2patq"
+
+	cmdClan      string = "clan"
+	cmdClanPoint string = "clanpoint"
+	cmdSein      string = "sein"
+	cmdSeinPoint string = "seinpoint"
+	cmdEqPatq    string = "eqpatq"
+
+	cmdIsValidPat  string = "isvalidpat"
+	cmdIsValidPatp string = "isvalidpatp"
+	cmdIsValidPatq string = "isvalidpatq"
+
+	cmdInfo    string = "info"
+	cmdConvert string = "convert"
+	cmdExplain string = "explain"
+
+	cmdREPL string = "repl"
+)
+
+// command is a CLI command that converts each of its inputs independently.
+type command struct {
+	name    string
+	summary string
+
+	// nargs is the number of values that make up one input. When reading a
+	// stream, the values of an input are separated by whitespace.
+	nargs int
+
+	// apply converts a single input.
+	apply func(args []string) (interface{}, error)
+}
+
+var commands = []command{
+	{cmdPatp, "converts a number to a @p-encoded string", 1, str(func(s string) (interface{}, error) { return co.Patp(s) })},
+	{cmdPatp2Dec, "converts a @p-encoded string to a decimal-encoded string", 1, str(func(s string) (interface{}, error) { return co.Patp2Dec(s) })},
+	{cmdPatp2Hex, "converts a @p-encoded string to a hex-encoded string", 1, str(func(s string) (interface{}, error) { return co.Patp2Hex(s) })},
+	{cmdPatp2Point, "converts a @p-encoded string to a big.Int", 1, str(func(s string) (interface{}, error) { return co.Patp2Point(s) })},
+	{cmdPatq, "converts a number to a @q-encoded string", 1, str(func(s string) (interface{}, error) { return co.Patq(s) })},
+	{cmdPatq2Dec, "converts a @q-encoded string to a decimal-encoded string", 1, str(func(s string) (interface{}, error) { return co.Patq2Dec(s) })},
+	{cmdPatq2Hex, "converts a @q-encoded string to a hex-encoded string", 1, str(func(s string) (interface{}, error) { return co.Patq2Hex(s) })},
+	{cmdPatq2Point, "converts a @q-encoded string to a big.Int", 1, str(func(s string) (interface{}, error) { return co.Patq2Point(s) })},
+	{cmdPoint2Patp, "converts a point to a @p-encoded string", 1, point(func(p *big.Int) (interface{}, error) { return co.Point2Patp(p) })},
+	{cmdPoint2Patq, "converts a point to a @q-encoded string", 1, point(func(p *big.Int) (interface{}, error) { return co.Point2Patq(p) })},
+	{cmdHex2Patp, "converts a hex-encoded string to a @p-encoded string", 1, str(func(s string) (interface{}, error) { return co.Hex2Patp(s) })},
+	{cmdHex2Patq, "converts a hex-encoded string to a @q-encoded string", 1, str(func(s string) (interface{}, error) { return co.Hex2Patq(s) })},
+	{cmdClan, "determines the ship class of a @p value", 1, str(func(s string) (interface{}, error) { return co.Clan(s) })},
+	{cmdClanPoint, "determines the ship class of a point", 1, point(func(p *big.Int) (interface{}, error) { return co.ClanPoint(p) })},
+	{cmdSein, "determines the parent of a @p value", 1, str(func(s string) (interface{}, error) { return co.Sein(s) })},
+	{cmdSeinPoint, "determines the parent of a point", 1, point(func(p *big.Int) (interface{}, error) { return co.SeinPoint(p) })},
+	{cmdEqPatq, "performs an equality comparison on @q values", 2, func(args []string) (interface{}, error) { return co.EqPatq(args[0], args[1]) }},
+	{cmdIsValidPat, "weakly checks if a string is a valid @p or @q value", 1, str(func(s string) (interface{}, error) { return co.IsValidPat(s), nil })},
+	{cmdIsValidPatp, "validates a @p string", 1, str(func(s string) (interface{}, error) { return co.IsValidPatp(s), nil })},
+	{cmdIsValidPatq, "validates a @q string", 1, str(func(s string) (interface{}, error) { return co.IsValidPatq(s), nil })},
+	{cmdConvert, "detects whether a value is a @p, @q, decimal, hex or binary number and converts it to every other", 1, str(convertAny)},
+	{cmdExplain, "shows step by step how a point becomes its @p, or a @p its point", 1, str(explainAny)},
+	{cmdInfo, "describes a @p value in every form, with its class, sponsors and children", 1, str(func(s string) (interface{}, error) { return co.Describe(s) })},
+}
+
+// tool is a CLI command that runs on its own rather than converting inputs.
+// Tools parse their own args.
+type tool struct {
+	name    string
+	summary string
+	run     func(g *globals, args []string) int
+
+	// flags returns the flag set the tool parses its args with, or is nil
+	// if it takes no flags.
+	flags func(g *globals) *flag.FlagSet
+}
+
+var tools []tool
+
+func init() {
+
+	// Some tools list the others, so the table is filled in here to avoid an
+	// initialization cycle.
+	tools = []tool{
+		{cmdREPL, "runs commands interactively, with line editing, history and tab completion", runREPL, nil},
+		{cmdTree, "prints the ships a ship sponsors, or its sponsors, as a tree", runTree, new(treeArgs).flags},
+		{cmdRange, "lists every ship in a range of points, with chosen columns", runRange, new(rangeArgs).flags},
+		{cmdLint, "reports, and with --fix rewrites, invalid and non-canonical names in files", runLint, new(lintArgs).flags},
+		{cmdQR, "prints a @p, @q or the result of any command as a QR code, or writes it as PNG or SVG", runQR, new(qrArgs).flags},
+		{cmdSigil, "draws the sigils of ships as SVG or PNG", runSigil, new(sigilArgs).flags},
+		{cmdSay, "prints how to say names aloud, respelled, in IPA or in the spelling alphabet", runSay, new(sayArgs).flags},
+		{cmdServe, "serves the commands as an HTTP JSON API", runServe, new(serveArgs).flags},
+		{cmdCompletion, "prints a bash, zsh or fish script that completes commands, flags and @p syllables", runCompletion, new(completionArgs).flags},
+	}
+}
+
+func lookupTool(name string) (tool, bool) {
+
+	for _, t := range tools {
+		if t.name == name {
+			return t, true
+		}
+	}
+
+	return tool{}, false
+}
+
+func lookupCommand(name string) (command, bool) {
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+
+	return command{}, false
+}
+
+// str adapts a conversion of a single string.
+func str(f func(string) (interface{}, error)) func([]string) (interface{}, error) {
+
+	return func(args []string) (interface{}, error) {
+		return f(args[0])
+	}
+}
+
+// point adapts a conversion of a single point, written in any of the forms
+// accepted by co.ParsePoint.
+func point(f func(*big.Int) (interface{}, error)) func([]string) (interface{}, error) {
+
+	return func(args []string) (interface{}, error) {
+		p, err := co.ParsePoint(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return f(p)
+	}
+}
+
+// shipPoint returns the point of s, which is a @p or a point.
+func shipPoint(s string) (*big.Int, error) {
+
+	if strings.HasPrefix(s, "~") {
+		return co.Patp2Point(s)
+	}
+
+	return co.ParsePoint(s)
+}
+
+// shipName returns the @p of s, which is a @p or a point.
+func shipName(s string) (string, error) {
+
+	p, err := shipPoint(s)
+	if err != nil {
+		return "", err
+	}
+
+	return co.Patp(p)
+}
+
+// formatResult renders a result as text.
+func formatResult(result interface{}) string {
+
+	switch v := result.(type) {
+	case *co.Ship:
+		return formatTable(shipRows(v))
+	case *conversion:
+		return formatTable(v.rows())
+	case *explanation:
+		return v.text()
+	default:
+		return fmt.Sprint(result)
+	}
+}
+
+// formatTable renders rows of keys and values as a table. The table ends in a
+// newline, so that the record delimiter leaves a blank line before the next.
+func formatTable(rows [][2]string) string {
+
+	width := 0
+	for _, r := range rows {
+		if len(r[0]) > width {
+			width = len(r[0])
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range rows {
+		fmt.Fprintf(&b, "%-*s  %s\n", width, r[0], r[1])
+	}
+
+	return b.String()
+}
+
+func shipRows(s *co.Ship) [][2]string {
+
+	sponsors := strings.Join(s.Sponsors, " > ")
+	if sponsors == "" {
+		sponsors = "-"
+	}
+
+	return [][2]string{
+		{"point", s.Point.String()},
+		{"hex", s.Hex},
+		{"patp", s.Patp},
+		{"patq", s.Patq},
+		{"class", s.Class},
+		{"rank", s.Rank},
+		{"sponsors", sponsors},
+		{"children", fmt.Sprint(s.Children)},
+		{"cite", s.Cite},
+	}
+}
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/deelawn/urbit-gob/co"
+	"github.com/deelawn/urbit-gob/qr"
+)
+
+const (
+	cmdCompletion string = "completion"
+
+	shellBash string = "bash"
+	shellZsh  string = "zsh"
+	shellFish string = "fish"
+)
+
+var shells = []string{shellBash, shellZsh, shellFish}
+
+// completionData is what the completion scripts are generated from.
+type completionData struct {
+	// Name is the name of the program being completed, and Ident a version
+	// of it usable in shell function and variable names.
+	Name  string
+	Ident string
+
+	Commands []completionCommand
+	Flags    []completionFlag
+	Shells   []string
+	Prefixes string
+	Suffixes string
+}
+
+// completionCommand is a command or tool. Tools take their own flags after
+// their name, in place of the global ones.
+type completionCommand struct {
+	Name    string
+	Summary string
+	Tool    bool
+	Flags   []completionFlag
+}
+
+type completionFlag struct {
+	Name    string
+	Usage   string
+	Value   bool
+	Choices string
+	File    bool
+}
+
+// flagChoices are the values completed for flags that take one of a fixed
+// set, keyed by the flag name or, for the flags of a tool, by the tool and
+// flag names.
+var flagChoices = map[string][]string{
+	"output":             {outputText, outputJSON, outputCSV, outputTSV},
+	"o":                  {outputText, outputJSON, outputCSV, outputTSV},
+	cmdTree + " format":  {treeText, treeDOT, treeMermaid},
+	cmdQR + " format":    {qrText, qrPNG, qrSVG},
+	cmdQR + " level":     {qr.L.String(), qr.M.String(), qr.Q.String(), qr.H.String()},
+	cmdSigil + " format": {sigilSVG, sigilPNG},
+	cmdSay + " format":   {sayRespelled, sayIPA, saySpelled, sayAll},
+}
+
+// fileFlags are the flags whose value is completed as a path.
+var fileFlags = map[string]bool{"file": true, "out": true, "symbols": true, "dir": true}
+
+// completionArgs are the flags of completion.
+type completionArgs struct {
+	name string
+}
+
+func (a *completionArgs) flags(g *globals) *flag.FlagSet {
+
+	fs := flag.NewFlagSet(cmdCompletion, flag.ContinueOnError)
+	fs.StringVar(&a.name, "name", filepath.Base(os.Args[0]), "name of the program to complete")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [--name NAME] %s\n\n", os.Args[0], cmdCompletion, strings.Join(shells, "|"))
+		fmt.Fprintf(fs.Output(), "Prints a script that completes commands, flags and the syllables of @p\n")
+		fmt.Fprintf(fs.Output(), "values for the given shell. For example:\n\n")
+		fmt.Fprintf(fs.Output(), "    source <(%s completion bash)\n", a.name)
+		fmt.Fprintf(fs.Output(), "    %s completion zsh > \"${fpath[1]}/_%s\"\n", a.name, a.name)
+		fmt.Fprintf(fs.Output(), "    %s completion fish > ~/.config/fish/completions/%s.fish\n\n", a.name, a.name)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+func runCompletion(g *globals, args []string) int {
+
+	var a completionArgs
+	fs := a.flags(g)
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return codeInsufficientArguments
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return codeInsufficientArguments
+	}
+
+	if err := writeCompletion(os.Stdout, positional[0], a.name, flag.CommandLine); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeInsufficientArguments
+	}
+
+	return 0
+}
+
+// writeCompletion writes the completion script for shell, completing the
+// program name with the commands, the global flags of fs and the tools with
+// their own flags.
+func writeCompletion(w io.Writer, shell, name string, fs *flag.FlagSet) error {
+
+	tmpl, ok := completionTemplates[shell]
+	if !ok {
+		return fmt.Errorf("unsupported shell: %s (want %s)", shell, strings.Join(shells, ", "))
+	}
+
+	data := completionData{
+		Name:     name,
+		Ident:    regexp.MustCompile(`[^A-Za-z0-9_]`).ReplaceAllString(name, "_"),
+		Shells:   shells,
+		Prefixes: strings.Join(co.Prefixes, " "),
+		Suffixes: strings.Join(co.Suffixes, " "),
+	}
+
+	for _, cmd := range commands {
+		data.Commands = append(data.Commands, completionCommand{Name: cmd.name, Summary: cmd.summary})
+	}
+	for _, t := range tools {
+		c := completionCommand{Name: t.name, Summary: t.summary, Tool: true}
+		if t.flags != nil {
+			c.Flags = completionFlags(t.name, t.flags(&globals{}))
+		}
+		data.Commands = append(data.Commands, c)
+	}
+	data.Flags = completionFlags("", fs)
+
+	return tmpl.Execute(w, data)
+}
+
+// completionFlags describes the flags of fs, which belong to the tool cmd or,
+// if cmd is empty, are the global flags.
+func completionFlags(cmd string, fs *flag.FlagSet) []completionFlag {
+
+	var flags []completionFlag
+	fs.VisitAll(func(f *flag.Flag) {
+		_, usage := flag.UnquoteUsage(f)
+		cf := completionFlag{Name: f.Name, Usage: usage, Value: true, File: fileFlags[f.Name]}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			cf.Value = false
+		}
+		choices, ok := flagChoices[cmd+" "+f.Name]
+		if !ok {
+			choices = flagChoices[f.Name]
+		}
+		cf.Choices = strings.Join(choices, " ")
+		flags = append(flags, cf)
+	})
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+
+	return flags
+}
+
+var completionFuncs = template.FuncMap{
+	// dashed gives the forms of a flag the shell should offer: --name for
+	// long flags and -n for single letters.
+	"dashed": func(name string) string {
+		if len(name) == 1 {
+			return "-" + name
+		}
+		return "--" + name
+	},
+	// quote single-quotes s for the shell.
+	"quote": func(s string) string {
+		return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+	},
+	"join": strings.Join,
+	// describe formats a name and description for zsh's _describe.
+	"describe": func(name, desc string) string {
+		return strings.Replace(name, ":", `\:`, -1) + ":" + desc
+	},
+}
+
+var completionTemplates = map[string]*template.Template{
+	shellBash: template.Must(template.New(shellBash).Funcs(completionFuncs).Parse(bashCompletion)),
+	shellZsh:  template.Must(template.New(shellZsh).Funcs(completionFuncs).Parse(zshCompletion)),
+	shellFish: template.Must(template.New(shellFish).Funcs(completionFuncs).Parse(fishCompletion)),
+}
+
+const bashCompletion = `# bash completion for {{.Name}}
+# Load with: source <({{.Name}} completion bash)
+
+_{{.Ident}}_prefixes="{{.Prefixes}}"
+_{{.Ident}}_suffixes="{{.Suffixes}}"
+
+# _{{.Ident}}_syllables completes the last word of the @p in $1.
+_{{.Ident}}_syllables() {
+    local cur=$1 head sep seg pre w
+    head=${cur%[~-]*}
+    sep=${cur:${#head}:1}
+    seg=${cur:${#head}+1}
+
+    if (( ${#seg} < 3 )); then
+        local words=$_{{.Ident}}_prefixes
+        [[ $sep == "~" ]] && words="$words $_{{.Ident}}_suffixes"
+        for w in $words; do
+            [[ $w == "$seg"* ]] && COMPREPLY+=("$head$sep$w")
+        done
+    elif (( ${#seg} < 6 )); then
+        pre=${seg:0:3}
+        [[ " $_{{.Ident}}_prefixes " == *" $pre "* ]] || return
+        for w in $_{{.Ident}}_suffixes; do
+            [[ $w == "${seg:3}"* ]] && COMPREPLY+=("$head$sep$pre$w")
+        done
+    fi
+}
+
+_{{.Ident}}() {
+    local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]} cmd="" i
+    COMPREPLY=()
+
+    for (( i = 1; i < COMP_CWORD; i++ )); do
+        case ${COMP_WORDS[i]} in
+{{- range .Flags}}{{if .Value}}
+            -{{.Name}}|--{{.Name}}) (( i++ )) ;;{{end}}{{end}}
+            -*) ;;
+            *) cmd=${COMP_WORDS[i]}; break ;;
+        esac
+    done
+
+    case $cmd:$prev in
+{{- range .Commands}}{{$cmd := .Name}}{{range .Flags}}{{if .Choices}}
+        {{$cmd}}:-{{.Name}}|{{$cmd}}:--{{.Name}}) COMPREPLY=($(compgen -W "{{.Choices}}" -- "$cur")); return ;;{{else if .File}}
+        {{$cmd}}:-{{.Name}}|{{$cmd}}:--{{.Name}}) COMPREPLY=($(compgen -f -- "$cur")); return ;;{{else if .Value}}
+        {{$cmd}}:-{{.Name}}|{{$cmd}}:--{{.Name}}) return ;;{{end}}{{end}}{{end}}
+{{- range .Flags}}{{if .Choices}}
+        *:-{{.Name}}|*:--{{.Name}}) COMPREPLY=($(compgen -W "{{.Choices}}" -- "$cur")); return ;;{{else if .File}}
+        *:-{{.Name}}|*:--{{.Name}}) COMPREPLY=($(compgen -f -- "$cur")); return ;;{{else if .Value}}
+        *:-{{.Name}}|*:--{{.Name}}) return ;;{{end}}{{end}}
+    esac
+
+    if [[ $cur == -* ]]; then
+        case $cmd in
+{{- range .Commands}}{{if .Tool}}
+            {{.Name}}) COMPREPLY=($(compgen -W "{{range $i, $f := .Flags}}{{if $i}} {{end}}{{dashed $f.Name}}{{end}}" -- "$cur")) ;;{{end}}{{end}}
+            *) COMPREPLY=($(compgen -W "{{range $i, $f := .Flags}}{{if $i}} {{end}}{{dashed $f.Name}}{{end}}" -- "$cur")) ;;
+        esac
+    elif [[ -z $cmd ]]; then
+        COMPREPLY=($(compgen -W "{{range $i, $c := .Commands}}{{if $i}} {{end}}{{$c.Name}}{{end}}" -- "$cur"))
+    elif [[ $cmd == completion ]]; then
+        COMPREPLY=($(compgen -W "{{join .Shells " "}}" -- "$cur"))
+    elif [[ $cur == \~* ]]; then
+        _{{.Ident}}_syllables "$cur"
+        compopt -o nospace 2>/dev/null
+    fi
+}
+
+complete -F _{{.Ident}} {{.Name}}
+`
+
+const zshCompletion = `#compdef {{.Name}}
+# zsh completion for {{.Name}}
+# Install with: {{.Name}} completion zsh > "${fpath[1]}/_{{.Name}}"
+
+_{{.Ident}}() {
+    local -a commands flags prefixes suffixes matches
+    commands=({{range .Commands}}
+        {{quote (describe .Name .Summary)}}{{end}}
+    )
+    flags=({{range .Flags}}
+        {{quote (describe (dashed .Name) .Usage)}}{{end}}
+    )
+    prefixes=({{.Prefixes}})
+    suffixes=({{.Suffixes}})
+
+    local cur=${words[CURRENT]} prev=${words[CURRENT-1]} cmd="" i
+    for (( i = 2; i < CURRENT; i++ )); do
+        case ${words[i]} in
+{{- range .Flags}}{{if .Value}}
+            (-{{.Name}}|--{{.Name}}) (( i++ )) ;;{{end}}{{end}}
+            (-*) ;;
+            (*) cmd=${words[i]}; break ;;
+        esac
+    done
+
+    case $cmd:$prev in
+{{- range .Commands}}{{$cmd := .Name}}{{range .Flags}}{{if .Choices}}
+        ({{$cmd}}:-{{.Name}}|{{$cmd}}:--{{.Name}}) compadd -- {{.Choices}}; return ;;{{else if .File}}
+        ({{$cmd}}:-{{.Name}}|{{$cmd}}:--{{.Name}}) _files; return ;;{{else if .Value}}
+        ({{$cmd}}:-{{.Name}}|{{$cmd}}:--{{.Name}}) return ;;{{end}}{{end}}{{end}}
+{{- range .Flags}}{{if .Choices}}
+        (*:-{{.Name}}|*:--{{.Name}}) compadd -- {{.Choices}}; return ;;{{else if .File}}
+        (*:-{{.Name}}|*:--{{.Name}}) _files; return ;;{{else if .Value}}
+        (*:-{{.Name}}|*:--{{.Name}}) return ;;{{end}}{{end}}
+    esac
+
+    if [[ $cur == -* ]]; then
+        case $cmd in
+{{- range .Commands}}{{if .Tool}}
+            ({{.Name}}) flags=({{range .Flags}}
+                {{quote (describe (dashed .Name) .Usage)}}{{end}}
+            ) ;;{{end}}{{end}}
+        esac
+        _describe -t flags flag flags
+    elif [[ -z $cmd ]]; then
+        _describe -t commands command commands
+    elif [[ $cmd == completion ]]; then
+        compadd -- {{join .Shells " "}}
+    elif [[ $cur == '~'* ]]; then
+        # Complete the last word of the @p.
+        local head=${cur%[~-]*} sep seg pre w
+        sep=${cur[${#head}+1]}
+        seg=${cur[${#head}+2,-1]}
+        if (( ${#seg} < 3 )); then
+            for w in $prefixes; do
+                [[ $w == ${seg}* ]] && matches+=("$head$sep$w")
+            done
+            if [[ $sep == '~' ]]; then
+                for w in $suffixes; do
+                    [[ $w == ${seg}* ]] && matches+=("$head$sep$w")
+                done
+            fi
+        elif (( ${#seg} < 6 )); then
+            pre=${seg[1,3]}
+            if (( ${prefixes[(Ie)$pre]} )); then
+                for w in $suffixes; do
+                    [[ $w == ${seg[4,-1]}* ]] && matches+=("$head$sep$pre$w")
+                done
+            fi
+        fi
+        compadd -Q -S '' -- $matches
+    fi
+}
+
+if [[ $funcstack[1] == _{{.Ident}} ]]; then
+    _{{.Ident}} "$@"
+else
+    compdef _{{.Ident}} {{.Name}}
+fi
+`
+
+const fishCompletion = `# fish completion for {{.Name}}
+# Install with: {{.Name}} completion fish > ~/.config/fish/completions/{{.Name}}.fish
+
+set -g __{{.Ident}}_prefixes {{.Prefixes}}
+set -g __{{.Ident}}_suffixes {{.Suffixes}}
+
+# Completes the last word of the @p being typed.
+function __{{.Ident}}_syllables
+    set -l cur (commandline -ct)
+    string match -q -- '~*' $cur; or return
+    set -l head (string replace -r -- '[~-][^~-]*$' '' $cur)
+    set -l n (string length -- "$head")
+    set -l sep (string sub -s (math $n + 1) -l 1 -- $cur)
+    set -l seg (string sub -s (math $n + 2) -- $cur)
+    set -l len (string length -- "$seg")
+
+    if test $len -lt 3
+        set -l words $__{{.Ident}}_prefixes
+        if test "$sep" = '~'
+            set words $words $__{{.Ident}}_suffixes
+        end
+        for w in $words
+            string match -q -- "$seg*" $w; and echo $head$sep$w
+        end
+    else if test $len -lt 6
+        set -l pre (string sub -l 3 -- $seg)
+        set -l rest (string sub -s 4 -- $seg)
+        contains -- $pre $__{{.Ident}}_prefixes; or return
+        for w in $__{{.Ident}}_suffixes
+            string match -q -- "$rest*" $w; and echo $head$sep$pre$w
+        end
+    end
+end
+
+complete -c {{.Name}} -f
+{{- range .Commands}}
+complete -c {{$.Name}} -n __fish_use_subcommand -a {{.Name}} -d {{quote .Summary}}{{end}}
+{{- range .Flags}}
+complete -c {{$.Name}} -n 'not __fish_seen_subcommand_from{{range $.Commands}}{{if .Tool}} {{.Name}}{{end}}{{end}}' {{template "fishFlag" .}}{{end}}
+{{- range .Commands}}{{$cmd := .Name}}{{range .Flags}}
+complete -c {{$.Name}} -n '__fish_seen_subcommand_from {{$cmd}}' {{template "fishFlag" .}}{{end}}{{end}}
+complete -c {{.Name}} -n '__fish_seen_subcommand_from completion' -a {{quote (join .Shells " ")}}
+complete -c {{.Name}} -n 'not __fish_use_subcommand' -a '(__{{.Ident}}_syllables)'
+{{- define "fishFlag"}}{{if eq (len .Name) 1}}-s{{else}}-l{{end}} {{.Name}}{{if .Choices}} -x -a {{quote .Choices}}{{else if .File}} -r -F{{else if .Value}} -x{{end}} -d {{quote .Usage}}{{end}}
+`
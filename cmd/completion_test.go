@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCompletionScripts(t *testing.T) {
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("output", outputText, "output `format`")
+	fs.Bool("k", false, "shorthand for --continue")
+
+	for _, shell := range shells {
+		var b bytes.Buffer
+		if err := writeCompletion(&b, shell, "urbit-gob", fs); err != nil {
+			t.Fatalf("%s: %v", shell, err)
+		}
+		script := b.String()
+		for _, want := range []string{"urbit_gob", "patp2dec", "completion", "output", "sam", "pel", "syllables", "mermaid"} {
+			if !strings.Contains(script, want) {
+				t.Errorf("%s: script does not mention %q", shell, want)
+			}
+		}
+	}
+
+	if err := writeCompletion(&bytes.Buffer{}, "tcsh", "urbit-gob", fs); err == nil {
+		t.Error("expected an error for an unsupported shell")
+	}
+}
+
+func TestBashCompletion(t *testing.T) {
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not installed")
+	}
+
+	var script bytes.Buffer
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("output", outputText, "output `format`")
+	fs.Bool("continue", false, "carry on")
+	if err := writeCompletion(&script, shellBash, "ug", fs); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"ug patp2", "patp2dec patp2hex patp2point"},
+		{"ug --out", "--output"},
+		{"ug --output j", "json"},
+		{"ug --output json clan", "clan clanpoint"},
+		{"ug completion f", "fish"},
+		{"ug info ~sampel-palne", "~sampel-palnec ~sampel-palnex ~sampel-palnep ~sampel-palnet ~sampel-palneb ~sampel-palnem ~sampel-palner ~sampel-palned ~sampel-palnes ~sampel-palnel ~sampel-palnev"},
+		{"ug info ~zo", "~zod"},
+		{"ug info ~sampel-xyz", ""},
+		{"ug patp 12", ""},
+		{"ug sigil --s", "--size --symbols"},
+		{"ug say --format i", "ipa"},
+		{"ug lint -", "--fix"},
+		{"ug --output json tree --format m", "mermaid"},
+		{"ug qr --level ", "L M Q H"},
+		{"ug range -o c", "csv"},
+		{"ug repl -", ""},
+	}
+
+	for _, tt := range tests {
+		cmd := exec.Command(bash, "--norc", "--noprofile", "-c", script.String()+`
+COMP_WORDS=($COMP_LINE)
+[[ $COMP_LINE == *" " ]] && COMP_WORDS+=("")
+COMP_CWORD=$(( ${#COMP_WORDS[@]} - 1 ))
+_ug
+echo "${COMPREPLY[*]}"
+`)
+		cmd.Env = []string{"COMP_LINE=" + tt.line}
+
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", tt.line, err, out)
+		}
+		if got := strings.TrimSpace(string(out)); got != tt.want {
+			t.Errorf("%q completes to %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/deelawn/urbit-gob/aura"
+	"github.com/deelawn/urbit-gob/co"
+)
+
+const (
+	// Notations recognised by convert
+	kindPatp    string = "@p"
+	kindPatq    string = "@q"
+	kindDecimal string = "decimal"
+	kindUd      string = "@ud"
+	kindHex     string = "hex"
+	kindUx      string = "@ux"
+	kindBinary  string = "binary"
+	kindUb      string = "@ub"
+)
+
+// conversion is a point in every notation convert knows.
+type conversion struct {
+	Kind    string `json:"kind"`
+	Decimal string `json:"decimal"`
+	Ud      string `json:"ud"`
+	Hex     string `json:"hex"`
+	Ux      string `json:"ux"`
+	Binary  string `json:"binary"`
+	Ub      string `json:"ub"`
+	Patp    string `json:"patp"`
+	Patq    string `json:"patq"`
+	Class   string `json:"class"`
+	Note    string `json:"note,omitempty"`
+}
+
+func (c *conversion) rows() [][2]string {
+
+	rows := [][2]string{
+		{"input", c.Kind},
+		{"decimal", c.Decimal},
+		{"@ud", c.Ud},
+		{"hex", c.Hex},
+		{"@ux", c.Ux},
+		{"binary", c.Binary},
+		{"@ub", c.Ub},
+		{"@p", c.Patp},
+		{"@q", c.Patq},
+		{"class", c.Class},
+	}
+	if c.Note != "" {
+		rows = append(rows, [2]string{"note", c.Note})
+	}
+
+	return rows
+}
+
+// convertAny detects the notation of s and converts it to every other. Most
+// names are valid both as a @p and as a @q; they are read as a @p, with a note
+// giving their value as a @q if that differs, unless written with Hoon's .~
+// prefix for @q.
+func convertAny(s string) (interface{}, error) {
+
+	s = strings.TrimSpace(s)
+
+	kind, p, err := detect(s)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &conversion{
+		Kind:    kind,
+		Decimal: p.String(),
+		Hex:     "0x" + p.Text(16),
+		Binary:  "0b" + p.Text(2),
+	}
+
+	// Points are never negative, so the auras always have a form for them.
+	c.Ud, _ = aura.FormatUd(p)
+	c.Ux, _ = aura.FormatUx(p)
+	c.Ub, _ = aura.FormatUb(p)
+
+	if c.Patp, err = co.Patp(p); err != nil {
+		return nil, err
+	}
+	if c.Patq, err = co.Patq(p); err != nil {
+		return nil, err
+	}
+	if c.Class, err = co.Clan(c.Patp); err != nil {
+		return nil, err
+	}
+
+	if kind == kindPatp && co.IsValidPatq(s) {
+		if q, err := co.Patq2Point(s); err == nil && q.Cmp(p) != 0 {
+			c.Note = fmt.Sprintf("also a valid @q, for %v", q)
+		}
+	}
+
+	return c, nil
+}
+
+// detect returns the notation and value of s.
+func detect(s string) (string, *big.Int, error) {
+
+	// Hoon writes @q literals with a leading dot, which settles names that are
+	// also valid as a @p.
+	if strings.HasPrefix(s, ".~") {
+		if !co.IsValidPatq(s[1:]) {
+			return "", nil, fmt.Errorf("invalid @q: %s", s[1:])
+		}
+		p, err := co.Patq2Point(s[1:])
+		return kindPatq, p, err
+	}
+
+	if strings.HasPrefix(s, "~") {
+		if co.IsValidPatp(s) {
+			p, err := co.Patp2Point(s)
+			return kindPatp, p, err
+		}
+		if co.IsValidPatq(s) {
+			p, err := co.Patq2Point(s)
+			return kindPatq, p, err
+		}
+		return "", nil, fmt.Errorf("invalid @p or @q: %s", s)
+	}
+
+	p, err := co.ParsePoint(s)
+	if err != nil {
+		return "", nil, err
+	}
+
+	lower := strings.ToLower(s)
+	dots := strings.Contains(s, ".")
+	switch {
+	case strings.HasPrefix(lower, "0x") && dots:
+		return kindUx, p, nil
+	case strings.HasPrefix(lower, "0x"):
+		return kindHex, p, nil
+	case strings.HasPrefix(lower, "0b") && dots:
+		return kindUb, p, nil
+	case strings.HasPrefix(lower, "0b"):
+		return kindBinary, p, nil
+	case dots:
+		return kindUd, p, nil
+	default:
+		return kindDecimal, p, nil
+	}
+}
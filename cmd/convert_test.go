@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+
+	tests := []struct {
+		in   string
+		want conversion
+	}{
+		{"~zod", conversion{kindPatp, "0", "0", "0x0", "0x0", "0b0", "0b0", "~zod", "~zod", "galaxy", ""}},
+		{"65536", conversion{kindDecimal, "65536", "65.536", "0x10000", "0x1.0000", "0b10000000000000000", "0b1.0000.0000.0000.0000", "~dapnep-ronmyl", "~doznec-dozzod", "planet", ""}},
+		{"65.536", conversion{kindUd, "65536", "65.536", "0x10000", "0x1.0000", "0b10000000000000000", "0b1.0000.0000.0000.0000", "~dapnep-ronmyl", "~doznec-dozzod", "planet", ""}},
+		{"0x100", conversion{kindHex, "256", "256", "0x100", "0x100", "0b100000000", "0b1.0000.0000", "~marzod", "~marzod", "star", ""}},
+		{"0x1.0000", conversion{kindUx, "65536", "65.536", "0x10000", "0x1.0000", "0b10000000000000000", "0b1.0000.0000.0000.0000", "~dapnep-ronmyl", "~doznec-dozzod", "planet", ""}},
+		{"0b1", conversion{kindBinary, "1", "1", "0x1", "0x1", "0b1", "0b1", "~nec", "~nec", "galaxy", ""}},
+		{"0b1.0000.0000", conversion{kindUb, "256", "256", "0x100", "0x100", "0b100000000", "0b1.0000.0000", "~marzod", "~marzod", "star", ""}},
+		{".~ronler-talpur", conversion{kindPatq, "1624961343", "1.624.961.343", "0x60daf13f", "0x60da.f13f", "0b1100000110110101111000100111111", "0b110.0000.1101.1010.1111.0001.0011.1111", "~sampel-palnet", "~ronler-talpur", "planet", ""}},
+		{"~doznec-dozzod", conversion{kindPatp, "2890239458", "2.890.239.458", "0xac458de2", "0xac45.8de2", "0b10101100010001011000110111100010", "0b1010.1100.0100.0101.1000.1101.1110.0010", "~doznec-dozzod", "~batpet-pagfyn", "planet", "also a valid @q, for 65536"}},
+	}
+
+	for _, tt := range tests {
+		got, err := convertAny(tt.in)
+		if err != nil {
+			t.Errorf("%s: %v", tt.in, err)
+			continue
+		}
+		if *got.(*conversion) != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.in, *got.(*conversion), tt.want)
+		}
+	}
+
+	for _, in := range []string{"~foo", "0123", "hello", ".~foo"} {
+		if _, err := convertAny(in); err == nil {
+			t.Errorf("%s: expected an error", in)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/deelawn/urbit-gob/co"
+	"github.com/deelawn/urbit-gob/ob"
+)
+
+// explanation walks through the conversion of a point to its @p, or of a @p
+// back to its point.
+type explanation struct {
+	Input string        `json:"input"`
+	Steps []explainStep `json:"steps"`
+}
+
+type explainStep struct {
+	Title  string   `json:"title"`
+	Detail []string `json:"detail"`
+}
+
+func (e *explanation) add(title string, detail ...string) {
+
+	e.Steps = append(e.Steps, explainStep{title, detail})
+}
+
+func (e *explanation) text() string {
+
+	var b strings.Builder
+	for i, s := range e.Steps {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, s.Title)
+		for _, d := range s.Detail {
+			fmt.Fprintf(&b, "   %s\n", d)
+		}
+	}
+
+	return b.String()
+}
+
+// explainAny explains how a point becomes a @p, or for a @p how it is parsed.
+func explainAny(s string) (interface{}, error) {
+
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "~") {
+		return explainParse(s)
+	}
+
+	p, err := co.ParsePoint(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return explainEncode(p)
+}
+
+func explainEncode(p *big.Int) (*explanation, error) {
+
+	name, err := co.Patp(p)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &explanation{Input: p.String()}
+	if err := e.addClass(p); err != nil {
+		return nil, err
+	}
+
+	scrambled := p
+	if p.BitLen() > 64 {
+		e.add("Scramble", "Comets are wider than 64 bits and are not scrambled.")
+	} else {
+		t := ob.FeinTrace(p.Uint64())
+		e.addScramble(t, true)
+		scrambled = big.NewInt(0).SetUint64(t.Out)
+	}
+
+	e.addChunks(scrambled)
+	e.addSyllables(scrambled, name)
+
+	if scrambled.Cmp(p) != 0 {
+		q, err := co.Patq(p)
+		if err != nil {
+			return nil, err
+		}
+		last := &e.Steps[len(e.Steps)-1]
+		last.Detail = append(last.Detail, fmt.Sprintf("The @q of the point, which is not scrambled, is %s.", q))
+	}
+
+	return e, nil
+}
+
+func explainParse(s string) (*explanation, error) {
+
+	p, err := co.Patp2Point(s)
+	if err != nil {
+		return nil, err
+	}
+	name, err := co.Patp(p)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &explanation{Input: s}
+
+	var detail []string
+	if name != s {
+		detail = append(detail, fmt.Sprintf("%s is written canonically as %s.", s, name))
+	}
+	scrambled := big.NewInt(0)
+	for _, w := range strings.FieldsFunc(name[1:], func(r rune) bool { return r == '-' }) {
+		pre, suf := "", w
+		if len(w) == 6 {
+			pre, suf = w[:3], w[3:]
+		}
+		chunk := indexOf(co.Suffixes, suf)
+		if pre != "" {
+			chunk |= indexOf(co.Prefixes, pre) << 8
+			detail = append(detail, fmt.Sprintf("%s: prefix %s is %d (0x%02x), suffix %s is %d (0x%02x), giving 0x%04x",
+				w, pre, chunk>>8, chunk>>8, suf, chunk&0xff, chunk&0xff, chunk))
+		} else {
+			detail = append(detail, fmt.Sprintf("%s: suffix %d (0x%02x)", w, chunk, chunk))
+		}
+		scrambled.Lsh(scrambled, 16)
+		scrambled.Or(scrambled, big.NewInt(int64(chunk)))
+	}
+	detail = append(detail, fmt.Sprintf("Each word is 16 bits; together they are 0x%s.", scrambled.Text(16)))
+	e.add("Syllables to 16-bit chunks", detail...)
+
+	if scrambled.BitLen() > 64 {
+		e.add("Unscramble", "Comets are wider than 64 bits and are not scrambled.")
+	} else {
+		e.addScramble(ob.FyndTrace(scrambled.Uint64()), false)
+	}
+
+	if err := e.addClass(p); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// addClass explains the class of the ship at p.
+func (e *explanation) addClass(p *big.Int) error {
+
+	class, err := co.ClanPoint(p)
+	if err != nil {
+		return err
+	}
+
+	bits := "bits"
+	if p.BitLen() == 1 {
+		bits = "bit"
+	}
+
+	e.add("Width and class",
+		fmt.Sprintf("The point is %s, or 0x%s, which is %d %s wide.", p, p.Text(16), p.BitLen(), bits),
+		"Galaxies fit in 8 bits, stars in 16, planets in 32 and moons in 64; wider points are comets.",
+		fmt.Sprintf("So it is a %s.", class))
+
+	return nil
+}
+
+// addScramble explains the trace of Fein, or of Fynd if fein is false.
+func (e *explanation) addScramble(t ob.Trace, fein bool) {
+
+	title, fn := "Scramble", "Fein"
+	if !fein {
+		title, fn = "Unscramble", "Fynd"
+	}
+
+	if !t.Scrambled {
+		e.add(title, fmt.Sprintf("The low 32 bits, 0x%x, are below 0x%x, so %s leaves them as they are.", t.Low, ob.FeinMin, fn))
+		return
+	}
+
+	var detail []string
+	if t.High != 0 {
+		detail = append(detail, fmt.Sprintf("The bits above the low 32, 0x%x, are kept as they are.", t.High>>32))
+	}
+	detail = append(detail, fmt.Sprintf("%s runs a 4-round Feistel cipher over the low 32 bits less 0x%x: 0x%08x - 0x%x = 0x%x.",
+		fn, ob.FeinMin, t.Low, ob.FeinMin, t.Passes[0].In))
+
+	for i, p := range t.Passes {
+		if i > 0 {
+			detail = append(detail, fmt.Sprintf("0x%x is out of range, so the cipher runs again over it.", p.In))
+		}
+		if fein {
+			detail = append(detail, fmt.Sprintf("Split into L = 0x%04x and R = 0x%04x, the remainder and quotient of dividing by 0xffff.", p.Left, p.Right))
+		} else {
+			detail = append(detail, fmt.Sprintf("Split into L = 0x%04x and R = 0x%04x, the quotient and remainder of dividing by 0xffff.", p.Left, p.Right))
+		}
+		for _, r := range p.Rounds {
+			if fein {
+				detail = append(detail, fmt.Sprintf("Round %d: F = muk(0x%08x, R = 0x%04x) = 0x%08x; (L + F) mod 0x%x = 0x%04x becomes R, and R becomes L.",
+					r.J, r.Key, r.Right, r.F, r.Mod, r.Out))
+			} else {
+				detail = append(detail, fmt.Sprintf("Round %d: F = muk(0x%08x, L = 0x%04x) = 0x%08x; (R - F) mod 0x%x = 0x%04x becomes L, and L becomes R.",
+					r.J, r.Key, r.Left, r.F, r.Mod, r.Out))
+			}
+		}
+		detail = append(detail, fmt.Sprintf("The halves are joined again as 0x%x.", p.Out))
+	}
+
+	detail = append(detail, fmt.Sprintf("Adding back 0x%x gives 0x%x.", ob.FeinMin, t.Out))
+	e.add(title, detail...)
+}
+
+// addChunks explains how the scrambled value is split into syllable indices.
+func (e *explanation) addChunks(v *big.Int) {
+
+	if v.BitLen() <= 8 {
+		e.add("16-bit chunks", fmt.Sprintf("A galaxy is a single byte, 0x%02x, which is the index of a suffix.", v.Uint64()))
+		return
+	}
+
+	var detail []string
+	detail = append(detail, fmt.Sprintf("0x%s is split into 16-bit chunks, each the index of a prefix (high byte) and a suffix (low byte):", v.Text(16)))
+	for _, c := range chunks(v) {
+		detail = append(detail, fmt.Sprintf("0x%04x: prefix %d (0x%02x), suffix %d (0x%02x)", c, c>>8, c>>8, c&0xff, c&0xff))
+	}
+
+	e.add("16-bit chunks", detail...)
+}
+
+// addSyllables explains how the syllable indices make name.
+func (e *explanation) addSyllables(v *big.Int, name string) {
+
+	if v.BitLen() <= 8 {
+		e.add("Syllables", fmt.Sprintf("Suffix %d is %s, so the name is %s.", v.Uint64(), co.Suffixes[v.Uint64()], name))
+		return
+	}
+
+	var detail []string
+	for _, c := range chunks(v) {
+		pre, suf := co.Prefixes[c>>8], co.Suffixes[c&0xff]
+		detail = append(detail, fmt.Sprintf("Prefix %d is %s and suffix %d is %s: %s%s", c>>8, pre, c&0xff, suf, pre, suf))
+	}
+	detail = append(detail, fmt.Sprintf("The words are joined with dashes, doubled between each 64 bits: %s", name))
+
+	e.add("Syllables", detail...)
+}
+
+// chunks returns the 16-bit chunks of v, most significant first.
+func chunks(v *big.Int) []int {
+
+	var cs []int
+	mask := big.NewInt(0xffff)
+	for rest := big.NewInt(0).Set(v); rest.Sign() > 0; rest.Rsh(rest, 16) {
+		cs = append([]int{int(big.NewInt(0).And(rest, mask).Int64())}, cs...)
+	}
+
+	return cs
+}
+
+func indexOf(list []string, s string) int {
+
+	for i, w := range list {
+		if w == s {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"0", []string{
+			"1. Width and class\n   The point is 0, or 0x0, which is 0 bits wide.",
+			"So it is a galaxy.",
+			"Suffix 0 is zod, so the name is ~zod.",
+		}},
+		{"256", []string{
+			"So it is a star.",
+			"The low 32 bits, 0x100, are below 0x10000, so Fein leaves them as they are.",
+			"0x0100: prefix 1 (0x01), suffix 0 (0x00)",
+			"Prefix 1 is mar and suffix 0 is zod: marzod",
+		}},
+		{"65536", []string{
+			"So it is a planet.",
+			"Split into L = 0x0000 and R = 0x0000, the remainder and quotient of dividing by 0xffff.",
+			"Round 1: F = muk(0xb76d5eed, R = 0x0000) = 0xbe0423ff; (L + F) mod 0xffff = 0xe203 becomes R, and R becomes L.",
+			"Round 4: F = muk(0x4b387af7, R = 0x423d) = 0x00c89697; (L + F) mod 0x10000 = 0xa2fc becomes R, and R becomes L.",
+			"The halves are joined again as 0x423d60bf.",
+			"Adding back 0x10000 gives 0x423e60bf.",
+			"0x423e: prefix 66 (0x42), suffix 62 (0x3e)",
+			"doubled between each 64 bits: ~dapnep-ronmyl",
+			"The @q of the point, which is not scrambled, is ~doznec-dozzod.",
+		}},
+		{"~dapnep-ronmyl", []string{
+			"dapnep: prefix dap is 66 (0x42), suffix nep is 62 (0x3e), giving 0x423e",
+			"Each word is 16 bits; together they are 0x423e60bf.",
+			"Split into L = 0x423d and R = 0xa2fc, the quotient and remainder of dividing by 0xffff.",
+			"Round 4: F = muk(0x4b387af7, L = 0x423d) = 0x00c89697",
+			"Adding back 0x10000 gives 0x10000.",
+			"The point is 65536, or 0x10000, which is 17 bits wide.",
+		}},
+		{"~doznec-sampel-palnet", []string{
+			"So it is a moon.",
+			"The bits above the low 32, 0x1, are kept as they are.",
+			"Adding back 0x10000 gives 0x160daf13f.",
+		}},
+		{"~dozzod-marzod", []string{
+			"~dozzod-marzod is written canonically as ~marzod.",
+		}},
+	}
+
+	for _, tt := range tests {
+		result, err := explainAny(tt.input)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.input, err)
+		}
+		text := formatResult(result)
+		for _, w := range tt.want {
+			if !strings.Contains(text, w) {
+				t.Errorf("explanation of %s does not contain %q:\n%s", tt.input, w, text)
+			}
+		}
+	}
+
+	for _, input := range []string{"x", "~zodd", "-1"} {
+		if _, err := explainAny(input); err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Control keys
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyLF        = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyCR        = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+// errInterrupted is returned by readLine when the line is abandoned with
+// Ctrl-C.
+var errInterrupted = errors.New("interrupted")
+
+// completer returns the candidates for completing the text before the cursor,
+// and the offset in it at which the word they replace begins.
+type completer func(before string) (start int, candidates []string)
+
+// lineEditor reads lines from a terminal in raw mode, with cursor movement,
+// Emacs-style editing keys, history and tab completion.
+type lineEditor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	prompt   string
+	history  []string
+	complete completer
+
+	buf []rune
+	pos int
+}
+
+func newLineEditor(in io.Reader, out io.Writer, prompt string) *lineEditor {
+
+	return &lineEditor{
+		in:     bufio.NewReader(in),
+		out:    out,
+		prompt: prompt,
+	}
+}
+
+// addHistory appends a line to the history, unless it is blank or repeats
+// the previous line.
+func (e *lineEditor) addHistory(line string) {
+
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if n := len(e.history); n > 0 && e.history[n-1] == line {
+		return
+	}
+
+	e.history = append(e.history, line)
+}
+
+// readLine reads a line. It returns io.EOF on Ctrl-D at an empty line and
+// errInterrupted on Ctrl-C.
+func (e *lineEditor) readLine() (string, error) {
+
+	e.buf, e.pos = e.buf[:0], 0
+
+	// The line being edited is kept at the end of the history while older
+	// entries are browsed.
+	hist := append(append([]string(nil), e.history...), "")
+	hidx := len(hist) - 1
+
+	e.refresh()
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case keyCR, keyLF:
+			fmt.Fprint(e.out, "\r\n")
+			return string(e.buf), nil
+
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errInterrupted
+
+		case keyCtrlD:
+			if len(e.buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteAt(e.pos)
+
+		case keyCtrlA:
+			e.pos = 0
+		case keyCtrlE:
+			e.pos = len(e.buf)
+		case keyCtrlB:
+			e.move(-1)
+		case keyCtrlF:
+			e.move(1)
+
+		case keyBackspace, keyCtrlH:
+			if e.pos > 0 {
+				e.pos--
+				e.deleteAt(e.pos)
+			}
+		case keyCtrlK:
+			e.buf = e.buf[:e.pos]
+		case keyCtrlU:
+			e.buf = append(e.buf[:0], e.buf[e.pos:]...)
+			e.pos = 0
+		case keyCtrlW:
+			start := e.pos
+			for start > 0 && unicode.IsSpace(e.buf[start-1]) {
+				start--
+			}
+			for start > 0 && !unicode.IsSpace(e.buf[start-1]) {
+				start--
+			}
+			e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+			e.pos = start
+
+		case keyCtrlL:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+
+		case keyCtrlP:
+			hidx = e.browse(hist, hidx, -1)
+		case keyCtrlN:
+			hidx = e.browse(hist, hidx, 1)
+
+		case keyTab:
+			e.tab()
+
+		case keyEscape:
+			hidx = e.escape(hist, hidx)
+
+		default:
+			if unicode.IsPrint(r) {
+				e.buf = append(e.buf, 0)
+				copy(e.buf[e.pos+1:], e.buf[e.pos:])
+				e.buf[e.pos] = r
+				e.pos++
+			}
+		}
+
+		e.refresh()
+	}
+}
+
+// escape handles the rest of an escape sequence for the arrow, home, end and
+// delete keys, ignoring any other.
+func (e *lineEditor) escape(hist []string, hidx int) int {
+
+	b, err := e.in.ReadByte()
+	if err != nil || (b != '[' && b != 'O') {
+		return hidx
+	}
+
+	// Read parameters up to the final byte of the sequence.
+	var param []byte
+	for {
+		c, err := e.in.ReadByte()
+		if err != nil {
+			return hidx
+		}
+		if c >= 0x40 && c <= 0x7e {
+			b = c
+			break
+		}
+		param = append(param, c)
+	}
+
+	switch b {
+	case 'A':
+		return e.browse(hist, hidx, -1)
+	case 'B':
+		return e.browse(hist, hidx, 1)
+	case 'C':
+		e.move(1)
+	case 'D':
+		e.move(-1)
+	case 'H':
+		e.pos = 0
+	case 'F':
+		e.pos = len(e.buf)
+	case '~':
+		switch string(param) {
+		case "1", "7":
+			e.pos = 0
+		case "4", "8":
+			e.pos = len(e.buf)
+		case "3":
+			e.deleteAt(e.pos)
+		}
+	}
+
+	return hidx
+}
+
+func (e *lineEditor) move(n int) {
+
+	e.pos += n
+	if e.pos < 0 {
+		e.pos = 0
+	}
+	if e.pos > len(e.buf) {
+		e.pos = len(e.buf)
+	}
+}
+
+func (e *lineEditor) deleteAt(i int) {
+
+	if i < len(e.buf) {
+		e.buf = append(e.buf[:i], e.buf[i+1:]...)
+	}
+}
+
+// browse moves through the history by n entries, saving any edits to the
+// entry being left, and returns the new index.
+func (e *lineEditor) browse(hist []string, hidx, n int) int {
+
+	next := hidx + n
+	if next < 0 || next >= len(hist) {
+		return hidx
+	}
+
+	hist[hidx] = string(e.buf)
+	e.buf = []rune(hist[next])
+	e.pos = len(e.buf)
+
+	return next
+}
+
+// tab completes the word before the cursor as far as the common prefix of the
+// candidates and, if that adds nothing, lists them below the line. Candidates
+// that complete a whole word end in a space.
+func (e *lineEditor) tab() {
+
+	if e.complete == nil {
+		return
+	}
+
+	before := string(e.buf[:e.pos])
+	start, candidates := e.complete(before)
+	if len(candidates) == 0 {
+		return
+	}
+
+	word := before[start:]
+	insert := commonPrefix(candidates)
+
+	if len(insert) > len(word) {
+		rest := []rune(insert[len(word):])
+		e.buf = append(e.buf[:e.pos], append(rest, e.buf[e.pos:]...)...)
+		e.pos += len(rest)
+		return
+	}
+
+	if len(candidates) > 1 {
+		list := make([]string, len(candidates))
+		for i, c := range candidates {
+			list[i] = strings.TrimSpace(c)
+		}
+		fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(list, "  "))
+	}
+}
+
+// refresh redraws the prompt and line and places the cursor.
+func (e *lineEditor) refresh() {
+
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K\r", e.prompt, string(e.buf))
+	if n := len([]rune(e.prompt)) + e.pos; n > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dC", n)
+	}
+}
+
+func commonPrefix(words []string) string {
+
+	prefix := words[0]
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	return prefix
+}
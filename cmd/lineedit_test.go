@@ -0,0 +1,83 @@
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestLineEditor(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		keys  string
+		lines []string
+	}{
+		{"plain", "patp 0\r", []string{"patp 0"}},
+		{"backspace", "patq\x7fp 0\r", []string{"patp 0"}},
+		{"insert", "pp 0\x01\x1b[Cat\r", []string{"patp 0"}},
+		{"home end", "atp\x01p\x05 0\r", []string{"patp 0"}},
+		{"delete", "xpatp 0\x01\x1b[3~\r", []string{"patp 0"}},
+		{"kill", "patp 0 junk\x02\x02\x02\x02\x02\x0b\r", []string{"patp 0"}},
+		{"kill line", "junk\x15patp 0\r", []string{"patp 0"}},
+		{"kill word", "patp junk\x17\x170\r", []string{"0"}},
+		{"history", "patp 0\r\x1b[A\x7f1\r\x10\x10\r", []string{"patp 0", "patp 1", "patp 0"}},
+		{"history edit kept", "one\rtwo\x1b[A\x1b[B\r", []string{"one", "two"}},
+		{"complete", "inf\t~zod\r", []string{"info ~zod"}},
+		{"complete ambiguous", "pat\t\r", []string{"pat"}},
+		{"complete syllable", "info ~sampel-paln\tet\r", []string{"info ~sampel-palnet"}},
+	}
+
+	for _, tt := range tests {
+		e := newLineEditor(strings.NewReader(tt.keys), ioutil.Discard, "> ")
+		e.complete = completeREPL
+
+		for _, want := range tt.lines {
+			got, err := e.readLine()
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if got != want {
+				t.Errorf("%s: got %q, want %q", tt.name, got, want)
+			}
+			e.addHistory(got)
+		}
+	}
+}
+
+func TestLineEditorControl(t *testing.T) {
+
+	e := newLineEditor(strings.NewReader("junk\x03\x04"), ioutil.Discard, "> ")
+	if _, err := e.readLine(); err != errInterrupted {
+		t.Errorf("Ctrl-C: got %v, want errInterrupted", err)
+	}
+	if _, err := e.readLine(); err != io.EOF {
+		t.Errorf("Ctrl-D: got %v, want io.EOF", err)
+	}
+}
+
+func TestCompleteREPL(t *testing.T) {
+
+	tests := []struct {
+		before     string
+		start      int
+		candidates []string
+	}{
+		{"inf", 0, []string{"info "}},
+		{":j", 0, []string{":json "}},
+		{"patp2", 0, []string{"patp2dec ", "patp2hex ", "patp2point "}},
+		{"info ~sampel-palne", 13, []string{"palnec", "palnex", "palnep", "palnet", "palneb", "palnem", "palner", "palned", "palnes", "palnel", "palnev"}},
+		{"info ~fe", 6, []string{"feb", "fel", "fep", "fer", "fex", "fen", "fet", "fed", "fes"}},
+		{"info ~dapnep-ro", 13, []string{"rov", "roc", "rop", "ron", "rol", "ros"}},
+		{"info ~xyzabc", 6, nil},
+		{"patp 12", 5, nil},
+	}
+
+	for _, tt := range tests {
+		start, candidates := completeREPL(tt.before)
+		if start != tt.start || strings.Join(candidates, ",") != strings.Join(tt.candidates, ",") {
+			t.Errorf("completeREPL(%q) = %d, %q; want %d, %q", tt.before, start, candidates, tt.start, tt.candidates)
+		}
+	}
+}
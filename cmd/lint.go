@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/deelawn/urbit-gob/co"
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+const (
+	cmdLint string = "lint"
+
+	// binarySniff is how much of a file is searched for a NUL byte to decide
+	// that it is binary and skip it.
+	binarySniff int = 8000
+)
+
+// patToken matches a @p, or a @q written with Hoon's .~ prefix, with the
+// character before it. Names inside paths (/home/~user) and words are left
+// alone.
+var patToken = regexp.MustCompile(`(?m)(?:^|[^A-Za-z0-9_/~.-])(\.?~[A-Za-z]+(?:--?[A-Za-z]+)*)`)
+
+// finding is a name that is invalid, or valid but not written canonically.
+type finding struct {
+	line, col int
+
+	// start and end are the byte offsets of the name in the text.
+	start, end int
+
+	name string
+
+	// fix is the canonical form of the name, or empty if it is invalid.
+	fix string
+	err error
+}
+
+func (f finding) String() string {
+
+	if f.fix == "" {
+		return f.err.Error()
+	}
+
+	return fmt.Sprintf("%s is not canonical, want %s", f.name, f.fix)
+}
+
+// lintArgs are the flags of lint.
+type lintArgs struct {
+	fix bool
+}
+
+func (a *lintArgs) flags(g *globals) *flag.FlagSet {
+
+	fs := flag.NewFlagSet(cmdLint, flag.ContinueOnError)
+	fs.BoolVar(&a.fix, "fix", false, "rewrite files with every name that has a canonical form in that form")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [--fix] PATH...\n\n", os.Args[0], cmdLint)
+		fmt.Fprintf(fs.Output(), "Reports every @p, and every @q written as .~name, that is invalid or not\n")
+		fmt.Fprintf(fs.Output(), "written canonically, in the files given and in the files below the\n")
+		fmt.Fprintf(fs.Output(), "directories given. Exits with %d if there are any, even once fixed.\n\n", codeFindings)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+func runLint(g *globals, args []string) int {
+
+	var a lintArgs
+	fs := a.flags(g)
+
+	paths, err := parseArgs(fs, args)
+	if err != nil {
+		return codeInsufficientArguments
+	}
+	if len(paths) == 0 {
+		fs.Usage()
+		return codeInsufficientArguments
+	}
+
+	code := 0
+	for _, root := range paths {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				if path != root && strings.HasPrefix(info.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+
+			n, err := lintFile(path, info, a.fix)
+			if err != nil {
+				return err
+			}
+			if n > 0 && code == 0 {
+				code = codeFindings
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = codeErrorReturned
+		}
+	}
+
+	return code
+}
+
+// lintFile reports the findings in a file, and with fix rewrites it with
+// those that can be fixed fixed. It returns the number of findings.
+func lintFile(path string, info os.FileInfo, fix bool) (int, error) {
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	sniff := data
+	if len(sniff) > binarySniff {
+		sniff = sniff[:binarySniff]
+	}
+	if bytes.IndexByte(sniff, 0) >= 0 {
+		return 0, nil
+	}
+
+	findings := lint(data)
+	fixed := 0
+	for _, f := range findings {
+		msg := f.String()
+		if fix && f.fix != "" {
+			msg = "fixed: " + msg
+			fixed++
+		}
+		fmt.Printf("%s:%d:%d: %s\n", path, f.line, f.col, msg)
+	}
+
+	if fixed > 0 {
+		if err := ioutil.WriteFile(path, applyFixes(data, findings), info.Mode().Perm()); err != nil {
+			return len(findings), err
+		}
+	}
+
+	return len(findings), nil
+}
+
+// lint returns the findings in text, in order.
+func lint(text []byte) []finding {
+
+	var findings []finding
+	line, lineStart, scanned := 1, 0, 0
+
+	for _, m := range patToken.FindAllSubmatchIndex(text, -1) {
+		start, end := m[2], m[3]
+		if end < len(text) && isNameChar(text[end]) {
+			continue
+		}
+
+		name := string(text[start:end])
+		q := strings.HasPrefix(name, ".")
+		if q {
+			name = name[1:]
+			start++
+		}
+		if !looksLikeName(name) {
+			continue
+		}
+
+		canon, err := canonicalName(name, q)
+		if err == nil && canon == name {
+			continue
+		}
+
+		for ; scanned < start; scanned++ {
+			if text[scanned] == '\n' {
+				line++
+				lineStart = scanned + 1
+			}
+		}
+
+		findings = append(findings, finding{
+			line:  line,
+			col:   start - lineStart + 1,
+			start: start,
+			end:   end,
+			name:  name,
+			fix:   canon,
+			err:   err,
+		})
+	}
+
+	return findings
+}
+
+// isNameChar reports whether c may not follow a name, as it continues a word
+// or path.
+func isNameChar(c byte) bool {
+
+	return c == '_' || c == '/' || c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+// looksLikeName reports whether a token is meant as a name, rather than, say,
+// a user's home directory: either it is split into words by dashes, or its
+// letters make whole syllables.
+func looksLikeName(name string) bool {
+
+	return strings.Contains(name, "-") || (len(name)-1)%3 == 0
+}
+
+// canonicalName returns the canonical form of a @p, or with q a @q, written in
+// any case and with any dashes.
+func canonicalName(name string, q bool) (string, error) {
+
+	lower := strings.ToLower(name)
+	if !q {
+		p, err := co.Patp2Point(lower)
+		if err != nil {
+			return "", fmt.Errorf(ugi.ErrInvalidP, name)
+		}
+		return co.Patp(p)
+	}
+
+	// The @q parser accepts words of any length, so they are checked here.
+	for i, w := range strings.Split(lower[1:], "-") {
+		if len(w) != 6 && !(i == 0 && len(w) == 3) {
+			return "", fmt.Errorf(ugi.ErrInvalidQ, name)
+		}
+	}
+	p, err := co.Patq2Point(lower)
+	if err != nil {
+		return "", fmt.Errorf(ugi.ErrInvalidQ, name)
+	}
+
+	return co.Patq(p)
+}
+
+// applyFixes returns text with every finding that can be fixed fixed.
+func applyFixes(text []byte, findings []finding) []byte {
+
+	var b bytes.Buffer
+	last := 0
+	for _, f := range findings {
+		if f.fix == "" {
+			continue
+		}
+		b.Write(text[last:f.start])
+		b.WriteString(f.fix)
+		last = f.end
+	}
+	b.Write(text[last:])
+
+	return b.Bytes()
+}
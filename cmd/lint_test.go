@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+
+	text := "" +
+		"allow:\n" +
+		"  - ~sampel-palnet\n" +
+		"  - ~Sampel-Palnet\n" +
+		"  - \"~sampelpalnet\"\n" +
+		"  - ~dozzod-marzod # star\n" +
+		"  - ~zod-zod\n" +
+		"home: /home/~user ~alice ~zod_x ~nec2 ~bud-\n" +
+		"codes: [.~doznec-dozzod, .~dozzod-marzod, .~sampelpalnet]\n"
+
+	want := []string{
+		"3:5: ~Sampel-Palnet is not canonical, want ~sampel-palnet",
+		"4:6: ~sampelpalnet is not canonical, want ~sampel-palnet",
+		"5:5: ~dozzod-marzod is not canonical, want ~marzod",
+		"6:5: invalid @p: ~zod-zod",
+		"8:27: ~dozzod-marzod is not canonical, want ~marzod",
+		"8:44: invalid @q: ~sampelpalnet",
+	}
+
+	findings := lint([]byte(text))
+	var got []string
+	for _, f := range findings {
+		got = append(got, fmt.Sprintf("%d:%d: %v", f.line, f.col, f))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got findings\n%q\nwant\n%q", got, want)
+	}
+
+	wantFixed := "" +
+		"allow:\n" +
+		"  - ~sampel-palnet\n" +
+		"  - ~sampel-palnet\n" +
+		"  - \"~sampel-palnet\"\n" +
+		"  - ~marzod # star\n" +
+		"  - ~zod-zod\n" +
+		"home: /home/~user ~alice ~zod_x ~nec2 ~bud-\n" +
+		"codes: [.~doznec-dozzod, .~marzod, .~sampelpalnet]\n"
+
+	if fixed := string(applyFixes([]byte(text), findings)); fixed != wantFixed {
+		t.Errorf("fixed text\n%s\nwant\n%s", fixed, wantFixed)
+	}
+
+	if findings := lint(applyFixes([]byte(text), findings)); len(findings) != 2 {
+		t.Errorf("%d findings after fixing, want the 2 that cannot be fixed", len(findings))
+	}
+}
@@ -1,172 +1,246 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
-	"math/big"
 	"os"
-	"strconv"
-
-	"github.com/deelawn/urbit-gob/co"
+	"strings"
 )
 
 const (
-	// Commands
-	cmdPatp       string = "patp"
-	cmdPatp2Dec   string = "patp2dec"
-	cmdPatp2Hex   string = "patp2hex"
-	cmdPatp2Point string = "patp2point"
-
-	cmdPatq       string = "patq"
-	cmdPatq2Dec   string = "patq2dec"
-	cmdPatq2Hex   string = "patq2hex"
-	cmdPatq2Point string = "patq2point"
-
-	cmdPoint2Patp string = "point2patp"
-	cmdPoint2Patq string = "point2patq"
-
-	cmdHex2Patp string = "hex2patp"
-	cmdHex2Patq string = "hex2patq"
-
-	cmdClan      string = "clan"
-	cmdClanPoint string = "clanpoint"
-	cmdSein      string = "sein"
-	cmdSeinPoint string = "seinpoint"
-	cmdEqPatq    string = "eqpatq"
-
-	cmdIsValidPat  string = "isvalidpat"
-	cmdIsValidPatp string = "isvalidpatp"
-	cmdIsValidPatq string = "isvalidpatq"
-
 	// Exit codes
 	codeInsufficientArguments int = 1
 	codeInvalidCommand        int = 2
 	codeErrorReturned         int = 3
+	codeFindings              int = 4
 
 	// Others
-	minArgLen        int    = 2
+	minArgLen        int    = 1
 	usageCmdFmtStr   string = "    %-20s: %s\n"
 	errInvalidCmdStr string = "invalid command: %s\n"
 	errMissingEqArg  string = "missing second argument for equality check"
 )
 
+// globals are the flags shared by every command.
+type globals struct {
+	file      string
+	null      bool
+	keepGoing bool
+	workers   int
+	output    string
+}
+
 func main() {
 
+	var g globals
+
+	flag.StringVar(&g.file, "file", "", "read inputs from `path` instead of the command line")
+	flag.BoolVar(&g.null, "null", false, "inputs and results are separated by NUL instead of newline")
+	flag.BoolVar(&g.null, "z", false, "shorthand for --null")
+	flag.BoolVar(&g.keepGoing, "continue", false, "report failed inputs and carry on instead of stopping at the first")
+	flag.BoolVar(&g.keepGoing, "k", false, "shorthand for --continue")
+	flag.IntVar(&g.workers, "parallel", 1, "number of inputs to convert in parallel")
+	flag.IntVar(&g.workers, "j", 1, "shorthand for --parallel")
+	flag.StringVar(&g.output, "output", outputText, "output `format`: text, json, csv or tsv")
+	flag.StringVar(&g.output, "o", outputText, "shorthand for --output")
+
 	flag.Usage = func() {
-		fmt.Printf("Usage: %s COMMAND args...\n\n", os.Args[0])
-		fmt.Printf("Valid commands:\n\n")
-		fmt.Printf(usageCmdFmtStr, cmdPatp, "converts a number to a @p-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdPatp2Dec, "converts a @p-encoded string to a decimal-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdPatp2Hex, "converts a @p-encoded string to a hex-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdPatp2Point, "converts a @p-encoded string to a big.Int\n")
-		fmt.Printf(usageCmdFmtStr, cmdPatq, "converts a number to a @q-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdPatq2Dec, "converts a @q-encoded string to a decimal-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdPatq2Hex, "converts a @q-encoded string to a hex-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdPatq2Point, "converts a @q-encoded string to a big.Int\n")
-		fmt.Printf(usageCmdFmtStr, cmdPoint2Patp, "converts a big.Int to a @p-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdPoint2Patq, "converts a big.Int to a @q-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdHex2Patp, "converts a hex-encoded string to a @p-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdHex2Patq, "converts a hex-encoded string to a @q-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdClan, "determines the ship class of a @p value\n")
-		fmt.Printf(usageCmdFmtStr, cmdClanPoint, "determines the ship class of a big.Int\n")
-		fmt.Printf(usageCmdFmtStr, cmdSein, "determines the parent of a @p value\n")
-		fmt.Printf(usageCmdFmtStr, cmdSeinPoint, "determines the parent of a big.Int\n")
-		fmt.Printf(usageCmdFmtStr, cmdEqPatq, "performs an equality comparison on @q values\n")
-		fmt.Printf(usageCmdFmtStr, cmdIsValidPat, "weakly checks if a string is a valid @p or @q value\n")
-		fmt.Printf(usageCmdFmtStr, cmdIsValidPatp, "validates a @p string\n")
-		fmt.Printf(usageCmdFmtStr, cmdIsValidPatq, "validates a @q string\n")
+		w := flag.CommandLine.Output()
+		fmt.Fprintf(w, "Usage: %s [flags] COMMAND [args... | -]\n\n", os.Args[0])
+		fmt.Fprintf(w, "With no args, or -, each COMMAND reads one input per line from stdin and\n")
+		fmt.Fprintf(w, "writes one result per line. Inputs of eqpatq are two whitespace-separated values.\n\n")
+		fmt.Fprintf(w, "Valid commands:\n\n")
+		for _, cmd := range commands {
+			fmt.Fprintf(w, usageCmdFmtStr, cmd.name, cmd.summary+"\n")
+		}
+		for _, t := range tools {
+			fmt.Fprintf(w, usageCmdFmtStr, t.name, t.summary+"\n")
+		}
+		fmt.Fprintf(w, "Flags:\n\n")
+		flag.PrintDefaults()
 	}
 
+	// Tools parse their own args, so only the flags before the command are
+	// global for them.
 	flag.Parse()
+	if t, ok := lookupTool(flag.Arg(0)); ok {
+		os.Exit(t.run(&g, flag.Args()[1:]))
+	}
 
-	var (
-		result interface{}
-		err    error
-	)
+	args, err := parseArgs(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		os.Exit(codeInsufficientArguments)
+	}
 
-	args := flag.Args()
 	if len(args) < minArgLen {
 		flag.Usage()
 		os.Exit(codeInsufficientArguments)
 	}
 
-	switch args[0] {
-
-	case cmdPatp:
-		result, err = co.Patp(args[1])
-	case cmdPatp2Dec:
-		result, err = co.Patp2Dec(args[1])
-	case cmdPatp2Hex:
-		result, err = co.Patp2Hex(args[1])
-	case cmdPatp2Point:
-		result, err = co.Patp2Point(args[1])
-	case cmdPatq:
-		result, err = co.Patq(args[1])
-	case cmdPatq2Dec:
-		result, err = co.Patq2Dec(args[1])
-	case cmdPatq2Hex:
-		result, err = co.Patq2Hex(args[1])
-	case cmdPatq2Point:
-		result, err = co.Patq2Point(args[1])
-	case cmdPoint2Patp:
-		i, err := strconv.ParseInt(args[1], 10, 64)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(codeErrorReturned)
+	cmd, ok := lookupCommand(args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, errInvalidCmdStr, args[0])
+		flag.Usage()
+		os.Exit(codeInvalidCommand)
+	}
+
+	delim := byte('\n')
+	if g.null {
+		delim = 0
+	}
+
+	out, err := newRecordWriter(g.output, os.Stdout, os.Stderr, delim, g.keepGoing)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(codeInsufficientArguments)
+	}
+
+	inputs := args[1:]
+	if g.file == "" && len(inputs) > 0 && !(len(inputs) == 1 && inputs[0] == "-") {
+		os.Exit(runArgs(cmd, inputs, out, g.keepGoing))
+	}
+
+	opts := streamOptions{
+		source:    "<stdin>",
+		delim:     delim,
+		keepGoing: g.keepGoing,
+		workers:   g.workers,
+	}
+
+	in := os.Stdin
+	if g.file != "" {
+		if len(inputs) > 0 {
+			fmt.Fprintln(os.Stderr, "--file cannot be combined with args")
+			os.Exit(codeInsufficientArguments)
 		}
-		result, err = co.Point2Patp(big.NewInt(i))
-	case cmdPoint2Patq:
-		i, err := strconv.ParseInt(args[1], 10, 64)
-		if err != nil {
-			fmt.Println(err)
+		if in, err = os.Open(g.file); err != nil {
+			fmt.Fprintln(os.Stderr, err)
 			os.Exit(codeErrorReturned)
 		}
-		result, err = co.Point2Patq(big.NewInt(i))
-	case cmdHex2Patp:
-		result, err = co.Hex2Patp(args[1])
-	case cmdHex2Patq:
-		result, err = co.Hex2Patq(args[1])
-	case cmdClan:
-		result, err = co.Clan(args[1])
-	case cmdClanPoint:
-		i, err := strconv.ParseInt(args[1], 10, 64)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(codeErrorReturned)
+		defer in.Close()
+		opts.source = g.file
+	}
+
+	failed, err := stream(context.Background(), cmd, in, out, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(codeErrorReturned)
+	}
+	if failed > 0 {
+		os.Exit(codeErrorReturned)
+	}
+}
+
+// runArgs converts the inputs given on the command line, each made of
+// cmd.nargs args, and returns the exit code.
+func runArgs(cmd command, args []string, out recordWriter, keepGoing bool) int {
+
+	if len(args)%cmd.nargs != 0 {
+		fmt.Fprintln(os.Stderr, errMissingEqArg)
+		flag.Usage()
+		return codeInsufficientArguments
+	}
+
+	failed, err := applyArgs(cmd, args, out, keepGoing)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeErrorReturned
+	}
+	if failed > 0 {
+		return codeErrorReturned
+	}
+
+	return 0
+}
+
+// applyArgs converts inputs of cmd.nargs args each and writes the outcomes to
+// out, stopping after the first failure unless keepGoing is set. It returns
+// the number of failed inputs and any error writing.
+func applyArgs(cmd command, args []string, out recordWriter, keepGoing bool) (int, error) {
+
+	failed := 0
+	for i := 0; i+cmd.nargs <= len(args); i += cmd.nargs {
+		input := args[i : i+cmd.nargs]
+		result, err := cmd.apply(input)
+		if err := out.write(outcome{input: strings.Join(input, " "), result: result, err: err}); err != nil {
+			return failed, err
 		}
-		result, err = co.ClanPoint(big.NewInt(i))
-	case cmdSein:
-		result, err = co.Sein(args[1])
-	case cmdSeinPoint:
-		i, err := strconv.ParseInt(args[1], 10, 64)
+
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(codeErrorReturned)
+			failed++
+			if !keepGoing {
+				break
+			}
 		}
-		result, err = co.SeinPoint(big.NewInt(i))
-	case cmdIsValidPat:
-		result = co.IsValidPat(args[1])
-	case cmdIsValidPatp:
-		result = co.IsValidPatp(args[1])
-	case cmdIsValidPatq:
-		result = co.IsValidPatq(args[1])
-	case cmdEqPatq:
-		if len(args) < 3 {
-			fmt.Println(errMissingEqArg)
-			flag.Usage()
-			os.Exit(codeInsufficientArguments)
+	}
+
+	return failed, out.flush()
+}
+
+// parseArgs parses the flags in args, which may appear before, between or
+// after the positional args, and returns the positional args. Everything after
+// a "--" is positional, as are negative numbers such as -1 that are not the
+// value of a flag, so that commands can report them as out of range.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+
+	var positional []string
+	for {
+		n := flagsEnd(fs, args)
+		if err := fs.Parse(args[:n]); err != nil {
+			return nil, err
 		}
-		result, err = co.EqPatq(args[1], args[2])
-	default:
-		fmt.Printf(errInvalidCmdStr, args[0])
-		flag.Usage()
-		os.Exit(codeInvalidCommand)
+
+		rest := append(append([]string{}, fs.Args()...), args[n:]...)
+		if consumed := args[:len(args)-len(rest)]; len(consumed) > 0 && consumed[len(consumed)-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
 	}
+}
 
-	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(codeErrorReturned)
+// flagsEnd returns the index of the first negative number in args that is
+// not the value of a flag, or len(args) if there is none before a "--".
+func flagsEnd(fs *flag.FlagSet, args []string) int {
+
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if isNegative(arg) && (i == 0 || !takesValue(fs, args[i-1])) {
+			return i
+		}
+	}
+
+	return len(args)
+}
+
+// isNegative reports whether arg is a negative decimal number, plain or
+// dotted.
+func isNegative(arg string) bool {
+
+	return len(arg) > 1 && arg[0] == '-' && arg[1] >= '0' && arg[1] <= '9' &&
+		strings.Trim(arg[1:], "0123456789.") == ""
+}
+
+// takesValue reports whether arg is a flag of fs whose value is the next
+// argument.
+func takesValue(fs *flag.FlagSet, arg string) bool {
+
+	name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+	if name == arg || strings.Contains(name, "=") {
+		return false
+	}
+	f := fs.Lookup(name)
+	if f == nil {
+		return false
 	}
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
 
-	fmt.Println(result)
+	return !ok || !b.IsBoolFlag()
 }
@@ -0,0 +1,163 @@
+package main
+
+// openAPISpec describes the API served by serve. The command enum must list
+// every command taking a single value; eqpatq has a path of its own.
+const openAPISpec string = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "urbit-gob",
+    "description": "Converts Urbit points to and from @p and @q names.",
+    "version": "1.0.0"
+  },
+  "paths": {
+    "/{command}/{value}": {
+      "get": {
+        "summary": "Applies a command to a single value",
+        "description": "Points may be decimal, 0x hex or 0b binary, with or without Urbit's dot grouping, and of any size.",
+        "parameters": [
+          {
+            "name": "command",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "enum": [
+                "patp", "patp2dec", "patp2hex", "patp2point",
+                "patq", "patq2dec", "patq2hex", "patq2point",
+                "point2patp", "point2patq", "hex2patp", "hex2patq",
+                "clan", "clanpoint", "sein", "seinpoint",
+                "isvalidpat", "isvalidpatp", "isvalidpatq",
+                "convert", "explain", "info"
+              ]
+            }
+          },
+          {
+            "name": "value",
+            "in": "path",
+            "required": true,
+            "schema": {"type": "string"},
+            "example": "~sampel-palnet"
+          }
+        ],
+        "responses": {
+          "200": {"$ref": "#/components/responses/Result"},
+          "400": {"$ref": "#/components/responses/Result"},
+          "404": {"$ref": "#/components/responses/Error"},
+          "405": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/eqpatq/{p}/{q}": {
+      "get": {
+        "summary": "Compares two @q values",
+        "parameters": [
+          {"name": "p", "in": "path", "required": true, "schema": {"type": "string"}, "example": "~dozzod-marzod"},
+          {"name": "q", "in": "path", "required": true, "schema": {"type": "string"}, "example": "~marzod"}
+        ],
+        "responses": {
+          "200": {"$ref": "#/components/responses/Result"},
+          "400": {"$ref": "#/components/responses/Result"},
+          "405": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/batch": {
+      "post": {
+        "summary": "Applies a command to many inputs",
+        "description": "Results are in the order of the inputs. A failed input does not fail the batch.",
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {"$ref": "#/components/schemas/BatchRequest"},
+              "example": {"command": "patp", "inputs": ["0", "65536"]}
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "The result or error of every input",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/BatchResponse"}}}
+          },
+          "400": {"$ref": "#/components/responses/Error"},
+          "405": {"$ref": "#/components/responses/Error"},
+          "413": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/openapi.json": {
+      "get": {
+        "summary": "Returns this description",
+        "responses": {"200": {"description": "The OpenAPI description of the API"}}
+      }
+    }
+  },
+  "components": {
+    "responses": {
+      "Result": {
+        "description": "The result of the input, or why it is invalid",
+        "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Result"}}}
+      },
+      "Error": {
+        "description": "The request cannot be served",
+        "content": {
+          "application/json": {
+            "schema": {
+              "type": "object",
+              "required": ["error"],
+              "properties": {"error": {"$ref": "#/components/schemas/Error"}}
+            }
+          }
+        }
+      }
+    },
+    "schemas": {
+      "Error": {
+        "type": "object",
+        "required": ["code", "message"],
+        "properties": {
+          "code": {
+            "type": "string",
+            "enum": ["not_found", "method_not_allowed", "bad_request", "too_large", "invalid_input", "internal"]
+          },
+          "message": {"type": "string"}
+        }
+      },
+      "Result": {
+        "type": "object",
+        "required": ["input"],
+        "properties": {
+          "input": {"type": "string"},
+          "result": {
+            "description": "A string, with points written in decimal; a boolean for isvalidpat, isvalidpatp, isvalidpatq and eqpatq; or an object for convert and info.",
+            "oneOf": [{"type": "string"}, {"type": "boolean"}, {"type": "object"}]
+          },
+          "error": {"$ref": "#/components/schemas/Error"}
+        }
+      },
+      "BatchRequest": {
+        "type": "object",
+        "required": ["command", "inputs"],
+        "properties": {
+          "command": {"type": "string"},
+          "inputs": {
+            "type": "array",
+            "items": {
+              "description": "A value, or for eqpatq either two values separated by whitespace or an array of two values.",
+              "oneOf": [{"type": "string"}, {"type": "array", "items": {"type": "string"}}]
+            }
+          }
+        }
+      },
+      "BatchResponse": {
+        "type": "object",
+        "required": ["results", "failed"],
+        "properties": {
+          "results": {"type": "array", "items": {"$ref": "#/components/schemas/Result"}},
+          "failed": {"type": "integer", "description": "The number of inputs that failed"}
+        }
+      }
+    }
+  }
+}
+`
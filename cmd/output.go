@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+const (
+	// Output formats
+	outputText string = "text"
+	outputJSON string = "json"
+	outputCSV  string = "csv"
+	outputTSV  string = "tsv"
+
+	errInvalidOutputStr string = "invalid output format: %s (want text, json, csv or tsv)"
+)
+
+// outcome is the conversion of a single input.
+type outcome struct {
+	// source and line locate the input in a stream; line is zero for inputs
+	// given on the command line.
+	source string
+	line   int
+
+	input  string
+	result interface{}
+	err    error
+}
+
+// diagnostic renders the error of a failed outcome for stderr.
+func (o outcome) diagnostic() string {
+
+	if o.line == 0 {
+		return o.err.Error()
+	}
+
+	return fmt.Sprintf("%s:%d: %v", o.source, o.line, o.err)
+}
+
+// recordWriter writes outcomes in one of the output formats.
+type recordWriter interface {
+	write(o outcome) error
+	flush() error
+}
+
+// newRecordWriter returns a writer for format that writes records to w and
+// diagnostics to errw. Text and JSON records are terminated by delim; CSV and
+// TSV always use newlines.
+//
+// In text format only results are written to w, and failures are reported to
+// errw; if placeholders is set an empty record stands in for each failure so
+// that results stay aligned with their inputs. The other formats write a
+// record with the input and either its result or its error for every outcome.
+func newRecordWriter(format string, w, errw io.Writer, delim byte, placeholders bool) (recordWriter, error) {
+
+	out := bufio.NewWriter(w)
+
+	switch format {
+	case outputText:
+		return &textWriter{out: out, errw: errw, delim: delim, placeholders: placeholders}, nil
+	case outputJSON:
+		return &jsonWriter{out: out, delim: delim}, nil
+	case outputCSV, outputTSV:
+		cw := csv.NewWriter(out)
+		if format == outputTSV {
+			cw.Comma = '\t'
+		}
+		return &csvWriter{out: out, csv: cw}, nil
+	default:
+		return nil, fmt.Errorf(errInvalidOutputStr, format)
+	}
+}
+
+type textWriter struct {
+	out          *bufio.Writer
+	errw         io.Writer
+	delim        byte
+	placeholders bool
+}
+
+func (t *textWriter) write(o outcome) error {
+
+	if o.err != nil {
+		// Flush first so that the diagnostic follows the results before it.
+		if err := t.out.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintln(t.errw, o.diagnostic())
+		if !t.placeholders {
+			return nil
+		}
+	} else {
+		t.out.WriteString(formatResult(o.result))
+	}
+
+	return t.out.WriteByte(t.delim)
+}
+
+func (t *textWriter) flush() error {
+
+	return t.out.Flush()
+}
+
+// plain reports whether a result is a single value, rather than a record such
+// as a ship description.
+func plain(result interface{}) bool {
+
+	switch result.(type) {
+	case string, bool, *big.Int:
+		return true
+	default:
+		return false
+	}
+}
+
+// jsonResult returns the value to marshal for a result in JSON.
+func jsonResult(result interface{}) interface{} {
+
+	if _, ok := result.(bool); ok || !plain(result) {
+		return result
+	}
+
+	// Points are written as strings, since they may not fit in the doubles
+	// that many JSON consumers use for numbers.
+	return formatResult(result)
+}
+
+// jsonRecord is the JSON form of an outcome.
+type jsonRecord struct {
+	Input  string      `json:"input"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+type jsonWriter struct {
+	out   *bufio.Writer
+	delim byte
+}
+
+func (j *jsonWriter) write(o outcome) error {
+
+	rec := jsonRecord{Input: o.input}
+	if o.err != nil {
+		rec.Error = o.err.Error()
+	} else {
+		rec.Result = jsonResult(o.result)
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	j.out.Write(data)
+
+	return j.out.WriteByte(j.delim)
+}
+
+func (j *jsonWriter) flush() error {
+
+	return j.out.Flush()
+}
+
+type csvWriter struct {
+	out    *bufio.Writer
+	csv    *csv.Writer
+	header bool
+}
+
+func (c *csvWriter) write(o outcome) error {
+
+	if !c.header {
+		c.header = true
+		if err := c.csv.Write([]string{"input", "result", "error"}); err != nil {
+			return err
+		}
+	}
+
+	rec := []string{o.input, "", ""}
+	if o.err != nil {
+		rec[2] = o.err.Error()
+	} else if !plain(o.result) {
+		// Records are held in a single column as JSON.
+		data, err := json.Marshal(o.result)
+		if err != nil {
+			return err
+		}
+		rec[1] = string(data)
+	} else {
+		rec[1] = formatResult(o.result)
+	}
+
+	return c.csv.Write(rec)
+}
+
+func (c *csvWriter) flush() error {
+
+	c.csv.Flush()
+	if err := c.csv.Error(); err != nil {
+		return err
+	}
+
+	return c.out.Flush()
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestRecordWriter(t *testing.T) {
+
+	outcomes := []outcome{
+		{source: "in", line: 1, input: "0", result: "~zod"},
+		{source: "in", line: 2, input: "x", err: errors.New(`invalid integer string: "x"`)},
+		{source: "in", line: 3, input: "~zod", result: big.NewInt(0)},
+		{source: "in", line: 4, input: "~zod ~zod", result: true},
+	}
+
+	tests := []struct {
+		format string
+		output string
+		errs   string
+	}{
+		{outputText, "~zod\n\n0\ntrue\n", "in:2: invalid integer string: \"x\"\n"},
+		{outputJSON, `{"input":"0","result":"~zod"}` + "\n" +
+			`{"input":"x","error":"invalid integer string: \"x\""}` + "\n" +
+			`{"input":"~zod","result":"0"}` + "\n" +
+			`{"input":"~zod ~zod","result":true}` + "\n", ""},
+		{outputCSV, "input,result,error\n0,~zod,\nx,,\"invalid integer string: \"\"x\"\"\"\n~zod,0,\n~zod ~zod,true,\n", ""},
+		{outputTSV, "input\tresult\terror\n0\t~zod\t\nx\t\t\"invalid integer string: \"\"x\"\"\"\n~zod\t0\t\n~zod ~zod\ttrue\t\n", ""},
+	}
+
+	for _, tt := range tests {
+		var out, errs bytes.Buffer
+		w, err := newRecordWriter(tt.format, &out, &errs, '\n', true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, o := range outcomes {
+			if err := w.write(o); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		if out.String() != tt.output {
+			t.Errorf("%s: output\n%s\nwant\n%s", tt.format, out.String(), tt.output)
+		}
+		if errs.String() != tt.errs {
+			t.Errorf("%s: errors %q, want %q", tt.format, errs.String(), tt.errs)
+		}
+	}
+
+	if _, err := newRecordWriter("xml", nil, nil, '\n', false); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/deelawn/urbit-gob/qr"
+)
+
+const (
+	cmdQR string = "qr"
+
+	// QR formats
+	qrText string = "text"
+	qrPNG  string = "png"
+	qrSVG  string = "svg"
+
+	defaultQRScale int = 8
+
+	errInvalidQRStr string = "invalid QR format: %s (want text, png or svg)"
+)
+
+// qrOptions choose how a QR code is rendered.
+type qrOptions struct {
+	format string
+	scale  int
+	quiet  int
+	invert bool
+}
+
+// qrArgs are the flags of qr.
+type qrArgs struct {
+	opts       qrOptions
+	level, out string
+}
+
+func (a *qrArgs) flags(g *globals) *flag.FlagSet {
+
+	fs := flag.NewFlagSet(cmdQR, flag.ContinueOnError)
+	fs.StringVar(&a.level, "level", qr.M.String(), "error correction `level`: L, M, Q or H, recovering 7%, 15%, 25% or 30% of the code")
+	fs.StringVar(&a.opts.format, "format", "", "output `format`: text, png or svg (default from the extension of --out, else text)")
+	fs.StringVar(&a.out, "out", "", "write to `path` instead of stdout")
+	fs.IntVar(&a.opts.scale, "scale", defaultQRScale, "pixels per module, for png and svg")
+	fs.IntVar(&a.opts.quiet, "quiet", qr.QuietZone, "width in modules of the light border")
+	fs.BoolVar(&a.opts.invert, "invert", false, "draw text for a light terminal background, with blocks for dark modules")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [flags] [COMMAND] VALUE...\n\n", os.Args[0], cmdQR)
+		fmt.Fprintf(fs.Output(), "Prints a QR code of the result of COMMAND for its values, such as\n")
+		fmt.Fprintf(fs.Output(), "\"%s patq 65536\", or without a command of the values themselves.\n\n", cmdQR)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+func runQR(g *globals, args []string) int {
+
+	var a qrArgs
+	fs := a.flags(g)
+
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return codeInsufficientArguments
+	}
+	if len(rest) == 0 || a.opts.scale < 1 || a.opts.quiet < 0 {
+		fs.Usage()
+		return codeInsufficientArguments
+	}
+
+	l, err := qr.ParseLevel(a.level)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeInsufficientArguments
+	}
+
+	if a.opts.format == "" {
+		a.opts.format = qrText
+		if ext := strings.ToLower(filepath.Ext(a.out)); ext == ".png" || ext == ".svg" {
+			a.opts.format = ext[1:]
+		}
+	}
+	switch a.opts.format {
+	case qrText, qrPNG, qrSVG:
+	default:
+		fmt.Fprintf(os.Stderr, errInvalidQRStr+"\n", a.opts.format)
+		return codeInsufficientArguments
+	}
+
+	text, err := qrContent(rest)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeErrorReturned
+	}
+
+	c, err := qr.Encode(text, l)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeErrorReturned
+	}
+
+	if a.out == "" {
+		err = writeQR(os.Stdout, c, a.opts)
+	} else {
+		var f *os.File
+		if f, err = os.Create(a.out); err == nil {
+			err = writeQR(f, c, a.opts)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeErrorReturned
+	}
+
+	return 0
+}
+
+// qrContent returns the text to encode for args: the result of the command
+// they name, or the values themselves joined by spaces.
+func qrContent(args []string) (string, error) {
+
+	cmd, ok := lookupCommand(args[0])
+	if !ok {
+		return strings.Join(args, " "), nil
+	}
+	if len(args)-1 != cmd.nargs {
+		return "", fmt.Errorf("%s takes %d value(s), got %d", cmd.name, cmd.nargs, len(args)-1)
+	}
+
+	result, err := cmd.apply(args[1:])
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(formatResult(result), "\n"), nil
+}
+
+func writeQR(w io.Writer, c *qr.Code, opts qrOptions) error {
+
+	switch opts.format {
+	case qrText:
+		_, err := io.WriteString(w, c.Text(opts.quiet, opts.invert))
+		return err
+	case qrPNG:
+		return c.PNG(w, opts.scale, opts.quiet)
+	case qrSVG:
+		return c.SVG(w, opts.scale, opts.quiet)
+	default:
+		return fmt.Errorf(errInvalidQRStr, opts.format)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/deelawn/urbit-gob/qr"
+)
+
+func TestQRContent(t *testing.T) {
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"~sampel-palnet"}, "~sampel-palnet"},
+		{[]string{"patq", "65536"}, "~doznec-dozzod"},
+		{[]string{"point2patp", "0x1.0000"}, "~dapnep-ronmyl"},
+		{[]string{"eqpatq", "~zod", "~zod"}, "true"},
+		{[]string{"hello", "world"}, "hello world"},
+	}
+	for _, tt := range tests {
+		got, err := qrContent(tt.args)
+		if err != nil {
+			t.Errorf("%v: %v", tt.args, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%v: got %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	info, err := qrContent([]string{"info", "~zod"})
+	if err != nil || strings.HasSuffix(info, "\n") || !strings.Contains(info, "class     galaxy") {
+		t.Errorf("info: got %q, %v", info, err)
+	}
+
+	for _, args := range [][]string{{"patp", "1", "2"}, {"patp"}, {"patp", "x"}} {
+		if _, err := qrContent(args); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
+
+func TestWriteQR(t *testing.T) {
+
+	c, err := qr.Encode("~sampel-palnet", qr.M)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	if err := writeQR(&b, c, qrOptions{format: qrText, quiet: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != c.Text(2, false) {
+		t.Errorf("text: got %q", b.String())
+	}
+
+	b.Reset()
+	if err := writeQR(&b, c, qrOptions{format: qrPNG, scale: 2, quiet: 4}); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := (c.Size + 8) * 2; img.Bounds().Dx() != n {
+		t.Errorf("png: got width %d, want %d", img.Bounds().Dx(), n)
+	}
+
+	b.Reset()
+	if err := writeQR(&b, c, qrOptions{format: qrSVG, scale: 1, quiet: 4}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(b.String(), "<svg ") {
+		t.Errorf("svg: got %q", b.String())
+	}
+
+	if err := writeQR(&b, c, qrOptions{format: "gif"}); err == nil {
+		t.Error("expected an error for gif")
+	}
+}
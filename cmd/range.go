@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/deelawn/urbit-gob/co"
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+const (
+	cmdRange string = "range"
+
+	defaultRangeColumns string = "point,patp,class,sponsor"
+
+	// rangeBlock is the number of points converted at a time.
+	rangeBlock int = 4096
+)
+
+// pointRange is the points lo, lo+step, lo+2*step... up to hi.
+type pointRange struct {
+	lo, hi, step *big.Int
+}
+
+// rangeColumn is a column that range can write for each point.
+type rangeColumn struct {
+	name  string
+	value func(p *big.Int) (string, error)
+}
+
+var rangeColumns = []rangeColumn{
+	{"point", func(p *big.Int) (string, error) { return p.String(), nil }},
+	{"hex", func(p *big.Int) (string, error) { return "0x" + p.Text(16), nil }},
+	{"patp", func(p *big.Int) (string, error) { return co.Patp(p) }},
+	{"patq", func(p *big.Int) (string, error) { return co.Patq(p) }},
+	{"class", co.ClanPoint},
+	{"sponsor", func(p *big.Int) (string, error) {
+		s, err := co.SeinPoint(p)
+		if err != nil {
+			return "", err
+		}
+		return co.Patp(s)
+	}},
+}
+
+// allRanges are the classes of ship that can be listed with "all". Each holds
+// only ships of its class, so the stars leave out the galaxies below them.
+var allRanges = map[string]pointRange{
+	"galaxies": {big.NewInt(0), big.NewInt(0xff), big.NewInt(1)},
+	"stars":    {big.NewInt(0x100), big.NewInt(0xffff), big.NewInt(1)},
+	"planets":  {big.NewInt(0x10000), big.NewInt(0xffffffff), big.NewInt(1)},
+	"moons":    {big.NewInt(0).Lsh(big.NewInt(1), 32), big.NewInt(0).SetUint64(1<<64 - 1), big.NewInt(1)},
+}
+
+// rangeArgs are the flags of range, besides --output, which sets the global.
+type rangeArgs struct {
+	columns string
+}
+
+func (a *rangeArgs) flags(g *globals) *flag.FlagSet {
+
+	fs := flag.NewFlagSet(cmdRange, flag.ContinueOnError)
+	fs.StringVar(&a.columns, "columns", defaultRangeColumns, "comma-separated `list` of columns: point, hex, patp, patq, class and sponsor")
+	fs.StringVar(&g.output, "output", g.output, "output `format`: text, json, csv or tsv")
+	fs.StringVar(&g.output, "o", g.output, "shorthand for --output")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [flags] LO HI | LO..HI | all galaxies|stars|planets|moons | children of SHIP\n\n", os.Args[0], cmdRange)
+		fmt.Fprintf(fs.Output(), "Lists every ship in a range of points, one per line. LO, HI and SHIP are\n")
+		fmt.Fprintf(fs.Output(), "each a @p or a point. Each class in \"all\" holds only ships of that class, so\n")
+		fmt.Fprintf(fs.Output(), "all stars lists the 65,280 stars, 0x100 to 0xffff, without the 256 galaxies.\n\n")
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+func runRange(g *globals, args []string) int {
+
+	var a rangeArgs
+	fs := a.flags(g)
+
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return codeInsufficientArguments
+	}
+
+	r, err := parseRange(rest)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fs.Usage()
+		return codeInsufficientArguments
+	}
+
+	cols, err := parseColumns(a.columns)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeInsufficientArguments
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	w, err := newRowWriter(g.output, out, cols)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeInsufficientArguments
+	}
+
+	if err := listRange(context.Background(), r, cols, w, g.workers); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeErrorReturned
+	}
+	if err := out.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeErrorReturned
+	}
+
+	return 0
+}
+
+// parseRange parses the args of range.
+func parseRange(args []string) (pointRange, error) {
+
+	switch {
+	case len(args) == 2 && args[0] == "all":
+		r, ok := allRanges[args[1]]
+		if !ok {
+			return pointRange{}, fmt.Errorf("cannot list all %s: want galaxies, stars, planets or moons", args[1])
+		}
+		return r, nil
+
+	case len(args) == 3 && args[0] == "children" && args[1] == "of":
+		return children(args[2])
+
+	case len(args) == 1 && strings.Contains(args[0], ".."):
+		i := strings.Index(args[0], "..")
+		return between(args[0][:i], args[0][i+2:])
+
+	case len(args) == 2:
+		return between(args[0], args[1])
+
+	default:
+		return pointRange{}, fmt.Errorf("invalid range: %s", strings.Join(args, " "))
+	}
+}
+
+func between(lo, hi string) (pointRange, error) {
+
+	r := pointRange{step: big.NewInt(1)}
+
+	var err error
+	if r.lo, err = shipPoint(lo); err != nil {
+		return pointRange{}, err
+	}
+	if r.hi, err = shipPoint(hi); err != nil {
+		return pointRange{}, err
+	}
+	if r.lo.Cmp(r.hi) > 0 {
+		return pointRange{}, fmt.Errorf("invalid range: %s is after %s", lo, hi)
+	}
+
+	return r, nil
+}
+
+// children returns the range of the ships sponsored by ship.
+func children(ship string) (pointRange, error) {
+
+	p, err := shipPoint(ship)
+	if err != nil {
+		return pointRange{}, err
+	}
+
+	s, err := co.DescribePoint(p)
+	if err != nil {
+		return pointRange{}, err
+	}
+	if s.Children == 0 {
+		return pointRange{}, fmt.Errorf("%s is a %s and sponsors no ships", s.Patp, s.Class)
+	}
+
+	r := pointRange{}
+	if r.lo, err = co.ChildPoint(p, 0); err != nil {
+		return pointRange{}, err
+	}
+	if r.hi, err = co.ChildPoint(p, s.Children-1); err != nil {
+		return pointRange{}, err
+	}
+	second, err := co.ChildPoint(p, 1)
+	if err != nil {
+		return pointRange{}, err
+	}
+	r.step = second.Sub(second, r.lo)
+
+	return r, nil
+}
+
+func parseColumns(list string) ([]rangeColumn, error) {
+
+	var cols []rangeColumn
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, c := range rangeColumns {
+			if c.name == name {
+				cols = append(cols, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column: %q", name)
+		}
+	}
+
+	return cols, nil
+}
+
+// listRange writes a row of cols for every point in r, converting a block of
+// points at a time with up to workers goroutines.
+func listRange(ctx context.Context, r pointRange, cols []rangeColumn, w rowWriter, workers int) error {
+
+	points := make([]*big.Int, rangeBlock)
+	for i := range points {
+		points[i] = big.NewInt(0)
+	}
+	rows := make([][]string, rangeBlock)
+	for i := range rows {
+		rows[i] = make([]string, len(cols))
+	}
+
+	next := big.NewInt(0).Set(r.lo)
+	for next.Cmp(r.hi) <= 0 {
+		n := 0
+		for ; n < rangeBlock && next.Cmp(r.hi) <= 0; n++ {
+			points[n].Set(next)
+			next.Add(next, r.step)
+		}
+
+		errs, err := ugi.ForEach(ctx, n, workers, func(i int) error {
+			for j, c := range cols {
+				v, err := c.value(points[i])
+				if err != nil {
+					return err
+				}
+				rows[i][j] = v
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, row := range rows[:n] {
+			if err := w.write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	return w.flush()
+}
+
+// rowWriter writes rows of the columns chosen for range.
+type rowWriter interface {
+	write(row []string) error
+	flush() error
+}
+
+// newRowWriter returns a writer for format. CSV and TSV start with a header
+// naming the columns, text is a line of space-separated values for each row,
+// and JSON is a line holding an object for each row.
+func newRowWriter(format string, w io.Writer, cols []rangeColumn) (rowWriter, error) {
+
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.name
+	}
+
+	switch format {
+	case outputText:
+		return &textRowWriter{w: w}, nil
+	case outputJSON:
+		return &jsonRowWriter{w: w, names: names}, nil
+	case outputCSV, outputTSV:
+		cw := csv.NewWriter(w)
+		if format == outputTSV {
+			cw.Comma = '\t'
+		}
+		return &csvRowWriter{csv: cw, header: names}, nil
+	default:
+		return nil, fmt.Errorf(errInvalidOutputStr, format)
+	}
+}
+
+type textRowWriter struct {
+	w io.Writer
+}
+
+func (t *textRowWriter) write(row []string) error {
+
+	_, err := fmt.Fprintln(t.w, strings.Join(row, " "))
+	return err
+}
+
+func (t *textRowWriter) flush() error {
+
+	return nil
+}
+
+type jsonRowWriter struct {
+	w     io.Writer
+	names []string
+	buf   []byte
+}
+
+func (j *jsonRowWriter) write(row []string) error {
+
+	// The object is built by hand to keep the keys in the order of the
+	// columns.
+	j.buf = append(j.buf[:0], '{')
+	for i, v := range row {
+		if i > 0 {
+			j.buf = append(j.buf, ',')
+		}
+		key, _ := json.Marshal(j.names[i])
+		val, _ := json.Marshal(v)
+		j.buf = append(append(append(j.buf, key...), ':'), val...)
+	}
+	j.buf = append(j.buf, '}', '\n')
+
+	_, err := j.w.Write(j.buf)
+	return err
+}
+
+func (j *jsonRowWriter) flush() error {
+
+	return nil
+}
+
+type csvRowWriter struct {
+	csv    *csv.Writer
+	header []string
+}
+
+func (c *csvRowWriter) write(row []string) error {
+
+	if c.header != nil {
+		if err := c.csv.Write(c.header); err != nil {
+			return err
+		}
+		c.header = nil
+	}
+
+	return c.csv.Write(row)
+}
+
+func (c *csvRowWriter) flush() error {
+
+	c.csv.Flush()
+
+	return c.csv.Error()
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRange(t *testing.T) {
+
+	tests := []struct {
+		args    []string
+		columns string
+		format  string
+		want    string
+	}{
+		{[]string{"0", "2"}, defaultRangeColumns, outputCSV, "" +
+			"point,patp,class,sponsor\n" +
+			"0,~zod,galaxy,~zod\n" +
+			"1,~nec,galaxy,~nec\n" +
+			"2,~bud,galaxy,~bud\n"},
+		{[]string{"~fipfes..0x1.0001"}, "patp,hex,class", outputTSV, "" +
+			"patp\thex\tclass\n" +
+			"~fipfes\t0xffff\tstar\n" +
+			"~dapnep-ronmyl\t0x10000\tplanet\n" +
+			"~milrys-soglec\t0x10001\tplanet\n"},
+		{[]string{"children", "of", "~marzod"}, "patp,sponsor", outputJSON, ""},
+		{[]string{"65535", "65536"}, "point,patq,sponsor", outputText, "" +
+			"65535 ~fipfes ~fes\n" +
+			"65536 ~doznec-dozzod ~zod\n"},
+	}
+
+	for _, tt := range tests {
+		r, err := parseRange(tt.args)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.args, err)
+		}
+		cols, err := parseColumns(tt.columns)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b bytes.Buffer
+		w, err := newRowWriter(tt.format, &b, cols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := listRange(context.Background(), r, cols, w, 2); err != nil {
+			t.Fatalf("%v: %v", tt.args, err)
+		}
+
+		got := b.String()
+		if tt.want == "" {
+			// Only the first rows of long listings are checked.
+			got = strings.Join(strings.SplitAfter(got, "\n")[:2], "")
+			tt.want = "" +
+				`{"patp":"~wicdev-wisryt","sponsor":"~marzod"}` + "\n" +
+				`{"patp":"~panret-tocsel","sponsor":"~marzod"}` + "\n"
+		}
+		if got != tt.want {
+			t.Errorf("%v:\ngot\n%s\nwant\n%s", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestRangeAllStars(t *testing.T) {
+
+	r, err := parseRange([]string{"all", "stars"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cols, _ := parseColumns(defaultRangeColumns)
+
+	var b bytes.Buffer
+	w, _ := newRowWriter(outputCSV, &b, cols)
+	if err := listRange(context.Background(), r, cols, w, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// The 256 galaxies below the first star are not stars, so they are left
+	// out of the 65,536 points up to 0xffff.
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 1+0xff00 {
+		t.Fatalf("listed %d stars, want %d", len(lines)-1, 0xff00)
+	}
+	if lines[1] != "256,~marzod,star,~zod" || lines[len(lines)-1] != "65535,~fipfes,star,~fes" {
+		t.Errorf("stars run from %s to %s", lines[1], lines[len(lines)-1])
+	}
+}
+
+func TestParseRangeErrors(t *testing.T) {
+
+	tests := [][]string{
+		nil,
+		{"all", "comets"},
+		{"children", "of", "~doznec-sampel-palnet"},
+		{"children", "of", "~zodd"},
+		{"5", "3"},
+		{"5..x"},
+		{"1", "2", "3"},
+	}
+
+	for _, args := range tests {
+		if _, err := parseRange(args); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+
+	if _, err := parseColumns("patp,rank"); err == nil {
+		t.Error("expected an error for an unknown column")
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/deelawn/urbit-gob/co"
+)
+
+const (
+	replPrompt string = "urbit> "
+
+	// The history file is $URBIT_GOB_HISTORY, or historyFile in the home
+	// directory. Only the last maxHistory lines are loaded.
+	historyEnv  string = "URBIT_GOB_HISTORY"
+	historyFile string = ".urbit-gob_history"
+	maxHistory  int    = 1000
+
+	// REPL commands
+	replJSON string = ":json"
+	replHelp string = ":help"
+	replQuit string = ":quit"
+)
+
+// repl runs commands typed interactively.
+type repl struct {
+	out    io.Writer
+	output string
+}
+
+func runREPL(g *globals, args []string) int {
+
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "%s takes no args\n", cmdREPL)
+		return codeInsufficientArguments
+	}
+
+	r := &repl{out: os.Stdout, output: g.output}
+	if _, err := newRecordWriter(r.output, nil, nil, '\n', false); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeInsufficientArguments
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !isTerminal(fd) {
+		r.runScript(os.Stdin)
+		return 0
+	}
+
+	restore, err := makeRaw(fd)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeErrorReturned
+	}
+	defer restore()
+
+	path := historyPath()
+	e := newLineEditor(os.Stdin, os.Stdout, replPrompt)
+	e.history = loadHistory(path)
+	e.complete = completeREPL
+
+	fmt.Fprintf(r.out, "Type a command and its args, %s for help or Ctrl-D to quit.\n", replHelp)
+
+	for {
+		line, err := e.readLine()
+		if err == errInterrupted {
+			continue
+		}
+		if err != nil {
+			return 0
+		}
+
+		e.addHistory(line)
+		if path != "" && strings.TrimSpace(line) != "" {
+			if err := appendHistory(path, line); err != nil {
+				fmt.Fprintf(r.out, "cannot save history: %v\n", err)
+				path = ""
+			}
+		}
+
+		if r.eval(line) {
+			return 0
+		}
+	}
+}
+
+// runScript runs commands read line by line, as when input is piped in.
+func (r *repl) runScript(in io.Reader) {
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if r.eval(scanner.Text()) {
+			return
+		}
+	}
+}
+
+// eval runs a line of input and reports whether the REPL should exit.
+func (r *repl) eval(line string) bool {
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false
+	}
+
+	switch fields[0] {
+	case replQuit, ":q":
+		return true
+	case replHelp:
+		r.help()
+		return false
+	case replJSON:
+		if r.output == outputJSON {
+			r.output = outputText
+		} else {
+			r.output = outputJSON
+		}
+		fmt.Fprintf(r.out, "output: %s\n", r.output)
+		return false
+	}
+
+	cmd, ok := lookupCommand(fields[0])
+	if !ok {
+		fmt.Fprintf(r.out, "unknown command: %s (try %s)\n", fields[0], replHelp)
+		return false
+	}
+
+	args := fields[1:]
+	if len(args) == 0 || len(args)%cmd.nargs != 0 {
+		fmt.Fprintf(r.out, "%s takes %d value(s) per input\n", cmd.name, cmd.nargs)
+		return false
+	}
+
+	out, _ := newRecordWriter(r.output, r.out, r.out, '\n', false)
+	if _, err := applyArgs(cmd, args, out, true); err != nil {
+		fmt.Fprintln(r.out, err)
+	}
+
+	return false
+}
+
+func (r *repl) help() {
+
+	fmt.Fprintf(r.out, "Commands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(r.out, usageCmdFmtStr, cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(r.out, usageCmdFmtStr, replJSON, "switches between text and JSON output")
+	fmt.Fprintf(r.out, usageCmdFmtStr, replHelp, "shows this help")
+	fmt.Fprintf(r.out, usageCmdFmtStr, replQuit, "quits (as does Ctrl-D)")
+	fmt.Fprintf(r.out, "\nTab completes command names and the syllables of @p and @q values.\n")
+}
+
+// completeREPL completes command names at the start of a line, and syllables
+// of the @p or @q being typed anywhere else.
+func completeREPL(before string) (int, []string) {
+
+	start := strings.LastIndexAny(before, " \t") + 1
+	word := before[start:]
+
+	if strings.TrimSpace(before[:start]) == "" {
+		var candidates []string
+		for _, cmd := range commands {
+			if strings.HasPrefix(cmd.name, word) {
+				candidates = append(candidates, cmd.name+" ")
+			}
+		}
+		for _, meta := range []string{replJSON, replHelp, replQuit} {
+			if strings.HasPrefix(meta, word) {
+				candidates = append(candidates, meta+" ")
+			}
+		}
+		return start, candidates
+	}
+
+	segStart, candidates := completeSyllables(word)
+
+	return start + segStart, candidates
+}
+
+// completeSyllables completes the last word of a @p or @q, returning the
+// offset of that word and the candidates for it. The first word of a name may
+// be a lone suffix, as in a galaxy's name; any other word is a prefix followed
+// by a suffix.
+func completeSyllables(name string) (int, []string) {
+
+	if !strings.Contains(name, "~") {
+		return 0, nil
+	}
+
+	start := strings.LastIndexAny(name, "~-") + 1
+	seg := name[start:]
+	first := name[start-1] == '~'
+
+	var candidates []string
+	switch {
+	case len(seg) < 3:
+		for _, pre := range co.Prefixes {
+			if strings.HasPrefix(pre, seg) {
+				candidates = append(candidates, pre)
+			}
+		}
+		if first {
+			for _, suf := range co.Suffixes {
+				if strings.HasPrefix(suf, seg) {
+					candidates = append(candidates, suf)
+				}
+			}
+		}
+	case len(seg) < 6:
+		pre, rest := seg[:3], seg[3:]
+		if !isPrefix(pre) {
+			return start, nil
+		}
+		for _, suf := range co.Suffixes {
+			if strings.HasPrefix(suf, rest) {
+				candidates = append(candidates, pre+suf)
+			}
+		}
+	}
+
+	return start, candidates
+}
+
+func isPrefix(syl string) bool {
+
+	for _, pre := range co.Prefixes {
+		if pre == syl {
+			return true
+		}
+	}
+
+	return false
+}
+
+func historyPath() string {
+
+	if path := os.Getenv(historyEnv); path != "" {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, historyFile)
+}
+
+// loadHistory returns the last maxHistory lines of the history file, or
+// nothing if it cannot be read.
+func loadHistory(path string) []string {
+
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > 2*maxHistory {
+			lines = append(lines[:0], lines[len(lines)-maxHistory:]...)
+		}
+	}
+	if len(lines) > maxHistory {
+		lines = lines[len(lines)-maxHistory:]
+	}
+
+	return lines
+}
+
+func appendHistory(path, line string) error {
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/deelawn/urbit-gob/co"
+)
+
+const (
+	cmdSay string = "say"
+
+	// Say formats
+	sayRespelled string = "say"
+	sayIPA       string = "ipa"
+	saySpelled   string = "spell"
+	sayAll       string = "all"
+
+	errInvalidSayStr string = "invalid say format: %s (want say, ipa, spell or all)"
+)
+
+// sayArgs are the flags of say.
+type sayArgs struct {
+	format    string
+	syllables bool
+}
+
+func (a *sayArgs) flags(g *globals) *flag.FlagSet {
+
+	fs := flag.NewFlagSet(cmdSay, flag.ContinueOnError)
+	fs.StringVar(&a.format, "format", sayRespelled, "output `format`: say (respelled for English), ipa, spell (spelling alphabet) or all")
+	fs.BoolVar(&a.syllables, "syllables", false, "list the pronunciation of every prefix and suffix")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [flags] NAME...\n\n", os.Args[0], cmdSay)
+		fmt.Fprintf(fs.Output(), "Prints how to say each NAME, a @p, @q or point, aloud: respelled for\n")
+		fmt.Fprintf(fs.Output(), "English, in IPA or spelled out letter by letter. A @q may be written\n")
+		fmt.Fprintf(fs.Output(), "as .~name to tell it from a @p.\n\n")
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+func runSay(g *globals, args []string) int {
+
+	var a sayArgs
+	fs := a.flags(g)
+
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return codeInsufficientArguments
+	}
+	if a.syllables {
+		if len(names) > 0 {
+			fs.Usage()
+			return codeInsufficientArguments
+		}
+		writeSyllables(os.Stdout)
+		return 0
+	}
+	if len(names) == 0 {
+		fs.Usage()
+		return codeInsufficientArguments
+	}
+
+	switch a.format {
+	case sayRespelled, sayIPA, saySpelled, sayAll:
+	default:
+		fmt.Fprintf(os.Stderr, errInvalidSayStr+"\n", a.format)
+		return codeInsufficientArguments
+	}
+
+	code := 0
+	for _, s := range names {
+		out, err := sayName(s, a.format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", s, err)
+			code = codeErrorReturned
+			continue
+		}
+		fmt.Println(out)
+	}
+
+	return code
+}
+
+// sayName renders a @p, @q or point in format. Points are said as their @p,
+// and a @q may be written with Hoon's .~ prefix. With sayAll it gives a table
+// of every form.
+func sayName(s, format string) (string, error) {
+
+	name := strings.TrimSpace(s)
+	label := name
+	switch {
+	case strings.HasPrefix(name, ".~"):
+		name = name[1:]
+		if !co.IsValidPatq(name) {
+			return "", fmt.Errorf("invalid @q: %s", name)
+		}
+	case !strings.HasPrefix(name, "~"):
+		var err error
+		if name, err = shipName(name); err != nil {
+			return "", err
+		}
+		label = name
+	}
+
+	switch format {
+	case sayIPA:
+		return co.SayIPA(name)
+	case saySpelled:
+		return co.Spell(name)
+	case sayAll:
+		said, err := co.Say(name)
+		if err != nil {
+			return "", err
+		}
+		ipa, _ := co.SayIPA(name)
+		spelled, _ := co.Spell(name)
+		return formatTable([][2]string{
+			{"name", label},
+			{sayRespelled, said},
+			{sayIPA, ipa},
+			{saySpelled, spelled},
+		}), nil
+	default:
+		return co.Say(name)
+	}
+}
+
+// writeSyllables writes each syllable with its IPA and respelling, one to a
+// line.
+func writeSyllables(w io.Writer) {
+
+	for _, p := range co.Pronunciations() {
+		pad := strings.Repeat(" ", 4-utf8.RuneCountInString(p.IPA))
+		fmt.Fprintf(w, "%s  %s%s  %s\n", p.Syllable, p.IPA, pad, p.Respelling)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSayName(t *testing.T) {
+
+	tests := []struct {
+		input  string
+		format string
+		want   string
+	}{
+		{"~sampel-palnet", sayRespelled, "SAM-pel PAL-net"},
+		{"~sampel-palnet", sayIPA, "/ˈsæm.pɛl ˈpæl.nɛt/"},
+		{"256", saySpelled, "mike-alpha-romeo zulu-oscar-delta"},
+		{"~doznec-dozzod", sayRespelled, "DOZ-nek DOZ-zod"},
+		{" 0 ", sayRespelled, "ZOD"},
+		{".~sampel-palnet", sayRespelled, "SAM-pel PAL-net"},
+		{".~marzod", saySpelled, "mike-alpha-romeo zulu-oscar-delta"},
+		{".~zod", sayAll, "name   .~zod\nsay    ZOD\nipa    /ˈzɑd/\nspell  zulu-oscar-delta\n"},
+		{"~zod", sayAll, "name   ~zod\nsay    ZOD\nipa    /ˈzɑd/\nspell  zulu-oscar-delta\n"},
+	}
+	for _, tt := range tests {
+		got, err := sayName(tt.input, tt.format)
+		if err != nil {
+			t.Errorf("%s %s: %v", tt.input, tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s %s: got %q, want %q", tt.input, tt.format, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"~zodd", "x", "-1", "~sampelpalnet", ".~zodd", ".sampel"} {
+		if _, err := sayName(s, sayRespelled); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestWriteSyllables(t *testing.T) {
+
+	var b strings.Builder
+	writeSyllables(&b)
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 512 {
+		t.Fatalf("got %d lines, want 512", len(lines))
+	}
+	if lines[0] != "doz  dɑz   doz" {
+		t.Errorf("got first line %q", lines[0])
+	}
+	if lines[len(lines)-1] != "fes  fɛs   fes" {
+		t.Errorf("got last line %q", lines[len(lines)-1])
+	}
+}
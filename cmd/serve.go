@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+const (
+	cmdServe string = "serve"
+
+	defaultAddr     string        = "127.0.0.1:8080"
+	defaultMaxBody  int64         = 1 << 20
+	defaultMaxBatch int           = 10000
+	defaultShutdown time.Duration = 10 * time.Second
+
+	// Routes other than those of the commands
+	routeBatch   string = "batch"
+	routeOpenAPI string = "openapi.json"
+
+	// Error codes returned by the API
+	errCodeNotFound         string = "not_found"
+	errCodeMethodNotAllowed string = "method_not_allowed"
+	errCodeBadRequest       string = "bad_request"
+	errCodeTooLarge         string = "too_large"
+	errCodeInvalidInput     string = "invalid_input"
+	errCodeInternal         string = "internal"
+)
+
+// server serves the commands as a JSON API. Each command is at
+// GET /{command}/{value}, or /{command}/{value}/{value} for those taking two
+// values, and POST /batch converts many inputs with one command.
+type server struct {
+	maxBody  int64
+	maxBatch int
+	workers  int
+}
+
+// apiError is the JSON form of an error. Code is one of the errCode
+// constants.
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// apiResult is the JSON form of the conversion of an input.
+type apiResult struct {
+	Input  string      `json:"input"`
+	Result interface{} `json:"result,omitempty"`
+	Error  *apiError   `json:"error,omitempty"`
+}
+
+// batchRequest is the body of POST /batch. Each input is a string, with the
+// values of commands taking several separated by whitespace, or an array of
+// strings.
+type batchRequest struct {
+	Command string            `json:"command"`
+	Inputs  []json.RawMessage `json:"inputs"`
+}
+
+type batchResponse struct {
+	Results []apiResult `json:"results"`
+	Failed  int         `json:"failed"`
+}
+
+// serveArgs are the flags of serve.
+type serveArgs struct {
+	server   server
+	addr     string
+	shutdown time.Duration
+}
+
+func (a *serveArgs) flags(g *globals) *flag.FlagSet {
+
+	a.server.workers = g.workers
+
+	fs := flag.NewFlagSet(cmdServe, flag.ContinueOnError)
+	fs.StringVar(&a.addr, "addr", defaultAddr, "listen on `address`")
+	fs.Int64Var(&a.server.maxBody, "max-body", defaultMaxBody, "largest request body accepted, in `bytes`")
+	fs.IntVar(&a.server.maxBatch, "max-batch", defaultMaxBatch, "most inputs accepted in one batch")
+	fs.DurationVar(&a.shutdown, "shutdown-timeout", defaultShutdown, "how long to let requests in flight finish on SIGINT or SIGTERM")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s [-j N] %s [flags]\n\n", os.Args[0], cmdServe)
+		fmt.Fprintf(fs.Output(), "Serves every command as a JSON API, described at /%s.\n\n", routeOpenAPI)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+func runServe(g *globals, args []string) int {
+
+	var a serveArgs
+	fs := a.flags(g)
+	s := &a.server
+
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return codeInsufficientArguments
+	}
+	if len(rest) > 0 {
+		fs.Usage()
+		return codeInsufficientArguments
+	}
+
+	ln, err := net.Listen("tcp", a.addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeErrorReturned
+	}
+
+	srv := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      time.Minute,
+		MaxHeaderBytes:    1 << 16,
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Serve(ln)
+	}()
+	fmt.Fprintf(os.Stderr, "listening on http://%s\n", ln.Addr())
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	select {
+	case err := <-errc:
+		fmt.Fprintln(os.Stderr, err)
+		return codeErrorReturned
+	case sig := <-sigc:
+		fmt.Fprintf(os.Stderr, "%v: shutting down\n", sig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdown)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeErrorReturned
+	}
+
+	return 0
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	path := strings.Trim(r.URL.Path, "/")
+	switch path {
+	case routeBatch:
+		if !allow(w, r, http.MethodPost) {
+			return
+		}
+		s.batch(w, r)
+		return
+	case routeOpenAPI:
+		if !allow(w, r, http.MethodGet, http.MethodHead) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, openAPISpec)
+		return
+	}
+
+	parts := strings.Split(path, "/")
+	cmd, ok := lookupCommand(parts[0])
+	if !ok {
+		writeError(w, http.StatusNotFound, errCodeNotFound, "no such endpoint: "+r.URL.Path)
+		return
+	}
+	if !allow(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+
+	args := parts[1:]
+	if len(args) != cmd.nargs {
+		writeError(w, http.StatusBadRequest, errCodeBadRequest,
+			fmt.Sprintf("/%s takes %d value(s) in the path, got %d", cmd.name, cmd.nargs, len(args)))
+		return
+	}
+
+	res := applyAPI(cmd, strings.Join(args, " "), args)
+	status := http.StatusOK
+	if res.Error != nil {
+		status = http.StatusBadRequest
+	}
+
+	writeJSON(w, status, res)
+}
+
+// batch handles POST /batch.
+func (s *server) batch(w http.ResponseWriter, r *http.Request) {
+
+	if r.ContentLength > s.maxBody {
+		writeError(w, http.StatusRequestEntityTooLarge, errCodeTooLarge,
+			fmt.Sprintf("request body is %d bytes, at most %d allowed", r.ContentLength, s.maxBody))
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, s.maxBody+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errCodeBadRequest, err.Error())
+		return
+	}
+	if int64(len(body)) > s.maxBody {
+		writeError(w, http.StatusRequestEntityTooLarge, errCodeTooLarge,
+			fmt.Sprintf("request body is over %d bytes", s.maxBody))
+		return
+	}
+
+	var req batchRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, errCodeBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	cmd, ok := lookupCommand(req.Command)
+	if !ok {
+		writeError(w, http.StatusBadRequest, errCodeBadRequest, fmt.Sprintf("unknown command: %q", req.Command))
+		return
+	}
+	if len(req.Inputs) > s.maxBatch {
+		writeError(w, http.StatusRequestEntityTooLarge, errCodeTooLarge,
+			fmt.Sprintf("batch has %d inputs, at most %d allowed", len(req.Inputs), s.maxBatch))
+		return
+	}
+
+	// Check the shape of every input before converting any.
+	inputs := make([]string, len(req.Inputs))
+	args := make([][]string, len(req.Inputs))
+	for i, raw := range req.Inputs {
+		if inputs[i], args[i], err = batchInput(raw, cmd.nargs); err != nil {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest, fmt.Sprintf("inputs[%d]: %v", i, err))
+			return
+		}
+	}
+
+	resp := batchResponse{Results: make([]apiResult, len(inputs))}
+	_, err = ugi.ForEach(r.Context(), len(inputs), s.workers, func(i int) error {
+		resp.Results[i] = applyAPI(cmd, inputs[i], args[i])
+		return nil
+	})
+	if err != nil {
+		// The client has gone away.
+		return
+	}
+
+	for _, res := range resp.Results {
+		if res.Error != nil {
+			resp.Failed++
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// batchInput returns an input of a batch as text and as the args of a
+// command taking nargs values. A string holding several values is split at
+// whitespace, as when streaming.
+func batchInput(raw json.RawMessage, nargs int) (string, []string, error) {
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		args := []string{strings.TrimSpace(text)}
+		if nargs > 1 {
+			args = strings.Fields(text)
+		}
+		return text, args, nil
+	}
+
+	var args []string
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", nil, fmt.Errorf("want a string or an array of %d strings", nargs)
+	}
+	if len(args) != nargs {
+		return "", nil, fmt.Errorf("want %d values, got %d", nargs, len(args))
+	}
+
+	return strings.Join(args, " "), args, nil
+}
+
+// applyAPI converts a single input. An input with the wrong number of values
+// fails like any other invalid input.
+func applyAPI(cmd command, input string, args []string) apiResult {
+
+	res := apiResult{Input: input}
+	if len(args) != cmd.nargs {
+		res.Error = &apiError{errCodeInvalidInput, fmt.Sprintf("expected %d values, got %d", cmd.nargs, len(args))}
+		return res
+	}
+
+	result, err := cmd.apply(args)
+	if err != nil {
+		res.Error = &apiError{errCodeInvalidInput, err.Error()}
+		return res
+	}
+	res.Result = jsonResult(result)
+
+	return res
+}
+
+// allow reports whether the request uses one of methods, and otherwise
+// responds with 405.
+func allow(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, errCodeMethodNotAllowed, r.Method+" is not allowed on "+r.URL.Path)
+
+	return false
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+
+	writeJSON(w, status, struct {
+		Error apiError `json:"error"`
+	}{apiError{code, message}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		data, _ = json.Marshal(struct {
+			Error apiError `json:"error"`
+		}{apiError{errCodeInternal, "cannot encode response: " + err.Error()}})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestServer() *httptest.Server {
+
+	return httptest.NewServer(&server{maxBody: 1024, maxBatch: 4, workers: 2})
+}
+
+func TestServeGet(t *testing.T) {
+
+	ts := newTestServer()
+	defer ts.Close()
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+		want   string
+	}{
+		{"GET", "/patp/0", 200, `{"input":"0","result":"~zod"}`},
+		{"GET", "/point2patp/0x1.0000", 200, `{"input":"0x1.0000","result":"~dapnep-ronmyl"}`},
+		{"GET", "/patp2point/~sampel-palnet/", 200, `{"input":"~sampel-palnet","result":"1624961343"}`},
+		{"GET", "/clan/~marzod", 200, `{"input":"~marzod","result":"star"}`},
+		{"GET", "/isvalidpatp/~zod", 200, `{"input":"~zod","result":true}`},
+		{"GET", "/eqpatq/~dozzod-marzod/~marzod", 200, `{"input":"~dozzod-marzod ~marzod","result":true}`},
+		{"GET", "/point2patp/abc", 400, `{"input":"abc","error":{"code":"invalid_input","message":"ambiguous point \"abc\": hex digits need a 0x prefix"}}`},
+		{"GET", "/patp", 400, `{"error":{"code":"bad_request","message":"/patp takes 1 value(s) in the path, got 0"}}`},
+		{"GET", "/patp/1/2", 400, `{"error":{"code":"bad_request","message":"/patp takes 1 value(s) in the path, got 2"}}`},
+		{"GET", "/nope/1", 404, `{"error":{"code":"not_found","message":"no such endpoint: /nope/1"}}`},
+		{"POST", "/patp/1", 405, `{"error":{"code":"method_not_allowed","message":"POST is not allowed on /patp/1"}}`},
+		{"GET", "/batch", 405, `{"error":{"code":"method_not_allowed","message":"GET is not allowed on /batch"}}`},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, ts.URL+tt.path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body bytes.Buffer
+		_, err = body.ReadFrom(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, resp.StatusCode, tt.status)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("%s %s: Content-Type %q", tt.method, tt.path, ct)
+		}
+		if got := strings.TrimSpace(body.String()); got != tt.want {
+			t.Errorf("%s %s:\ngot  %s\nwant %s", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestServeBatch(t *testing.T) {
+
+	ts := newTestServer()
+	defer ts.Close()
+
+	tests := []struct {
+		body   string
+		status int
+		want   string
+	}{
+		{
+			`{"command":"patp","inputs":["0","65536","x"]}`, 200,
+			`{"results":[{"input":"0","result":"~zod"},{"input":"65536","result":"~dapnep-ronmyl"},{"input":"x","error":{"code":"invalid_input","message":"invalid integer string: x"}}],"failed":1}`,
+		},
+		{
+			`{"command":"eqpatq","inputs":["~zod ~zod",["~zod","~nec"],"~zod"]}`, 200,
+			`{"results":[{"input":"~zod ~zod","result":true},{"input":"~zod ~nec","result":false},{"input":"~zod","error":{"code":"invalid_input","message":"expected 2 values, got 1"}}],"failed":1}`,
+		},
+		{
+			`{"command":"patp","inputs":[]}`, 200,
+			`{"results":[],"failed":0}`,
+		},
+		{
+			`{"command":"eqpatq","inputs":[["~zod"]]}`, 400,
+			`{"error":{"code":"bad_request","message":"inputs[0]: want 2 values, got 1"}}`,
+		},
+		{
+			`{"command":"patp","inputs":[1]}`, 400,
+			`{"error":{"code":"bad_request","message":"inputs[0]: want a string or an array of 1 strings"}}`,
+		},
+		{
+			`{"command":"nope","inputs":[]}`, 400,
+			`{"error":{"code":"bad_request","message":"unknown command: \"nope\""}}`,
+		},
+		{
+			`{"command":`, 400,
+			`{"error":{"code":"bad_request","message":"invalid request body: unexpected end of JSON input"}}`,
+		},
+		{
+			`{"command":"patp","inputs":["1","2","3","4","5"]}`, 413,
+			`{"error":{"code":"too_large","message":"batch has 5 inputs, at most 4 allowed"}}`,
+		},
+		{
+			`{"command":"patp","inputs":["` + strings.Repeat("1", 1024) + `"]}`, 413,
+			`{"error":{"code":"too_large","message":"request body is 1056 bytes, at most 1024 allowed"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		resp, err := http.Post(ts.URL+"/batch", "application/json", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body bytes.Buffer
+		_, err = body.ReadFrom(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.status {
+			t.Errorf("%.40s: status %d, want %d", tt.body, resp.StatusCode, tt.status)
+		}
+		if got := strings.TrimSpace(body.String()); got != tt.want {
+			t.Errorf("%.40s:\ngot  %s\nwant %s", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestServeBatchUnknownLength(t *testing.T) {
+
+	// A body sent without a length is cut off at the limit.
+	body := `{"command":"patp","inputs":["` + strings.Repeat("1", 2048) + `"]}`
+	req := httptest.NewRequest("POST", "/batch", strings.NewReader(body))
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+
+	(&server{maxBody: 1024, maxBatch: 4}).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestOpenAPISpec(t *testing.T) {
+
+	ts := newTestServer()
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var spec struct {
+		Paths map[string]struct {
+			Get struct {
+				Parameters []struct {
+					Name   string
+					Schema struct {
+						Enum []string
+					}
+				}
+			}
+		}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&spec); err != nil {
+		t.Fatal(err)
+	}
+
+	var want []string
+	for _, cmd := range commands {
+		if cmd.nargs == 1 {
+			want = append(want, cmd.name)
+		}
+	}
+
+	got := spec.Paths["/{command}/{value}"].Get.Parameters[0].Schema.Enum
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("the spec lists commands %v, want %v", got, want)
+	}
+	if _, ok := spec.Paths["/"+cmdEqPatq+"/{p}/{q}"]; !ok {
+		t.Errorf("the spec does not describe %s", cmdEqPatq)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/deelawn/urbit-gob/co"
+	"github.com/deelawn/urbit-gob/sigil"
+)
+
+const (
+	cmdSigil string = "sigil"
+
+	// Sigil formats
+	sigilSVG string = "svg"
+	sigilPNG string = "png"
+
+	// envSigilSymbols names a symbols file to use instead of the built-in
+	// symbols.
+	envSigilSymbols string = "URBIT_GOB_SIGIL_SYMBOLS"
+
+	errInvalidSigilStr string = "invalid sigil format: %s (want svg or png)"
+)
+
+// sigilArgs are the flags of sigil.
+type sigilArgs struct {
+	opts                      sigil.Options
+	symbols, format, out, dir string
+}
+
+func (a *sigilArgs) flags(g *globals) *flag.FlagSet {
+
+	a.opts = sigil.DefaultOptions
+
+	fs := flag.NewFlagSet(cmdSigil, flag.ContinueOnError)
+	fs.StringVar(&a.symbols, "symbols", os.Getenv(envSigilSymbols), "JSON file of symbols to use instead of the built-in ones (default $"+envSigilSymbols+")")
+	fs.Float64Var(&a.opts.Size, "size", a.opts.Size, "width and height in pixels")
+	fs.StringVar(&a.opts.Foreground, "fg", a.opts.Foreground, "`colour` of the symbols")
+	fs.StringVar(&a.opts.Background, "bg", a.opts.Background, "`colour` of the background")
+	fs.Float64Var(&a.opts.Margin, "margin", a.opts.Margin, "border around the symbols, as a fraction of the size")
+	fs.StringVar(&a.format, "format", "", "output `format`: svg or png (default from the extension of --out, else svg)")
+	fs.StringVar(&a.out, "out", "", "write the sigil of a single ship to `path` instead of stdout")
+	fs.StringVar(&a.dir, "dir", "", "write the sigil of each ship to a file in `directory`, named after it")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [flags] [SHIP...]\n\n", os.Args[0], cmdSigil)
+		fmt.Fprintf(fs.Output(), "Draws the sigil of each SHIP, a @p or a point, or of each ship read from\n")
+		fmt.Fprintf(fs.Output(), "stdin, one to a line, if none are given, with the symbols of sigil-js or,\n")
+		fmt.Fprintf(fs.Output(), "with --symbols, a file mapping each syllable to its symbol.\n\n")
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+func runSigil(g *globals, args []string) int {
+
+	var a sigilArgs
+	fs := a.flags(g)
+
+	ships, err := parseArgs(fs, args)
+	if err != nil {
+		return codeInsufficientArguments
+	}
+	if a.out != "" && a.dir != "" {
+		fs.Usage()
+		return codeInsufficientArguments
+	}
+
+	if a.format == "" {
+		a.format = sigilSVG
+		if ext := strings.ToLower(filepath.Ext(a.out)); ext == ".png" {
+			a.format = sigilPNG
+		}
+	}
+	if a.format != sigilSVG && a.format != sigilPNG {
+		fmt.Fprintf(os.Stderr, errInvalidSigilStr+"\n", a.format)
+		return codeInsufficientArguments
+	}
+
+	if len(ships) == 0 {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if s := strings.TrimSpace(scanner.Text()); s != "" {
+				ships = append(ships, s)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return codeErrorReturned
+		}
+	}
+	if len(ships) > 1 && a.dir == "" {
+		fmt.Fprintln(os.Stderr, "more than one ship: give --dir to write a file for each")
+		return codeInsufficientArguments
+	}
+
+	set, err := loadSymbols(a.symbols)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeErrorReturned
+	}
+
+	code := 0
+	for _, s := range ships {
+		written, err := writeSigil(set, s, a.opts, a.format, a.out, a.dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", s, err)
+			code = codeErrorReturned
+			continue
+		}
+		if a.dir != "" {
+			fmt.Println(written)
+		}
+	}
+
+	return code
+}
+
+// loadSymbols reads the symbols file at path, or returns the built-in symbols
+// if path is empty.
+func loadSymbols(path string) (*sigil.Set, error) {
+
+	if path == "" {
+		return sigil.Builtin()
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return sigil.LoadSet(f)
+}
+
+// writeSigil writes the sigil of ship to path, or to stdout if path is
+// empty, or to a file named after ship in dir if dir is given. It returns
+// the path written.
+func writeSigil(set *sigil.Set, ship string, opts sigil.Options, format, path, dir string) (string, error) {
+
+	name, err := shipName(ship)
+	if err != nil {
+		return "", err
+	}
+	s, err := co.Describe(name)
+	if err != nil {
+		return "", err
+	}
+
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+		path = filepath.Join(dir, strings.TrimPrefix(name, "~")+"."+format)
+	}
+
+	var w io.Writer = os.Stdout
+	var f *os.File
+	if path != "" {
+		if f, err = os.Create(path); err != nil {
+			return "", err
+		}
+		w = f
+	}
+
+	if format == sigilPNG {
+		err = set.PNG(w, s, opts)
+	} else {
+		err = set.SVG(w, s, opts)
+	}
+	if f != nil {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return path, err
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deelawn/urbit-gob/internal/sigiltest"
+	"github.com/deelawn/urbit-gob/sigil"
+)
+
+func TestWriteSigil(t *testing.T) {
+
+	circle := `{"name": "circle", "attributes": {"cx": "64", "cy": "64", "r": "48", "fill": "@FG"}}`
+	set, err := sigil.LoadSet(strings.NewReader(sigiltest.Symbols(sigiltest.Same(circle))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "sigil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	opts := sigil.DefaultOptions
+	opts.Size = 64
+
+	path, err := writeSigil(set, "~sampel-palnet", opts, sigilSVG, "", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "sampel-palnet.svg"); path != want {
+		t.Errorf("got path %s, want %s", path, want)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "<circle"); n != 4 {
+		t.Errorf("got %d symbols, want 4", n)
+	}
+
+	path, err = writeSigil(set, "256", opts, sigilPNG, "", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "marzod.png" || img.Bounds().Dx() != 64 {
+		t.Errorf("got %s of %v", path, img.Bounds())
+	}
+
+	out := filepath.Join(dir, "zod.svg")
+	if path, err := writeSigil(set, "~zod", opts, sigilSVG, out, ""); err != nil || path != out {
+		t.Errorf("got %s, %v", path, err)
+	}
+
+	if _, err := writeSigil(set, "~zodd", opts, sigilSVG, "", dir); err == nil {
+		t.Error("expected an error for an invalid ship")
+	}
+}
+
+func decodePNG(t *testing.T, path string) image.Image {
+
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+
+	return img
+}
+
+// TestSigilReference checks the PNG sigils drawn with the built-in symbols
+// against those of sigil-js. The reference sigils in testdata/sigils are PNG
+// files rendered from the SVG of sigil-js, white on black and 128 pixels
+// square, each named after its ship, such as sampel-palnet.png.
+func TestSigilReference(t *testing.T) {
+
+	set, err := loadSymbols("")
+	if err != nil {
+		t.Skip(err)
+	}
+	refs, err := filepath.Glob(filepath.Join("testdata", "sigils", "*.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) == 0 {
+		t.Skip("no reference sigils in testdata/sigils")
+	}
+
+	dir, err := ioutil.TempDir("", "sigil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, ref := range refs {
+		ship := "~" + strings.TrimSuffix(filepath.Base(ref), ".png")
+		path, err := writeSigil(set, ship, sigil.DefaultOptions, sigilPNG, "", dir)
+		if err != nil {
+			t.Fatalf("%s: %v", ship, err)
+		}
+
+		got, want := decodePNG(t, path), decodePNG(t, ref)
+		if got.Bounds() != want.Bounds() {
+			t.Errorf("%s: got %v, want %v", ship, got.Bounds(), want.Bounds())
+			continue
+		}
+
+		// Rasterizers differ in their anti-aliasing, so the colours are
+		// compared on average.
+		var diff float64
+		r := got.Bounds()
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				p := color.RGBAModel.Convert(got.At(x, y)).(color.RGBA)
+				q := color.RGBAModel.Convert(want.At(x, y)).(color.RGBA)
+				diff += math.Abs(float64(p.R)-float64(q.R)) + math.Abs(float64(p.G)-float64(q.G)) + math.Abs(float64(p.B)-float64(q.B))
+			}
+		}
+		if diff /= float64(3 * 255 * r.Dx() * r.Dy()); diff > 0.02 {
+			t.Errorf("%s: differs from the sigil-js sigil by %.4f", ship, diff)
+		}
+	}
+}
@@ -1,31 +1,35 @@
 package main
 
 import (
+	"context"
 	"crypto/rand"
+	"encoding/binary"
 	"flag"
 	"fmt"
 	"log"
-	"math/big"
 	"os"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
-	"time"
 
-	"github.com/deelawn/urbit-gob/ob"
+	"github.com/deelawn/urbit-gob/ob/verify"
 )
 
 func main() {
 	var numChecks int
 	var workers int
 	var verbose bool
-	
+	var rangeArg string
+
 	flag.IntVar(&numChecks, "n", 10000, "Number of spot checks to perform")
 	flag.IntVar(&numChecks, "num-checks", 10000, "Number of spot checks to perform (alias)")
 	flag.IntVar(&workers, "workers", runtime.NumCPU(), "Number of parallel workers")
 	flag.BoolVar(&verbose, "v", false, "Verbose output")
 	flag.BoolVar(&verbose, "verbose", false, "Verbose output (alias)")
-	
+	flag.StringVar(&rangeArg, "range", "", "Exhaustively check the range `lo:hi` instead of sampling (\"all\" for the full 32-bit domain)")
+
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [flags]\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\nSpot-check the bijectivity of the Urbit @p scrambler\n\n")
@@ -34,158 +38,180 @@ func main() {
 		fmt.Fprintf(os.Stderr, "\nExample:\n")
 		fmt.Fprintf(os.Stderr, "  %s -n 100000 -v\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s --num-checks 1000000 --workers 8\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --range 0x10000:0x1000000\n", os.Args[0])
 	}
-	
+
 	flag.Parse()
-	
-	if numChecks <= 0 {
-		log.Fatal("Number of checks must be positive")
+
+	var report verify.Report
+	if rangeArg != "" {
+		lo, hi, err := parseRange(rangeArg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		report = checkRange(lo, hi, workers, verbose)
+	} else {
+		if numChecks <= 0 {
+			log.Fatal("Number of checks must be positive")
+		}
+		report = spotCheck(numChecks, workers, verbose)
+	}
+
+	// Print results
+	fmt.Printf("\n=== Spot Check Results ===\n")
+	fmt.Printf("Total values tested: %d\n", report.Checked)
+	fmt.Printf("Duplicate samples skipped: %d\n", report.Duplicates)
+	fmt.Printf("Distinct outputs: %d (coverage %.6f)\n", report.Distinct, report.Coverage)
+	fmt.Printf("Time elapsed: %v\n", report.Elapsed)
+	fmt.Printf("Values per second: %.0f\n", float64(report.Checked)/report.Elapsed.Seconds())
+	fmt.Printf("Collisions found: %d\n", report.NumCollisions)
+	fmt.Printf("Round-trip failures found: %d\n", report.NumRoundTripFailures)
+
+	var errors []fmt.Stringer
+	for _, c := range report.Collisions {
+		errors = append(errors, c)
+	}
+	for _, f := range report.RoundTripFailures {
+		errors = append(errors, f)
+	}
+
+	if !report.OK() {
+		fmt.Printf("\n!!! ERRORS FOUND: %d !!!\n", report.NumCollisions+report.NumRoundTripFailures)
+		for i, err := range errors {
+			fmt.Printf("Error %d: %v\n", i+1, err)
+			if i >= 10 && len(errors) > 11 {
+				fmt.Printf("... and %d more errors\n", report.NumCollisions+report.NumRoundTripFailures-11)
+				break
+			}
+		}
+		os.Exit(1)
+	} else {
+		fmt.Printf("\n✓ All spot checks passed! No collisions or round-trip failures detected.\n")
+	}
+}
+
+// parseRange parses "lo:hi", with each bound in any base accepted by
+// strconv.ParseUint, or "all".
+func parseRange(arg string) (uint64, uint64, error) {
+
+	if arg == "all" {
+		return 0, verify.Domain, nil
+	}
+
+	parts := strings.Split(arg, ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid range %q: expected lo:hi", arg)
+	}
+
+	lo, err := strconv.ParseUint(parts[0], 0, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %v", arg, err)
+	}
+
+	hi, err := strconv.ParseUint(parts[1], 0, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %v", arg, err)
+	}
+
+	return lo, hi, nil
+}
+
+func checkRange(lo, hi uint64, workers int, verbose bool) verify.Report {
+
+	fmt.Printf("Starting exhaustive check of [%#x, %#x) using %d workers...\n", lo, hi, workers)
+
+	opts := verify.Options{Workers: workers}
+	if verbose {
+		step := (hi - lo) / 100
+		if step == 0 {
+			step = 1
+		}
+		var next uint64 = step
+		var mu sync.Mutex
+		opts.Progress = func(checked uint64) {
+			mu.Lock()
+			defer mu.Unlock()
+			if checked >= next {
+				fmt.Printf("Progress: %d/%d (%.1f%%)\n", checked, hi-lo, float64(checked)/float64(hi-lo)*100)
+				next = checked + step
+			}
+		}
 	}
-	
+
+	report, err := verify.Range(context.Background(), lo, hi, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return report
+}
+
+func spotCheck(numChecks, workers int, verbose bool) verify.Report {
+
 	fmt.Printf("Starting spot-check with %d random values using %d workers...\n", numChecks, workers)
-	start := time.Now()
-	
+
 	// Define test ranges
 	ranges := []struct {
 		name     string
-		start    *big.Int
-		end      *big.Int
+		start    uint64
+		end      uint64
 		fraction float64 // fraction of total tests
 	}{
-		{"small values (< 0x10000)", big.NewInt(0), big.NewInt(0x10000), 0.1},
-		{"low feistel (0x10000-0x1000000)", big.NewInt(0x10000), big.NewInt(0x1000000), 0.3},
-		{"mid feistel (0x1000000-0x10000000)", big.NewInt(0x1000000), big.NewInt(0x10000000), 0.3},
-		{"high feistel (0x10000000-0x100000000)", big.NewInt(0x10000000), big.NewInt(0x100000000), 0.3},
+		{"small values (< 0x10000)", 0, 0x10000, 0.1},
+		{"low feistel (0x10000-0x1000000)", 0x10000, 0x1000000, 0.3},
+		{"mid feistel (0x1000000-0x10000000)", 0x1000000, 0x10000000, 0.3},
+		{"high feistel (0x10000000-0x100000000)", 0x10000000, 0x100000000, 0.3},
 	}
-	
-	// Channel for work items
-	type workItem struct {
-		value    *big.Int
-		rangeIdx int
-	}
-	
-	workChan := make(chan workItem, workers*2)
-	resultChan := make(chan error, workers)
-	
-	// Shared map for collision detection (with mutex)
-	var mu sync.Mutex
-	seen := make(map[string]string)
-	
+
+	workChan := make(chan uint32, workers*2)
+	verifier := verify.New(verify.Options{Workers: workers})
+
 	// Progress tracking
 	var processed atomic.Int64
-	var collisions atomic.Int64
-	
+
 	// Start workers
 	var wg sync.WaitGroup
 	for i := 0; i < workers; i++ {
 		wg.Add(1)
-		go func(workerID int) {
+		go func() {
 			defer wg.Done()
-			
-			for item := range workChan {
-				// Encrypt
-				encrypted, err := ob.Fein(item.value.String())
-				if err != nil {
-					resultChan <- fmt.Errorf("worker %d: Fein failed for %s: %v", workerID, item.value.String(), err)
-					continue
-				}
-				
-				encStr := encrypted.String()
-				
-				// Check for collisions (with lock)
-				mu.Lock()
-				if original, exists := seen[encStr]; exists {
-					// Only report if it's a real collision (different inputs)
-					if original != item.value.String() {
-						mu.Unlock()
-						collisions.Add(1)
-						resultChan <- fmt.Errorf("COLLISION: %s and %s both encrypt to %s", original, item.value.String(), encStr)
-						continue
-					}
-				} else {
-					seen[encStr] = item.value.String()
-				}
-				mu.Unlock()
-				
-				// Verify round-trip
-				decrypted, err := ob.Fynd(encrypted)
-				if err != nil {
-					resultChan <- fmt.Errorf("worker %d: Fynd failed for %s: %v", workerID, encrypted.String(), err)
-					continue
-				}
-				
-				if decrypted.Cmp(item.value) != 0 {
-					resultChan <- fmt.Errorf("worker %d: Round-trip failed: %s -> %s -> %s", workerID, item.value.String(), encrypted.String(), decrypted.String())
-					continue
-				}
-				
+
+			for value := range workChan {
+				verifier.Check(value)
+
 				count := processed.Add(1)
 				if verbose && count%1000 == 0 {
 					fmt.Printf("Progress: %d/%d (%.1f%%)\n", count, numChecks, float64(count)/float64(numChecks)*100)
 				}
 			}
-		}(i)
+		}()
 	}
-	
+
 	// Generate work items
 	go func() {
-		for idx, r := range ranges {
+		var buf [4]byte
+		for _, r := range ranges {
 			numTests := int(float64(numChecks) * r.fraction)
-			rangeBig := new(big.Int).Sub(r.end, r.start)
-			
+
 			if verbose {
 				fmt.Printf("Generating %d values for range %s\n", numTests, r.name)
 			}
-			
+
 			for i := 0; i < numTests; i++ {
 				// Generate cryptographically random value in range
-				randOffset, err := rand.Int(rand.Reader, rangeBig)
-				if err != nil {
+				if _, err := rand.Read(buf[:]); err != nil {
 					log.Fatalf("Failed to generate random number: %v", err)
 				}
-				
-				testVal := new(big.Int).Add(r.start, randOffset)
-				workChan <- workItem{value: testVal, rangeIdx: idx}
+
+				offset := uint64(binary.LittleEndian.Uint32(buf[:])) % (r.end - r.start)
+				workChan <- uint32(r.start + offset)
 			}
 		}
 		close(workChan)
 	}()
-	
-	// Collect errors in a separate goroutine
-	var errors []error
-	errDone := make(chan struct{})
-	go func() {
-		for err := range resultChan {
-			errors = append(errors, err)
-		}
-		close(errDone)
-	}()
-	
+
 	// Wait for workers to finish
 	wg.Wait()
-	close(resultChan)
-	<-errDone
-	
-	elapsed := time.Since(start)
-	
-	// Print results
-	fmt.Printf("\n=== Spot Check Results ===\n")
-	fmt.Printf("Total values tested: %d\n", processed.Load())
-	fmt.Printf("Time elapsed: %v\n", elapsed)
-	fmt.Printf("Values per second: %.0f\n", float64(processed.Load())/elapsed.Seconds())
-	fmt.Printf("Collisions found: %d\n", collisions.Load())
-	
-	if len(errors) > 0 {
-		fmt.Printf("\n!!! ERRORS FOUND: %d !!!\n", len(errors))
-		for i, err := range errors {
-			fmt.Printf("Error %d: %v\n", i+1, err)
-			if i >= 10 && len(errors) > 11 {
-				fmt.Printf("... and %d more errors\n", len(errors)-11)
-				break
-			}
-		}
-		os.Exit(1)
-	} else {
-		fmt.Printf("\n✓ All spot checks passed! No collisions or round-trip failures detected.\n")
-	}
-}
\ No newline at end of file
+
+	return verifier.Report()
+}
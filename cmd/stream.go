@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+const (
+	// maxBatch is the largest number of inputs converted at once. Smaller
+	// batches are converted as soon as no more input is immediately available,
+	// so that interactive use and slow producers see results straight away.
+	maxBatch = 4096
+
+	// maxInputLen is the longest single input accepted from a stream.
+	maxInputLen = 1 << 20
+)
+
+// streamOptions control how a stream of inputs is converted.
+type streamOptions struct {
+	// source names the stream in error messages.
+	source string
+	// delim separates inputs, and results on output.
+	delim byte
+	// keepGoing reports failed inputs and carries on instead of stopping.
+	keepGoing bool
+	// workers is the number of inputs converted in parallel.
+	workers int
+}
+
+// record is one input of a stream.
+type record struct {
+	line int
+	text string
+}
+
+// stream converts every input read from r with cmd and writes the outcomes to
+// out in input order. If opts.keepGoing is set the stream carries on past
+// failed inputs, otherwise it stops after the first. stream returns the number
+// of failed inputs and any error reading or writing.
+func stream(ctx context.Context, cmd command, r io.Reader, out recordWriter, opts streamOptions) (int, error) {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	records := make(chan record, maxBatch)
+	var readErr error
+	go func() {
+		defer close(records)
+		readErr = scan(ctx, r, opts.delim, records)
+	}()
+
+	failed := 0
+
+	for rec, ok := <-records; ok; rec, ok = <-records {
+		batch := []record{rec}
+	collect:
+		for len(batch) < maxBatch {
+			select {
+			case rec, ok := <-records:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, rec)
+			default:
+				break collect
+			}
+		}
+
+		results := make([]interface{}, len(batch))
+		errs, err := ugi.ForEach(ctx, len(batch), opts.workers, func(i int) error {
+			result, err := convert(cmd, batch[i].text)
+			results[i] = result
+			return err
+		})
+		if err != nil {
+			return failed, err
+		}
+
+		for i, result := range results {
+			o := outcome{
+				source: opts.source,
+				line:   batch[i].line,
+				input:  strings.TrimSpace(batch[i].text),
+				result: result,
+			}
+			if errs != nil {
+				o.err = errs[i]
+			}
+
+			if err := out.write(o); err != nil {
+				return failed, err
+			}
+
+			if o.err != nil {
+				failed++
+				if !opts.keepGoing {
+					return failed, out.flush()
+				}
+			}
+		}
+
+		if err := out.flush(); err != nil {
+			return failed, err
+		}
+	}
+
+	return failed, readErr
+}
+
+// convert applies cmd to a single input, splitting it into cmd.nargs values.
+func convert(cmd command, text string) (interface{}, error) {
+
+	args := []string{strings.TrimSpace(text)}
+	if cmd.nargs > 1 {
+		args = strings.Fields(text)
+	}
+	if len(args) != cmd.nargs {
+		return nil, fmt.Errorf("expected %d values, got %d", cmd.nargs, len(args))
+	}
+
+	return cmd.apply(args)
+}
+
+// scan sends every delim-terminated input of r to records, stopping early if
+// ctx is done.
+func scan(ctx context.Context, r io.Reader, delim byte, records chan<- record) error {
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxInputLen)
+	scanner.Split(splitOn(delim))
+
+	for line := 1; scanner.Scan(); line++ {
+		select {
+		case records <- record{line: line, text: scanner.Text()}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	return scanner.Err()
+}
+
+// splitOn is a bufio.SplitFunc for inputs terminated by delim, where the last
+// input need not be terminated. A carriage return before a newline is dropped.
+func splitOn(delim byte) bufio.SplitFunc {
+
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+
+		if i := bytes.IndexByte(data, delim); i >= 0 {
+			token := data[:i]
+			if delim == '\n' {
+				token = bytes.TrimSuffix(token, []byte{'\r'})
+			}
+			return i + 1, token, nil
+		}
+
+		if atEOF {
+			return len(data), data, nil
+		}
+
+		return 0, nil, nil
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+
+	patp, _ := lookupCommand(cmdPatp)
+	eqpatq, _ := lookupCommand(cmdEqPatq)
+
+	tests := []struct {
+		name   string
+		cmd    command
+		input  string
+		opts   streamOptions
+		output string
+		errs   string
+		failed int
+	}{
+		{"newlines", patp, "0\n65535\r\n65536", streamOptions{delim: '\n'}, "~zod\n~fipfes\n~dapnep-ronmyl\n", "", 0},
+		{"nul", patp, "0\x00255\x00", streamOptions{delim: 0}, "~zod\x00~fes\x00", "", 0},
+		{"stop", patp, "0\nbad\n1\n", streamOptions{source: "in", delim: '\n'}, "~zod\n", "in:2: invalid integer string: bad\n", 1},
+		{"continue", patp, "bad\n1\nworse\n", streamOptions{source: "in", delim: '\n', keepGoing: true}, "\n~nec\n\n", "in:1: invalid integer string: bad\nin:3: invalid integer string: worse\n", 2},
+		{"pairs", eqpatq, "~zod ~zod\n~zod\t~nec\n", streamOptions{delim: '\n'}, "true\nfalse\n", "", 0},
+		{"missing pair", eqpatq, "~zod\n", streamOptions{source: "in", delim: '\n'}, "", "in:1: expected 2 values, got 1\n", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errs bytes.Buffer
+			w, err := newRecordWriter(outputText, &out, &errs, tt.opts.delim, tt.opts.keepGoing)
+			if err != nil {
+				t.Fatal(err)
+			}
+			failed, err := stream(context.Background(), tt.cmd, strings.NewReader(tt.input), w, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.output {
+				t.Errorf("output %q, want %q", out.String(), tt.output)
+			}
+			if errs.String() != tt.errs {
+				t.Errorf("errors %q, want %q", errs.String(), tt.errs)
+			}
+			if failed != tt.failed {
+				t.Errorf("%d failed, want %d", failed, tt.failed)
+			}
+		})
+	}
+}
+
+func TestStreamParallelOrder(t *testing.T) {
+
+	patp, _ := lookupCommand(cmdPatp)
+
+	var input, want strings.Builder
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(&input, "%d\n", i*7919)
+		p, _ := patp.apply([]string{fmt.Sprint(i * 7919)})
+		fmt.Fprintf(&want, "%s\n", p)
+	}
+
+	var out bytes.Buffer
+	w, _ := newRecordWriter(outputText, &out, &out, '\n', false)
+	opts := streamOptions{delim: '\n', workers: 4}
+	if _, err := stream(context.Background(), patp, strings.NewReader(input.String()), w, opts); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != want.String() {
+		t.Error("results are not in input order")
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+
+	tests := []struct {
+		args       []string
+		positional []string
+		continued  bool
+	}{
+		{[]string{"patp", "1"}, []string{"patp", "1"}, false},
+		{[]string{"-k", "patp", "1"}, []string{"patp", "1"}, true},
+		{[]string{"patp", "1", "--continue", "2"}, []string{"patp", "1", "2"}, true},
+		{[]string{"patp", "-"}, []string{"patp", "-"}, false},
+		{[]string{"patp", "--", "-k"}, []string{"patp", "-k"}, false},
+		{[]string{"patp", "-1"}, []string{"patp", "-1"}, false},
+		{[]string{"-1", "-k"}, []string{"-1"}, true},
+		{[]string{"patp", "-k", "-1.000", "2"}, []string{"patp", "-1.000", "2"}, true},
+		{[]string{"patp", "--size", "-1", "2"}, []string{"patp", "2"}, false},
+		{[]string{"patp", "--size=-1", "-2"}, []string{"patp", "-2"}, false},
+	}
+
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		var keepGoing bool
+		fs.BoolVar(&keepGoing, "continue", false, "")
+		fs.BoolVar(&keepGoing, "k", false, "")
+		fs.Int("size", 0, "")
+
+		positional, err := parseArgs(fs, tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(positional) != fmt.Sprint(tt.positional) || keepGoing != tt.continued {
+			t.Errorf("parseArgs(%q) = %q, %v", tt.args, positional, keepGoing)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package main
+
+import "errors"
+
+// isTerminal reports whether fd refers to a terminal. Line editing is only
+// supported on Unix systems, so elsewhere input is always read line by line.
+func isTerminal(fd int) bool {
+
+	return false
+}
+
+func makeRaw(fd int) (func(), error) {
+
+	return nil, errors.New("raw terminal mode is not supported on this system")
+}
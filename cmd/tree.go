@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/deelawn/urbit-gob/co"
+)
+
+const (
+	cmdTree string = "tree"
+
+	// Tree formats
+	treeText    string = "text"
+	treeDOT     string = "dot"
+	treeMermaid string = "mermaid"
+
+	defaultTreeLimit uint64 = 256
+
+	errInvalidTreeStr string = "invalid tree format: %s (want text, dot or mermaid)"
+)
+
+// treeNode is a ship in a hierarchy, with the children shown and the number of
+// those after them that are left out.
+type treeNode struct {
+	name     string
+	children []*treeNode
+	more     uint64
+}
+
+// treeOptions choose the part of a hierarchy to show: depth levels of
+// children, at most limit for each ship, starting from the offset-th child of
+// the root.
+type treeOptions struct {
+	depth  int
+	limit  uint64
+	offset uint64
+}
+
+// treeArgs are the flags of tree.
+type treeArgs struct {
+	opts   treeOptions
+	up     bool
+	format string
+}
+
+func (a *treeArgs) flags(g *globals) *flag.FlagSet {
+
+	fs := flag.NewFlagSet(cmdTree, flag.ContinueOnError)
+	fs.IntVar(&a.opts.depth, "depth", 1, "number of levels of children to show")
+	fs.Uint64Var(&a.opts.limit, "limit", defaultTreeLimit, "most children to show for each ship")
+	fs.Uint64Var(&a.opts.offset, "offset", 0, "number of the ship's children to skip, for paging through them")
+	fs.BoolVar(&a.up, "ancestors", false, "show the ship's chain of sponsors instead of its children")
+	fs.StringVar(&a.format, "format", treeText, "output `format`: text, dot (Graphviz) or mermaid")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [flags] SHIP\n\n", os.Args[0], cmdTree)
+		fmt.Fprintf(fs.Output(), "Prints the ships SHIP sponsors, or with --ancestors those sponsoring it.\n")
+		fmt.Fprintf(fs.Output(), "SHIP is a @p or a point.\n\n")
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+func runTree(g *globals, args []string) int {
+
+	var a treeArgs
+	fs := a.flags(g)
+
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return codeInsufficientArguments
+	}
+	if len(rest) != 1 || a.opts.depth < 0 {
+		fs.Usage()
+		return codeInsufficientArguments
+	}
+
+	switch a.format {
+	case treeText, treeDOT, treeMermaid:
+	default:
+		fmt.Fprintf(os.Stderr, errInvalidTreeStr+"\n", a.format)
+		return codeInsufficientArguments
+	}
+
+	name, err := shipName(rest[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeErrorReturned
+	}
+
+	var root *treeNode
+	if a.up {
+		root, err = ancestors(name)
+	} else {
+		root, err = descendants(name, a.opts)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeErrorReturned
+	}
+
+	if err := writeTree(os.Stdout, a.format, root); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return codeErrorReturned
+	}
+
+	return 0
+}
+
+// descendants returns the tree of the ships that name sponsors.
+func descendants(name string, opts treeOptions) (*treeNode, error) {
+
+	node := &treeNode{name: name}
+	if opts.depth == 0 {
+		return node, nil
+	}
+
+	ship, err := co.Describe(name)
+	if err != nil {
+		return nil, err
+	}
+	if opts.offset >= ship.Children {
+		return node, nil
+	}
+
+	end := ship.Children
+	if opts.limit < end-opts.offset {
+		end = opts.offset + opts.limit
+	}
+	node.more = ship.Children - end
+
+	next := treeOptions{depth: opts.depth - 1, limit: opts.limit}
+	for i := opts.offset; i < end; i++ {
+		child, err := co.Child(name, i)
+		if err != nil {
+			return nil, err
+		}
+		c, err := descendants(child, next)
+		if err != nil {
+			return nil, err
+		}
+		node.children = append(node.children, c)
+	}
+
+	return node, nil
+}
+
+// ancestors returns the chain of sponsors of name, from its galaxy down to
+// name itself.
+func ancestors(name string) (*treeNode, error) {
+
+	node := &treeNode{name: name}
+	for {
+		class, err := co.Clan(node.name)
+		if err != nil {
+			return nil, err
+		}
+		if class == co.ShipClassGalaxy {
+			return node, nil
+		}
+
+		sein, err := co.Sein(node.name)
+		if err != nil {
+			return nil, err
+		}
+		node = &treeNode{name: sein, children: []*treeNode{node}}
+	}
+}
+
+// writeTree writes the tree from root to w in the given format, returning the
+// first error writing it.
+func writeTree(out io.Writer, format string, root *treeNode) error {
+
+	// Errors are kept by the buffer and returned by Flush.
+	w := bufio.NewWriter(out)
+
+	switch format {
+	case treeText:
+		fmt.Fprintln(w, root.name)
+		writeTreeText(w, root, "")
+	case treeDOT:
+		fmt.Fprintf(w, "digraph ships {\n\t%q;\n", root.name)
+		writeTreeDOT(w, root)
+		fmt.Fprintln(w, "}")
+	case treeMermaid:
+		fmt.Fprintf(w, "graph TD\n\tn0[%q]\n", root.name)
+		writeTreeMermaid(w, root, "n0", new(int))
+	default:
+		return fmt.Errorf(errInvalidTreeStr, format)
+	}
+
+	return w.Flush()
+}
+
+func writeTreeText(w io.Writer, n *treeNode, indent string) {
+
+	for i, c := range n.children {
+		branch, next := "├── ", "│   "
+		if i == len(n.children)-1 && n.more == 0 {
+			branch, next = "└── ", "    "
+		}
+		fmt.Fprintf(w, "%s%s%s\n", indent, branch, c.name)
+		writeTreeText(w, c, indent+next)
+	}
+
+	if n.more > 0 {
+		fmt.Fprintf(w, "%s└── … %d more\n", indent, n.more)
+	}
+}
+
+func writeTreeDOT(w io.Writer, n *treeNode) {
+
+	for _, c := range n.children {
+		fmt.Fprintf(w, "\t%q -> %q;\n", n.name, c.name)
+		writeTreeDOT(w, c)
+	}
+
+	if n.more > 0 {
+		more := n.name + "+more"
+		fmt.Fprintf(w, "\t%q [label=\"… %d more\", shape=plaintext];\n", more, n.more)
+		fmt.Fprintf(w, "\t%q -> %q [style=dashed];\n", n.name, more)
+	}
+}
+
+// writeTreeMermaid writes the children of n, whose node is id. Nodes are
+// numbered by last, since Mermaid ids cannot hold the characters of names.
+func writeTreeMermaid(w io.Writer, n *treeNode, id string, last *int) {
+
+	for _, c := range n.children {
+		*last++
+		cid := fmt.Sprintf("n%d", *last)
+		fmt.Fprintf(w, "\t%s --> %s[%q]\n", id, cid, c.name)
+		writeTreeMermaid(w, c, cid, last)
+	}
+
+	if n.more > 0 {
+		*last++
+		fmt.Fprintf(w, "\t%s -.-> n%d[\"… %d more\"]\n", id, *last, n.more)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		opts   treeOptions
+		up     bool
+		format string
+		want   string
+	}{
+		{"~zod", treeOptions{depth: 1, limit: 2}, false, treeText, "" +
+			"~zod\n" +
+			"├── ~marzod\n" +
+			"├── ~binzod\n" +
+			"└── … 253 more\n"},
+		{"~zod", treeOptions{depth: 1, limit: 2, offset: 253}, false, treeText, "" +
+			"~zod\n" +
+			"├── ~mipzod\n" +
+			"└── ~fipzod\n"},
+		{"~zod", treeOptions{depth: 2, limit: 1}, false, treeText, "" +
+			"~zod\n" +
+			"├── ~marzod\n" +
+			"│   ├── ~wicdev-wisryt\n" +
+			"│   └── … 65534 more\n" +
+			"└── … 254 more\n"},
+		{"~zod", treeOptions{depth: 0, limit: 1}, false, treeText, "~zod\n"},
+		{"~doznec-sampel-palnet", treeOptions{depth: 1, limit: 1}, false, treeText, "~doznec-sampel-palnet\n"},
+		{"~doznec-sampel-palnet", treeOptions{}, true, treeText, "" +
+			"~pur\n" +
+			"└── ~talpur\n" +
+			"    └── ~sampel-palnet\n" +
+			"        └── ~doznec-sampel-palnet\n"},
+		{"~marzod", treeOptions{}, true, treeDOT, "" +
+			"digraph ships {\n" +
+			"\t\"~zod\";\n" +
+			"\t\"~zod\" -> \"~marzod\";\n" +
+			"}\n"},
+		{"~zod", treeOptions{depth: 1, limit: 1}, false, treeDOT, "" +
+			"digraph ships {\n" +
+			"\t\"~zod\";\n" +
+			"\t\"~zod\" -> \"~marzod\";\n" +
+			"\t\"~zod+more\" [label=\"… 254 more\", shape=plaintext];\n" +
+			"\t\"~zod\" -> \"~zod+more\" [style=dashed];\n" +
+			"}\n"},
+		{"~zod", treeOptions{depth: 2, limit: 1}, false, treeMermaid, "" +
+			"graph TD\n" +
+			"\tn0[\"~zod\"]\n" +
+			"\tn0 --> n1[\"~marzod\"]\n" +
+			"\tn1 --> n2[\"~wicdev-wisryt\"]\n" +
+			"\tn1 -.-> n3[\"… 65534 more\"]\n" +
+			"\tn0 -.-> n4[\"… 254 more\"]\n"},
+	}
+
+	for _, tt := range tests {
+		var root *treeNode
+		var err error
+		if tt.up {
+			root, err = ancestors(tt.name)
+		} else {
+			root, err = descendants(tt.name, tt.opts)
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		var b bytes.Buffer
+		if err := writeTree(&b, tt.format, root); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != tt.want {
+			t.Errorf("%s %+v %s:\ngot\n%s\nwant\n%s", tt.name, tt.opts, tt.format, b.String(), tt.want)
+		}
+	}
+
+	root, err := descendants("~zod", treeOptions{depth: 1, limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := ioutil.TempFile("", "tree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.Close()
+	if err := writeTree(f, treeText, root); err == nil {
+		t.Error("expected an error writing to a closed file")
+	}
+	stdout := os.Stdout
+	os.Stdout = f
+	code := runTree(&globals{}, []string{"~zod"})
+	os.Stdout = stdout
+	if code != codeErrorReturned {
+		t.Errorf("runTree writing to a closed file: got code %d, want %d", code, codeErrorReturned)
+	}
+
+	if _, err := descendants("~zodd", treeOptions{depth: 1}); err == nil {
+		t.Error("expected an error for an invalid name")
+	}
+}
@@ -0,0 +1,57 @@
+package co
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+	"github.com/deelawn/urbit-gob/ob"
+)
+
+// BatchOptions configures the batch functions.
+type BatchOptions = ob.BatchOptions
+
+// PatpBatch converts every point of src to a @p and stores the names in dst,
+// which must be at least as long as src.
+//
+// The returned slice is nil if every point was converted; otherwise it holds
+// the error for each point, nil for those that succeeded. If ctx is done before
+// all points are processed, the rest are left untouched, their errors are set
+// to ctx.Err() and that error is also returned.
+func PatpBatch(ctx context.Context, dst []string, src []*big.Int, opts BatchOptions) ([]error, error) {
+
+	if len(dst) < len(src) {
+		return nil, fmt.Errorf(ugi.ErrBatchLength, "PatpBatch", len(dst), len(src))
+	}
+
+	return ugi.ForEach(ctx, len(src), opts.Workers, func(i int) error {
+		p, err := patpPoint(src[i])
+		if err != nil {
+			return err
+		}
+		dst[i] = p
+
+		return nil
+	})
+}
+
+// ParsePatpBatch converts every @p of src to its point and stores the points
+// in dst, which must be at least as long as src. Errors are reported as by
+// PatpBatch.
+func ParsePatpBatch(ctx context.Context, dst []*big.Int, src []string, opts BatchOptions) ([]error, error) {
+
+	if len(dst) < len(src) {
+		return nil, fmt.Errorf(ugi.ErrBatchLength, "ParsePatpBatch", len(dst), len(src))
+	}
+
+	return ugi.ForEach(ctx, len(src), opts.Workers, func(i int) error {
+		point, err := patp2bn(src[i])
+		if err != nil {
+			return err
+		}
+		dst[i] = point
+
+		return nil
+	})
+}
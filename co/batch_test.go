@@ -0,0 +1,70 @@
+package co
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatpBatch(t *testing.T) {
+	comet, _ := big.NewInt(0).SetString("ffffffffffffffffffffffffffffffff", 16)
+	src := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(255),
+		big.NewInt(256),
+		big.NewInt(65535),
+		big.NewInt(65536),
+		big.NewInt(14287616),
+		big.NewInt(4294967295),
+		comet,
+		nil,
+	}
+
+	for _, workers := range []int{0, 3} {
+		opts := BatchOptions{Workers: workers}
+
+		names := make([]string, len(src))
+		errs, err := PatpBatch(context.Background(), names, src, opts)
+		require.NoError(t, err)
+		require.Len(t, errs, len(src))
+
+		for i, point := range src[:len(src)-1] {
+			want, err := Patp(point)
+			require.NoError(t, err)
+			assert.NoError(t, errs[i])
+			assert.Equal(t, want, names[i])
+		}
+		assert.EqualError(t, errs[len(src)-1], "invalid integer string: <nil>")
+
+		points := make([]*big.Int, len(src))
+		names[len(src)-1] = "~invalid"
+		errs, err = ParsePatpBatch(context.Background(), points, names, opts)
+		require.NoError(t, err)
+		require.Len(t, errs, len(src))
+
+		for i, point := range src[:len(src)-1] {
+			assert.NoError(t, errs[i])
+			assert.Equal(t, 0, point.Cmp(points[i]), "%s parsed as %v, want %v", names[i], points[i], point)
+		}
+		assert.EqualError(t, errs[len(src)-1], "invalid @p: ~invalid")
+	}
+}
+
+func TestPatpBatchErrors(t *testing.T) {
+	_, err := PatpBatch(context.Background(), nil, []*big.Int{big.NewInt(1)}, BatchOptions{})
+	assert.EqualError(t, err, "PatpBatch: dst has 0 items, need at least 1")
+
+	_, err = ParsePatpBatch(context.Background(), nil, []string{"~zod"}, BatchOptions{})
+	assert.EqualError(t, err, "ParsePatpBatch: dst has 0 items, need at least 1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	names := make([]string, 1)
+	errs, err := PatpBatch(ctx, names, []*big.Int{big.NewInt(1)}, BatchOptions{})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, []error{context.Canceled}, errs)
+	assert.Empty(t, names[0])
+}
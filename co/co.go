@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/deelawn/urbit-gob/aura"
 	ugi "github.com/deelawn/urbit-gob/internal"
 	"github.com/deelawn/urbit-gob/ob"
 )
@@ -73,6 +74,9 @@ var (
 	// of two syllables, or one of one syllables in the case of galaxies, that make up a ship name.
 	Suffixes = regexp.MustCompile(namePartitionPattern).FindAllString(suf, -1)
 
+	removeCharsPattern = regexp.MustCompile(`[\^~-]`)
+	partitionPattern   = regexp.MustCompile(namePartitionPattern)
+
 	prefixesIndex = map[string]int{}
 	suffixesIndex = map[string]int{}
 	prefixes      = make([]string, len(Prefixes))
@@ -95,20 +99,24 @@ func init() {
 
 func patp2syls(name string) []string {
 
-	removeCharsPattern := regexp.MustCompile(`[\^~-]`)
 	normalizedName := removeCharsPattern.ReplaceAllString(name, "")
-	partitionPattern := regexp.MustCompile(namePartitionPattern)
 	return partitionPattern.FindAllString(normalizedName, -1)
 }
 
 func bex(n *big.Int) *big.Int {
 
-	return big.NewInt(0).Exp(two, n, nil)
+	return big.NewInt(0).Lsh(one, uint(n.Uint64()))
+}
+
+// bits returns the number of bits in b blocks of 2^a bits.
+func bits(a, b *big.Int) uint {
+
+	return uint(b.Uint64()) << uint(a.Uint64())
 }
 
 func rsh(a, b, c *big.Int) *big.Int {
 
-	return big.NewInt(0).Div(c, bex(big.NewInt(0).Mul(bex(a), b)))
+	return big.NewInt(0).Rsh(c, bits(a, b))
 }
 
 func met(a, b, c *big.Int) *big.Int {
@@ -117,34 +125,90 @@ func met(a, b, c *big.Int) *big.Int {
 		c = big.NewInt(0)
 	}
 
-	if b.Cmp(zero) == 0 {
-		return c
-	}
+	// The number of 2^a-bit blocks needed to hold b.
+	bloq := uint(1) << uint(a.Uint64())
+	n := (uint(b.BitLen()) + bloq - 1) / bloq
 
-	return met(a, rsh(a, one, b), big.NewInt(0).Add(c, one))
+	return big.NewInt(0).Add(c, big.NewInt(int64(n)))
 }
 
 func end(a, b, c *big.Int) *big.Int {
 
-	return big.NewInt(0).Mod(c, bex(big.NewInt(0).Mul(bex(a), b)))
+	mask := big.NewInt(0).Sub(bex(big.NewInt(int64(bits(a, b)))), one)
+
+	return mask.And(c, mask)
 }
 
-// Hex2Patp converts a hex-encoded string to a @p-encoded string.
+// Hex2Patp converts a hex-encoded string, or a @ux such as 0x1.0000, to a
+// @p-encoded string.
 func Hex2Patp(hex string) (string, error) {
 
-	v, ok := big.NewInt(0).SetString(hex, 16)
+	v, err := parseHex(hex)
+	if err != nil {
+		return "", err
+	}
+
+	return Patp(v)
+}
+
+// parseDec parses a decimal string, or a @ud such as 1.000.000.
+func parseDec(s string) (*big.Int, error) {
+
+	if strings.Contains(s, ".") {
+		return aura.ParseUd(s)
+	}
+
+	v, ok := big.NewInt(0).SetString(s, 10)
 	if !ok {
-		return "", fmt.Errorf(ugi.ErrInvalidHex, hex)
+		return nil, fmt.Errorf(ugi.ErrInvalidInt, s)
 	}
 
-	return Patp(v.String())
+	return v, nil
+}
+
+// parseHex parses a hex string, with or without 0x, or a @ux such as
+// 0x1.0000.
+func parseHex(s string) (*big.Int, error) {
+
+	if strings.Contains(s, ".") {
+		return aura.ParseUx(s)
+	}
+
+	v, ok := big.NewInt(0).SetString(strings.TrimPrefix(s, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf(ugi.ErrInvalidHex, s)
+	}
+
+	return v, nil
 }
 
 // Patp2Hex converts a @p-encoded string to a hex-encoded string.
 func Patp2Hex(name string) (string, error) {
 
+	v, err := patp2bn(name)
+	if err != nil {
+		return "", err
+	}
+
+	hex := v.Text(16)
+
+	if len(hex)%2 != 0 {
+		return "0" + hex, nil
+	}
+
+	return hex, nil
+}
+
+func syl2bin(idx int) string {
+
+	binStr := strconv.FormatInt(int64(idx), 2)
+	return strings.Repeat("0", 8-len(binStr)) + binStr // padStart
+}
+
+func patp2bn(name string) (*big.Int, error) {
+
 	if !IsValidPat(name) {
-		return "", fmt.Errorf(ugi.ErrInvalidP, name)
+		return nil, fmt.Errorf(ugi.ErrInvalidP, name)
 	}
 
 	syls := patp2syls(name)
@@ -161,42 +225,42 @@ func Patp2Hex(name string) (string, error) {
 
 	bigAddr, ok := big.NewInt(0).SetString(addr, 2)
 	if !ok {
-		return "", fmt.Errorf(ugi.ErrInvalidBin, addr)
+		return nil, fmt.Errorf(ugi.ErrInvalidBin, addr)
 	}
 
-	v, err := ob.Fynd(bigAddr)
-	if err != nil {
-		return "", nil
-	}
+	return fynd(bigAddr)
+}
 
-	hex := v.Text(16)
+// fein scrambles a point with ob.Fein. Comets, which are wider than 64 bits,
+// are not scrambled by @p and are returned as they are.
+func fein(v *big.Int) (*big.Int, error) {
 
-	if len(hex)%2 != 0 {
-		return "0" + hex, nil
+	if v.BitLen() > 64 {
+		return v, nil
 	}
 
-	return hex, nil
+	return ob.Fein(v)
 }
 
-func syl2bin(idx int) string {
+// fynd is the inverse of fein.
+func fynd(v *big.Int) (*big.Int, error) {
 
-	binStr := strconv.FormatInt(int64(idx), 2)
-	return strings.Repeat("0", 8-len(binStr)) + binStr // padStart
+	if v.BitLen() > 64 {
+		return v, nil
+	}
+
+	return ob.Fynd(v)
 }
 
-func patp2bn(name string) (*big.Int, error) {
+// canonical returns v, or a freshly made zero if v is zero, so that results
+// compare equal regardless of the arithmetic that produced them.
+func canonical(v *big.Int) *big.Int {
 
-	hexStr, err := Patp2Hex(name)
-	if err != nil {
-		return nil, err
-	}
-
-	hex, ok := big.NewInt(0).SetString(hexStr, 16)
-	if !ok {
-		return nil, fmt.Errorf(ugi.ErrInvalidHex, hexStr)
+	if v.Sign() == 0 {
+		return big.NewInt(0)
 	}
 
-	return hex, nil
+	return v
 }
 
 // Patp2Point converts a @p-encoded string to a big.Int pointer.
@@ -227,9 +291,9 @@ func Patp2Dec(name string) (string, error) {
 
 func patq(arg string) (string, error) {
 
-	v, ok := big.NewInt(0).SetString(arg, 10)
-	if !ok {
-		return "", fmt.Errorf(ugi.ErrInvalidInt, arg)
+	v, err := parseDec(arg)
+	if err != nil {
+		return "", err
 	}
 
 	buf := v.Bytes()
@@ -335,12 +399,20 @@ func chunk(items []byte, size int) [][]byte {
 	return slices
 }
 
-// Hex2Patq converts a hex-encoded string to a @q-encoded string.
-// Note that this preserves leading zero bytes.
+// Hex2Patq converts a hex-encoded string, or a @ux such as 0x1.0000, to a
+// @q-encoded string. Note that this preserves leading zero bytes of plain hex.
 func Hex2Patq(arg string) (string, error) {
 
-	hexStr := arg
-	if len(arg)%2 != 0 {
+	if strings.Contains(arg, ".") {
+		v, err := aura.ParseUx(arg)
+		if err != nil {
+			return "", err
+		}
+		return Patq(v)
+	}
+
+	hexStr := strings.TrimPrefix(arg, "0x")
+	if len(hexStr)%2 != 0 {
 		hexStr = "0" + hexStr
 	}
 
@@ -410,7 +482,7 @@ func patq2bn(name string) (*big.Int, error) {
 		return nil, fmt.Errorf(ugi.ErrInvalidHex, name)
 	}
 
-	return v, nil
+	return canonical(v), nil
 }
 
 // Patq2Dec converts a @q-encoded string to a decimal-encoded string.
@@ -432,31 +504,37 @@ func Clan(who string) (string, error) {
 		return ShipClassEmpty, err
 	}
 
+	return clan(name), nil
+}
+
+// ClanPoint determines the ship class of a big.Int-encoded @p value.
+func ClanPoint(arg *big.Int) (string, error) {
+
+	if err := checkPoint("ClanPoint", arg); err != nil {
+		return ShipClassEmpty, err
+	}
+
+	return clan(arg), nil
+}
+
+func clan(name *big.Int) string {
+
 	wid := met(three, name, nil)
 
 	if wid.Cmp(one) <= 0 {
-		return ShipClassGalaxy, nil
+		return ShipClassGalaxy
 	}
 	if wid.Cmp(two) <= 0 {
-		return ShipClassStar, nil
+		return ShipClassStar
 	}
 	if wid.Cmp(four) <= 0 {
-		return ShipClassPlanet, nil
+		return ShipClassPlanet
 	}
 	if wid.Cmp(eight) <= 0 {
-		return ShipClassMoon, nil
+		return ShipClassMoon
 	}
 
-	return ShipClassComet, nil
-}
-
-// ClanPoint determines the ship class of a big.Int-encoded @p value.
-func ClanPoint(arg *big.Int) (string, error) {
-	patp, err := Patp(arg)
-	if err != nil {
-		return "", err
-	}
-	return Clan(patp)
+	return ShipClassComet
 }
 
 // Sein determines the parent of a @p value.
@@ -467,40 +545,45 @@ func Sein(name string) (string, error) {
 		return "", err
 	}
 
-	mir, err := Clan(name)
-	if err != nil {
-		return "", err
+	return Patp(sein(who))
+}
+
+// SeinPoint determines the parent of a big.Int-encoded @p value.
+func SeinPoint(arg *big.Int) (*big.Int, error) {
+
+	if err := checkPoint("SeinPoint", arg); err != nil {
+		return nil, err
 	}
 
-	var res *big.Int
-	switch mir {
+	return canonical(sein(arg)), nil
+}
+
+// sein returns a new big.Int holding the parent of who.
+func sein(who *big.Int) *big.Int {
+
+	switch clan(who) {
 	case ShipClassGalaxy:
-		res = who
+		return big.NewInt(0).Set(who)
 	case ShipClassStar:
-		res = end(three, one, who)
+		return end(three, one, who)
 	case ShipClassPlanet:
-		res = end(four, one, who)
+		return end(four, one, who)
 	case ShipClassMoon:
-		res = end(five, one, who)
+		return end(five, one, who)
 	default:
-		res = zero
+		return big.NewInt(0)
 	}
-
-	return Patp(res.String())
 }
 
-// SeinPoint determines the parent of a big.Int-encoded @p value.
-func SeinPoint(arg *big.Int) (*big.Int, error) {
-	patp, err := Point2Patp(arg)
-	if err != nil {
-		return nil, err
-	}
-	sein, err := Sein(patp)
-	if err != nil {
-		return nil, err
+// checkPoint returns an error naming fn if v is nil or negative. Points have
+// no upper bound, since comets run past 2^64.
+func checkPoint(fn string, v *big.Int) error {
+
+	if v == nil || v.Sign() < 0 {
+		return fmt.Errorf(ugi.ErrNegativePoint, fn, v)
 	}
 
-	return Patp2Point(sein)
+	return nil
 }
 
 /*
@@ -604,12 +687,21 @@ func EqPatq(p, q string) (bool, error) {
 }
 
 func patp(arg string) (string, error) {
-	v, ok := big.NewInt(0).SetString(arg, 10)
-	if !ok {
-		return "", fmt.Errorf(ugi.ErrInvalidInt, arg)
+	v, err := parseDec(arg)
+	if err != nil {
+		return "", err
+	}
+
+	return patpPoint(v)
+}
+
+func patpPoint(v *big.Int) (string, error) {
+
+	if v == nil {
+		return "", fmt.Errorf(ugi.ErrInvalidInt, v)
 	}
 
-	sxz, err := ob.Fein(v.String())
+	sxz, err := fein(v)
 	if err != nil {
 		return "", err
 	}
@@ -634,7 +726,7 @@ func Patp(arg interface{}) (string, error) {
 	case string:
 		return patp(v)
 	case *big.Int:
-		return patp(v.String())
+		return patpPoint(v)
 	default:
 		return "", fmt.Errorf(ugi.ErrInvalidP, arg)
 	}
@@ -246,6 +246,18 @@ func TestPatp(t *testing.T) {
 			in:  big.NewInt(4294967296),
 			out: "~doznec-dozzod-dozzod",
 		},
+		{
+			in:  "65.536",
+			out: "~dapnep-ronmyl",
+		},
+		{
+			in:  "4.294.967.295",
+			out: "~dostec-risfen",
+		},
+		{
+			in:              "65.53.6",
+			expectedErrText: `invalid @ud: "65.53.6"`,
+		},
 		{
 			in:              "abcdefg",
 			expectedErrText: "invalid integer string: abcdefg",
@@ -345,6 +357,14 @@ func TestPatq(t *testing.T) {
 			in:  big.NewInt(4294967296),
 			out: "~doznec-dozzod-dozzod",
 		},
+		{
+			in:  "65.536",
+			out: "~doznec-dozzod",
+		},
+		{
+			in:              "6.5536",
+			expectedErrText: `invalid @ud: "6.5536"`,
+		},
 		{
 			in:              "abcdefg",
 			expectedErrText: "invalid integer string: abcdefg",
@@ -739,6 +759,22 @@ func TestHex2Patp(t *testing.T) {
 			in:  "0100000000",
 			out: "~doznec-dozzod-dozzod",
 		},
+		{
+			in:  "0x1.0000",
+			out: "~dapnep-ronmyl",
+		},
+		{
+			in:  "0xda.0300",
+			out: "~rosmur-hobrem",
+		},
+		{
+			in:  "0x10000",
+			out: "~dapnep-ronmyl",
+		},
+		{
+			in:              "0x1.000",
+			expectedErrText: `invalid @ux: "0x1.000"`,
+		},
 		{
 			in:              "abcdefg",
 			expectedErrText: "invalid hexadecimal string: abcdefg",
@@ -794,6 +830,22 @@ func TestHex2Patq(t *testing.T) {
 			in:  "000100000000",
 			out: "~doznec-dozzod-dozzod",
 		},
+		{
+			in:  "0x1.0000",
+			out: "~doznec-dozzod",
+		},
+		{
+			in:  "0xffff.ffff",
+			out: "~fipfes-fipfes",
+		},
+		{
+			in:  "0x00010000",
+			out: "~doznec-dozzod",
+		},
+		{
+			in:              "0x1.000",
+			expectedErrText: `invalid @ux: "0x1.000"`,
+		},
 		{
 			in:              "abcdefg",
 			expectedErrText: "invalid hexadecimal string: abcdefg",
@@ -1065,6 +1117,14 @@ func TestSeinPoint(t *testing.T) {
 			in:  big.NewInt(4294967296),
 			out: big.NewInt(0),
 		},
+		{
+			in:              big.NewInt(-1),
+			expectedErrText: "SeinPoint: invalid integer -1: a point must be non-negative (comets above 2^64 are allowed)",
+		},
+		{
+			in:              nil,
+			expectedErrText: "SeinPoint: invalid integer <nil>: a point must be non-negative (comets above 2^64 are allowed)",
+		},
 	}
 
 	int2IntTestRunner(t, testCases, SeinPoint)
@@ -1117,6 +1177,10 @@ func TestClanPoint(t *testing.T) {
 			in:  big.NewInt(4294967296),
 			out: ShipClassMoon,
 		},
+		{
+			in:              big.NewInt(-1),
+			expectedErrText: "ClanPoint: invalid integer -1: a point must be non-negative (comets above 2^64 are allowed)",
+		},
 	}
 
 	int2StringTestRunner(t, testCases, ClanPoint)
@@ -0,0 +1,208 @@
+package co
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+const (
+	// Hoon ranks, the names Hoon's ++clan gives the ship classes.
+	RankCzar string = "czar"
+	RankKing string = "king"
+	RankDuke string = "duke"
+	RankEarl string = "earl"
+	RankPawn string = "pawn"
+)
+
+var ranks = map[string]string{
+	ShipClassGalaxy: RankCzar,
+	ShipClassStar:   RankKing,
+	ShipClassPlanet: RankDuke,
+	ShipClassMoon:   RankEarl,
+	ShipClassComet:  RankPawn,
+}
+
+// childBits is the number of low bits that the ships sponsored by a ship of
+// each class share with it. Ships of other classes sponsor none.
+var childBits = map[string]uint{
+	ShipClassGalaxy: 8,
+	ShipClassStar:   16,
+	ShipClassPlanet: 32,
+}
+
+// Ship describes a ship in every form the package knows.
+type Ship struct {
+	Point *big.Int
+	Hex   string
+	Patp  string
+	Patq  string
+	Class string
+	Rank  string
+
+	// Sponsors is the chain of sponsors as given by Sein, from the immediate
+	// sponsor up to the galaxy. It is empty for galaxies.
+	Sponsors []string
+
+	// Children is the number of ships this ship sponsors directly: 255 stars
+	// for a galaxy, 65,535 planets for a star and 4,294,967,295 moons for a
+	// planet.
+	Children uint64
+
+	// Cite is the abbreviated form Hoon's ++cite uses in the interface, such
+	// as ~sampel^palnet for a moon.
+	Cite string
+}
+
+// Describe returns a complete description of the ship named by a @p value.
+func Describe(name string) (*Ship, error) {
+
+	point, err := patp2bn(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return DescribePoint(point)
+}
+
+// DescribePoint returns a complete description of the ship at a point.
+func DescribePoint(point *big.Int) (*Ship, error) {
+
+	p, err := Patp(point)
+	if err != nil {
+		return nil, err
+	}
+
+	ship := &Ship{
+		Point: big.NewInt(0).Set(point),
+		Patp:  p,
+	}
+
+	if ship.Patq, err = Patq(point); err != nil {
+		return nil, err
+	}
+	if ship.Hex, err = Patp2Hex(p); err != nil {
+		return nil, err
+	}
+	if ship.Class, err = Clan(p); err != nil {
+		return nil, err
+	}
+	ship.Rank = ranks[ship.Class]
+
+	if bits, ok := childBits[ship.Class]; ok {
+		ship.Children = 1<<bits - 1
+	}
+
+	for who, class := p, ship.Class; class != ShipClassGalaxy; {
+		if who, err = Sein(who); err != nil {
+			return nil, err
+		}
+		if class, err = Clan(who); err != nil {
+			return nil, err
+		}
+		ship.Sponsors = append(ship.Sponsors, who)
+	}
+
+	ship.Cite = cite(p, point)
+
+	return ship, nil
+}
+
+// Child returns the i-th ship, counting from zero, that the ship named by a @p
+// value sponsors directly. Children are in order of their points, and Sein of
+// each is the ship itself.
+func Child(name string, i uint64) (string, error) {
+
+	point, err := patp2bn(name)
+	if err != nil {
+		return "", err
+	}
+
+	child, err := ChildPoint(point, i)
+	if err != nil {
+		return "", err
+	}
+
+	return Patp(child)
+}
+
+// ChildPoint returns the i-th ship, counting from zero, that the ship at a
+// point sponsors directly.
+func ChildPoint(point *big.Int, i uint64) (*big.Int, error) {
+
+	class, err := ClanPoint(point)
+	if err != nil {
+		return nil, err
+	}
+
+	bits, ok := childBits[class]
+	if !ok {
+		return nil, fmt.Errorf(ugi.ErrNoChildren, class, point)
+	}
+	if count := uint64(1)<<bits - 1; i >= count {
+		return nil, fmt.Errorf(ugi.ErrOutOfDomain, "ChildPoint", i, 0, count-1)
+	}
+
+	// A child has its sponsor in its low bits, and any non-zero value above.
+	child := big.NewInt(0).SetUint64(i + 1)
+	child.Lsh(child, bits)
+
+	return child.Or(child, point), nil
+}
+
+// cite implements Hoon's ++cite: galaxies, stars and planets are written in
+// full, moons as the last two words of their own @p joined by a caret
+// (~sampel^palnet) and comets as their first and last words (~sampel_palnet).
+// A moon's @p always ends in two words, even when its low 32 bits are a galaxy
+// or star, so ~doznec-dozzod-dozzod, a moon of ~zod, is ~dozzod^dozzod.
+func cite(name string, point *big.Int) string {
+
+	wid := met(four, point, nil).Int64()
+
+	switch {
+	case wid <= 2:
+		return name
+	case wid <= 4:
+		n := len(name)
+		return "~" + slice(name, n-13, n-7) + "^" + slice(name, n-6, n)
+	default:
+		return slice(name, 0, 7) + "_" + slice(name, 51, len(name))
+	}
+}
+
+// slice returns s[i:j], clipped to the length of s as Hoon's scag and slag do.
+func slice(s string, i, j int) string {
+
+	if j > len(s) {
+		j = len(s)
+	}
+	if i > j {
+		return ""
+	}
+
+	return s[i:j]
+}
+
+// MarshalJSON implements json.Marshaler. The point is written as a decimal
+// string, since it may not fit in a JSON number.
+func (s *Ship) MarshalJSON() ([]byte, error) {
+
+	sponsors := s.Sponsors
+	if sponsors == nil {
+		sponsors = []string{}
+	}
+
+	return json.Marshal(struct {
+		Point    string   `json:"point"`
+		Hex      string   `json:"hex"`
+		Patp     string   `json:"patp"`
+		Patq     string   `json:"patq"`
+		Class    string   `json:"class"`
+		Rank     string   `json:"rank"`
+		Sponsors []string `json:"sponsors"`
+		Children uint64   `json:"children"`
+		Cite     string   `json:"cite"`
+	}{s.Point.String(), s.Hex, s.Patp, s.Patq, s.Class, s.Rank, sponsors, s.Children, s.Cite})
+}
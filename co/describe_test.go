@@ -0,0 +1,112 @@
+package co
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe(t *testing.T) {
+	moon, _ := big.NewInt(0).SetString("1234567890123", 10)
+	comet, _ := big.NewInt(0).SetString("ffffffffffffffffffffffffffffffff", 16)
+
+	tests := []struct {
+		name  string
+		point *big.Int
+		want  Ship
+	}{
+		{"~zod", big.NewInt(0), Ship{
+			Hex: "00", Patq: "~zod", Class: ShipClassGalaxy, Rank: RankCzar,
+			Children: 255, Cite: "~zod",
+		}},
+		{"~marzod", big.NewInt(256), Ship{
+			Hex: "0100", Patq: "~marzod", Class: ShipClassStar, Rank: RankKing,
+			Sponsors: []string{"~zod"}, Children: 65535, Cite: "~marzod",
+		}},
+		{"~dapnep-ronmyl", big.NewInt(65536), Ship{
+			Hex: "010000", Patq: "~doznec-dozzod", Class: ShipClassPlanet, Rank: RankDuke,
+			Sponsors: []string{"~zod"}, Children: 4294967295, Cite: "~dapnep-ronmyl",
+		}},
+		{"~marnex-barfun-marpyl", moon, Ship{
+			Hex: "011f71fb04cb", Patq: "~marnex-tagteg-samlec", Class: ShipClassMoon, Rank: RankEarl,
+			Sponsors: []string{"~barfun-marpyl", "~samlec", "~lec"}, Cite: "~barfun^marpyl",
+		}},
+		{"~doznec-dozzod-dozzod", big.NewInt(1 << 32), Ship{
+			Hex: "0100000000", Patq: "~doznec-dozzod-dozzod", Class: ShipClassMoon, Rank: RankEarl,
+			Sponsors: []string{"~zod"}, Cite: "~dozzod^dozzod",
+		}},
+		{"~doznec-dozzod-marzod", big.NewInt(1<<32 | 256), Ship{
+			Hex: "0100000100", Patq: "~doznec-dozzod-marzod", Class: ShipClassMoon, Rank: RankEarl,
+			Sponsors: []string{"~marzod", "~zod"}, Cite: "~dozzod^marzod",
+		}},
+		{"~fipfes-fipfes-fipfes-fipfes--fipfes-fipfes-fipfes-fipfes", comet, Ship{
+			Hex: "ffffffffffffffffffffffffffffffff", Patq: "~fipfes-fipfes-fipfes-fipfes-fipfes-fipfes-fipfes-fipfes",
+			Class: ShipClassComet, Rank: RankPawn, Sponsors: []string{"~zod"}, Cite: "~fipfes_fipfes",
+		}},
+	}
+
+	for _, tt := range tests {
+		tt.want.Point = tt.point
+		tt.want.Patp = tt.name
+
+		got, err := Describe(tt.name)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, 0, got.Point.Cmp(tt.point), tt.name)
+		got.Point = tt.point
+		assert.Equal(t, tt.want, *got, tt.name)
+
+		byPoint, err := DescribePoint(tt.point)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, got.Patp, byPoint.Patp)
+	}
+
+	_, err := Describe("~invalid")
+	assert.EqualError(t, err, "invalid @p: ~invalid")
+}
+
+func TestShipJSON(t *testing.T) {
+	ship, err := Describe("~zod")
+	require.NoError(t, err)
+
+	data, err := json.Marshal(ship)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"point": "0", "hex": "00", "patp": "~zod", "patq": "~zod", "class": "galaxy",
+		"rank": "czar", "sponsors": [], "children": 255, "cite": "~zod"
+	}`, string(data))
+}
+
+func TestChild(t *testing.T) {
+	tests := []struct {
+		name string
+		i    uint64
+		want string
+	}{
+		{"~zod", 0, "~marzod"},
+		{"~zod", 254, "~fipzod"},
+		{"~nec", 0, "~marnec"},
+		{"~marzod", 0, "~wicdev-wisryt"},
+		{"~talpur", 0x60da - 1, "~sampel-palnet"},
+		{"~sampel-palnet", 0, "~doznec-sampel-palnet"},
+	}
+
+	for _, tt := range tests {
+		child, err := Child(tt.name, tt.i)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, child, "%s %d", tt.name, tt.i)
+
+		sein, err := Sein(child)
+		require.NoError(t, err)
+		assert.Equal(t, tt.name, sein, "sponsor of %s", child)
+	}
+
+	_, err := Child("~zod", 255)
+	assert.EqualError(t, err, "ChildPoint: 255 is outside the domain [0, 254]")
+	_, err = Child("~doznec-sampel-palnet", 0)
+	assert.EqualError(t, err, "a moon sponsors no ships: 5919928639")
+	_, err = ChildPoint(big.NewInt(-1), 0)
+	assert.Error(t, err)
+}
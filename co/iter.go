@@ -0,0 +1,207 @@
+package co
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+	"github.com/deelawn/urbit-gob/ob"
+)
+
+// PointIterator walks a set of points in a keyed pseudo-random order, visiting
+// each exactly once. The order is fixed by the key, so a walk that is
+// interrupted can be continued by a new iterator created with the same key
+// and resumed from a saved Cursor.
+type PointIterator struct {
+	perm   *ob.Perm
+	base   uint64
+	stride uint64
+	pos    uint64
+}
+
+// Cursor is the serializable position of a PointIterator. It records the
+// points being walked and a fingerprint of the key, but not the key itself.
+type Cursor struct {
+	Base        uint64
+	Stride      uint64
+	Count       uint64
+	Position    uint64
+	Fingerprint uint32
+}
+
+// NewPlanetIterator returns an iterator over the 65,535 planets of a star,
+// in an order determined by key.
+func NewPlanetIterator(star string, key []byte) (*PointIterator, error) {
+
+	class, err := Clan(star)
+	if err != nil {
+		return nil, err
+	}
+	if class != ShipClassStar {
+		return nil, fmt.Errorf(ugi.ErrNotStar, star)
+	}
+
+	point, err := patp2bn(star)
+	if err != nil {
+		return nil, err
+	}
+
+	// The planets of a star share its low 16 bits, and have any of the 65,535
+	// non-zero values in their high 16 bits.
+	return newPointIterator(point.Uint64()+0x10000, 0x10000, 0xffff, key)
+}
+
+// NewPointIterator returns an iterator over the points in [lo, hi], in an
+// order determined by key. Both bounds must fit in 64 bits and the range may
+// hold at most ob.PermMax points.
+func NewPointIterator(lo, hi *big.Int, key []byte) (*PointIterator, error) {
+
+	if lo == nil || hi == nil || lo.Sign() < 0 || hi.BitLen() > 64 || lo.Cmp(hi) > 0 {
+		return nil, fmt.Errorf(ugi.ErrInvalidRange, fmt.Sprintf("[%v, %v]", lo, hi))
+	}
+
+	count := big.NewInt(0).Sub(hi, lo)
+	if count.Uint64() >= ob.PermMax {
+		return nil, fmt.Errorf(ugi.ErrInvalidRange, fmt.Sprintf("[%v, %v] holds more than %d points", lo, hi, ob.PermMax))
+	}
+
+	return newPointIterator(lo.Uint64(), 1, count.Uint64()+1, key)
+}
+
+func newPointIterator(base, stride, count uint64, key []byte) (*PointIterator, error) {
+
+	perm, err := ob.NewPerm(key, count)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PointIterator{
+		perm:   perm,
+		base:   base,
+		stride: stride,
+	}, nil
+}
+
+// Len returns the total number of points in the walk.
+func (it *PointIterator) Len() uint64 {
+
+	return it.perm.Size()
+}
+
+// Remaining returns the number of points not yet visited.
+func (it *PointIterator) Remaining() uint64 {
+
+	return it.perm.Size() - it.pos
+}
+
+// Next returns the next point of the walk, or false once every point has been
+// visited.
+func (it *PointIterator) Next() (*big.Int, bool) {
+
+	if it.pos >= it.perm.Size() {
+		return nil, false
+	}
+
+	// pos is always within the permutation's domain.
+	i, _ := it.perm.Permute(it.pos)
+	it.pos++
+
+	return big.NewInt(0).SetUint64(it.base + i*it.stride), true
+}
+
+// NextPatp returns the next point of the walk as a @p, or false once every
+// point has been visited.
+func (it *PointIterator) NextPatp() (string, bool) {
+
+	point, ok := it.Next()
+	if !ok {
+		return "", false
+	}
+
+	// Every point of the walk fits in 64 bits, which Patp always accepts.
+	p, _ := Patp(point)
+
+	return p, true
+}
+
+// Cursor returns the current position of the walk.
+func (it *PointIterator) Cursor() Cursor {
+
+	return Cursor{
+		Base:        it.base,
+		Stride:      it.stride,
+		Count:       it.perm.Size(),
+		Position:    it.pos,
+		Fingerprint: it.perm.Fingerprint(),
+	}
+}
+
+// Resume moves the iterator to a position saved with Cursor. The cursor must
+// come from an iterator over the same points with the same key.
+func (it *PointIterator) Resume(c Cursor) error {
+
+	want := it.Cursor()
+	want.Position = c.Position
+	if c != want || c.Position > c.Count {
+		return fmt.Errorf(ugi.ErrCursorMismatch, c)
+	}
+
+	it.pos = c.Position
+
+	return nil
+}
+
+// String renders the cursor in the form read by ParseCursor.
+func (c Cursor) String() string {
+
+	return fmt.Sprintf("%x.%x.%x.%x.%08x", c.Base, c.Stride, c.Count, c.Position, c.Fingerprint)
+}
+
+// ParseCursor parses a cursor rendered by Cursor.String.
+func ParseCursor(s string) (Cursor, error) {
+
+	var c Cursor
+
+	parts := strings.Split(s, ".")
+	if len(parts) != 5 {
+		return c, fmt.Errorf(ugi.ErrInvalidCursor, s)
+	}
+
+	fields := []*uint64{&c.Base, &c.Stride, &c.Count, &c.Position}
+	for i, field := range fields {
+		v, err := strconv.ParseUint(parts[i], 16, 64)
+		if err != nil {
+			return c, fmt.Errorf(ugi.ErrInvalidCursor, s)
+		}
+		*field = v
+	}
+
+	fp, err := strconv.ParseUint(parts[4], 16, 32)
+	if err != nil {
+		return c, fmt.Errorf(ugi.ErrInvalidCursor, s)
+	}
+	c.Fingerprint = uint32(fp)
+
+	return c, nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c Cursor) MarshalText() ([]byte, error) {
+
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *Cursor) UnmarshalText(text []byte) error {
+
+	parsed, err := ParseCursor(string(text))
+	if err != nil {
+		return err
+	}
+
+	*c = parsed
+
+	return nil
+}
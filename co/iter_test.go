@@ -0,0 +1,150 @@
+package co
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanetIterator(t *testing.T) {
+	it, err := NewPlanetIterator("~marzod", []byte("secret"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(65535), it.Len())
+
+	name, ok := it.NextPatp()
+	require.True(t, ok)
+	sein, err := Sein(name)
+	require.NoError(t, err)
+	assert.Equal(t, "~marzod", sein)
+
+	seen := map[uint64]bool{}
+	sequential := 0
+	var prev uint64
+	for point, ok := it.Next(); ok; point, ok = it.Next() {
+		v := point.Uint64()
+		assert.Equal(t, uint64(256), v&0xffff, "%d is not a planet of ~marzod", v)
+		assert.True(t, v > 0xffff && v <= 0xffffffff, "%d is not a planet", v)
+		assert.False(t, seen[v], "planet %d visited twice", v)
+		seen[v] = true
+
+		if v == prev+0x10000 {
+			sequential++
+		}
+		prev = v
+	}
+
+	assert.Len(t, seen, 65534)
+	assert.Equal(t, uint64(0), it.Remaining())
+	assert.Less(t, sequential, 10, "the order should not follow the points")
+}
+
+func TestPlanetIteratorErrors(t *testing.T) {
+	_, err := NewPlanetIterator("~zod", nil)
+	assert.EqualError(t, err, "not a star: ~zod")
+
+	_, err = NewPlanetIterator("~dapnep-ronmyl", nil)
+	assert.EqualError(t, err, "not a star: ~dapnep-ronmyl")
+
+	_, err = NewPlanetIterator("abcdefg", nil)
+	assert.EqualError(t, err, "invalid @p: abcdefg")
+}
+
+func TestPointIterator(t *testing.T) {
+	lo, hi := big.NewInt(0x100000000), big.NewInt(0x100000000+999)
+	it, err := NewPointIterator(lo, hi, []byte("secret"))
+	require.NoError(t, err)
+
+	seen := map[uint64]bool{}
+	for point, ok := it.Next(); ok; point, ok = it.Next() {
+		v := point.Uint64()
+		assert.True(t, v >= 0x100000000 && v < 0x100000000+1000, "%d is out of range", v)
+		assert.False(t, seen[v])
+		seen[v] = true
+	}
+	assert.Len(t, seen, 1000)
+
+	_, err = NewPointIterator(hi, lo, nil)
+	assert.Error(t, err)
+
+	_, err = NewPointIterator(big.NewInt(-1), lo, nil)
+	assert.Error(t, err)
+
+	tooBig, _ := big.NewInt(0).SetString("10000000000000000", 16)
+	_, err = NewPointIterator(lo, tooBig, nil)
+	assert.Error(t, err)
+}
+
+func TestIteratorResume(t *testing.T) {
+	key := []byte("secret")
+	full, err := NewPlanetIterator("~wanzod", key)
+	require.NoError(t, err)
+
+	var want []*big.Int
+	for point, ok := full.Next(); ok; point, ok = full.Next() {
+		want = append(want, point)
+	}
+
+	// Walk part of the way, save the cursor through JSON and continue from it
+	// with a new iterator.
+	first, err := NewPlanetIterator("~wanzod", key)
+	require.NoError(t, err)
+
+	var got []*big.Int
+	for i := 0; i < 1234; i++ {
+		point, ok := first.Next()
+		require.True(t, ok)
+		got = append(got, point)
+	}
+
+	saved, err := json.Marshal(map[string]Cursor{"cursor": first.Cursor()})
+	require.NoError(t, err)
+
+	var loaded map[string]Cursor
+	require.NoError(t, json.Unmarshal(saved, &loaded))
+
+	second, err := NewPlanetIterator("~wanzod", key)
+	require.NoError(t, err)
+	require.NoError(t, second.Resume(loaded["cursor"]))
+	assert.Equal(t, uint64(65535-1234), second.Remaining())
+
+	for point, ok := second.Next(); ok; point, ok = second.Next() {
+		got = append(got, point)
+	}
+
+	assert.Equal(t, want, got)
+}
+
+func TestIteratorResumeMismatch(t *testing.T) {
+	it, err := NewPlanetIterator("~marzod", []byte("secret"))
+	require.NoError(t, err)
+	it.Next()
+	c := it.Cursor()
+
+	otherKey, err := NewPlanetIterator("~marzod", []byte("other"))
+	require.NoError(t, err)
+	assert.Error(t, otherKey.Resume(c))
+
+	otherStar, err := NewPlanetIterator("~wanzod", []byte("secret"))
+	require.NoError(t, err)
+	assert.Error(t, otherStar.Resume(c))
+
+	c.Position = c.Count + 1
+	assert.Error(t, it.Resume(c))
+}
+
+func TestParseCursor(t *testing.T) {
+	c := Cursor{Base: 0x10100, Stride: 0x10000, Count: 0xffff, Position: 42, Fingerprint: 0xdeadbeef}
+	assert.Equal(t, "10100.10000.ffff.2a.deadbeef", c.String())
+
+	parsed, err := ParseCursor(c.String())
+	require.NoError(t, err)
+	assert.Equal(t, c, parsed)
+
+	for _, s := range []string{"", "1.2.3.4", "1.2.3.4.5.6", "x.2.3.4.5", "1.2.3.4.100000000"} {
+		_, err := ParseCursor(s)
+		assert.EqualError(t, err, "invalid cursor: "+s)
+	}
+}
@@ -0,0 +1,95 @@
+package co
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/deelawn/urbit-gob/aura"
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+// pointBase describes one of the notations accepted by ParsePoint.
+type pointBase struct {
+	prefix string
+	base   int
+	digits string
+	// aura and dotted name and read Urbit's dotted form of the notation.
+	aura   string
+	dotted func(string) (*big.Int, error)
+}
+
+var (
+	pointHex = pointBase{"0x", 16, "0123456789abcdef", "ux", aura.ParseUx}
+	pointBin = pointBase{"0b", 2, "01", "ub", aura.ParseUb}
+	pointDec = pointBase{"", 10, "0123456789", "ud", aura.ParseUd}
+)
+
+// ParsePoint parses a point written in any of the common notations:
+//
+//	decimal   65536        1.000.000
+//	hex       0x10000      0x1.0000
+//	binary    0b10000      0b1.0000
+//
+// The dotted forms are Urbit's @ud, @ux and @ub notations, in which digits are
+// grouped in threes (decimal) or fours (hex and binary) from the right and the
+// first group has no leading zeros. Points may be of any size.
+//
+// Input that could reasonably be read more than one way, such as a decimal with
+// leading zeros (octal in many languages) or hex digits without a 0x prefix,
+// is rejected rather than guessed at.
+func ParsePoint(s string) (*big.Int, error) {
+
+	in := strings.TrimSpace(s)
+	lower := strings.ToLower(in)
+
+	switch {
+	case in == "":
+		return nil, fmt.Errorf(ugi.ErrInvalidPoint, s, "empty")
+	case strings.HasPrefix(in, "-"):
+		return nil, fmt.Errorf(ugi.ErrInvalidPoint, s, "points cannot be negative")
+	case strings.HasPrefix(lower, pointHex.prefix):
+		return parsePoint(s, lower[2:], pointHex)
+	case strings.HasPrefix(lower, pointBin.prefix):
+		return parsePoint(s, lower[2:], pointBin)
+	}
+
+	if strings.Trim(lower, pointDec.digits+".") != "" {
+		if strings.Trim(lower, pointHex.digits+".") == "" {
+			return nil, fmt.Errorf(ugi.ErrAmbiguousPoint, s, "hex digits need a 0x prefix")
+		}
+		return nil, fmt.Errorf(ugi.ErrInvalidPoint, s, "not a decimal, 0x hex or 0b binary number")
+	}
+
+	if len(in) > 1 && in[0] == '0' && !strings.Contains(in, ".") {
+		return nil, fmt.Errorf(ugi.ErrAmbiguousPoint, s, "leading zeros; write it without them, or with 0x for hex")
+	}
+
+	return parsePoint(s, in, pointDec)
+}
+
+// parsePoint parses the digits of s, with any prefix removed, in base b.
+func parsePoint(s, digits string, b pointBase) (*big.Int, error) {
+
+	if digits == "" {
+		return nil, fmt.Errorf(ugi.ErrInvalidPoint, s, "no digits")
+	}
+	if strings.Trim(digits, b.digits+".") != "" {
+		return nil, fmt.Errorf(ugi.ErrInvalidPoint, s, fmt.Sprintf("not a base %d number", b.base))
+	}
+
+	if strings.Contains(digits, ".") {
+		v, err := b.dotted(b.prefix + digits)
+		if err != nil {
+			return nil, fmt.Errorf(ugi.ErrInvalidPoint, s, "digits are not grouped as in a @"+b.aura)
+		}
+		return canonical(v), nil
+	}
+
+	v, ok := big.NewInt(0).SetString(digits, b.base)
+	if !ok {
+		return nil, fmt.Errorf(ugi.ErrInvalidPoint, s, fmt.Sprintf("not a base %d number", b.base))
+	}
+
+	return canonical(v), nil
+}
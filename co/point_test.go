@@ -0,0 +1,72 @@
+package co
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePoint(t *testing.T) {
+	comet, _ := big.NewInt(0).SetString("ffffffffffffffffffffffffffffffff", 16)
+
+	tests := []struct {
+		in   string
+		want *big.Int
+	}{
+		{"0", big.NewInt(0)},
+		{"65536", big.NewInt(65536)},
+		{" 65536\t", big.NewInt(65536)},
+		{"1.000.000", big.NewInt(1000000)},
+		{"65.536", big.NewInt(65536)},
+		{"340282366920938463463374607431768211455", comet},
+		{"340.282.366.920.938.463.463.374.607.431.768.211.455", comet},
+		{"0x0", big.NewInt(0)},
+		{"0x10000", big.NewInt(65536)},
+		{"0x00010000", big.NewInt(65536)},
+		{"0x1.0000", big.NewInt(65536)},
+		{"0X1.FFFF", big.NewInt(131071)},
+		{"0XFF", big.NewInt(255)},
+		{"0xffff.ffff.ffff.ffff.ffff.ffff.ffff.ffff", comet},
+		{"0b0", big.NewInt(0)},
+		{"0b101", big.NewInt(5)},
+		{"0b1.0000.0000", big.NewInt(256)},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePoint(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+}
+
+func TestParsePointErrors(t *testing.T) {
+	tests := []struct {
+		in  string
+		err string
+	}{
+		{"", `invalid point "": empty`},
+		{"-1", `invalid point "-1": points cannot be negative`},
+		{"0x", `invalid point "0x": no digits`},
+		{"0xfg", `invalid point "0xfg": not a base 16 number`},
+		{"0b102", `invalid point "0b102": not a base 2 number`},
+		{"~zod", `invalid point "~zod": not a decimal, 0x hex or 0b binary number`},
+		{"1,000", `invalid point "1,000": not a decimal, 0x hex or 0b binary number`},
+		{"ff", `ambiguous point "ff": hex digits need a 0x prefix`},
+		{"0123", `ambiguous point "0123": leading zeros; write it without them, or with 0x for hex`},
+		{"1.00", `invalid point "1.00": digits are not grouped as in a @ud`},
+		{"1000.000", `invalid point "1000.000": digits are not grouped as in a @ud`},
+		{"01.000", `invalid point "01.000": digits are not grouped as in a @ud`},
+		{".000", `invalid point ".000": digits are not grouped as in a @ud`},
+		{"1.000.", `invalid point "1.000.": digits are not grouped as in a @ud`},
+		{"0x1.000", `invalid point "0x1.000": digits are not grouped as in a @ux`},
+		{"0x01.0000", `invalid point "0x01.0000": digits are not grouped as in a @ux`},
+		{"0b10.000", `invalid point "0b10.000": digits are not grouped as in a @ub`},
+	}
+
+	for _, tt := range tests {
+		_, err := ParsePoint(tt.in)
+		assert.EqualError(t, err, tt.err, tt.in)
+	}
+}
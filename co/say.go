@@ -0,0 +1,208 @@
+package co
+
+import (
+	"fmt"
+	"strings"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+// Pronunciation is how a syllable is said, in the International Phonetic
+// Alphabet and respelled for readers of English.
+//
+// Every syllable is a consonant, a vowel and a consonant, each said as in
+// English with the short vowel of "cat", "bet", "bit", "cot" or "cut"; y is
+// said as i and c as k. Some prefixes then sound like suffixes, such as
+// dys and dis, but since prefixes and suffixes alternate in a name it can
+// still be heard unambiguously.
+type Pronunciation struct {
+	Syllable   string
+	IPA        string
+	Respelling string
+}
+
+var (
+	ipaSounds = map[byte]string{
+		'a': "æ", 'e': "ɛ", 'i': "ɪ", 'o': "ɑ", 'u': "ʌ", 'y': "ɪ",
+		'c': "k", 'g': "ɡ", 'x': "ks",
+	}
+	respelledSounds = map[byte]string{
+		'y': "i", 'c': "k", 'x': "ks",
+	}
+
+	// Alphabet is the spelling alphabet Spell uses, the ICAO one, indexed by
+	// letter from a.
+	Alphabet = []string{
+		"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf",
+		"hotel", "india", "juliet", "kilo", "lima", "mike", "november",
+		"oscar", "papa", "quebec", "romeo", "sierra", "tango", "uniform",
+		"victor", "whiskey", "xray", "yankee", "zulu",
+	}
+
+	pronunciations = map[string]Pronunciation{}
+)
+
+func init() {
+
+	for _, syls := range [][]string{Prefixes, Suffixes} {
+		for _, syl := range syls {
+			p := Pronunciation{Syllable: syl}
+			for i := 0; i < len(syl); i++ {
+				p.IPA += sound(ipaSounds, syl[i])
+				p.Respelling += sound(respelledSounds, syl[i])
+			}
+			pronunciations[syl] = p
+		}
+	}
+}
+
+func sound(sounds map[byte]string, c byte) string {
+
+	if s, ok := sounds[c]; ok {
+		return s
+	}
+
+	return string(c)
+}
+
+// Pronounce returns the pronunciation of a prefix or suffix.
+func Pronounce(syllable string) (Pronunciation, error) {
+
+	p, ok := pronunciations[syllable]
+	if !ok {
+		return Pronunciation{}, fmt.Errorf(ugi.ErrInvalidSyllable, syllable)
+	}
+
+	return p, nil
+}
+
+// Pronunciations returns the pronunciations of the 256 prefixes, then the 256
+// suffixes, in the order of Prefixes and Suffixes.
+func Pronunciations() []Pronunciation {
+
+	ps := make([]Pronunciation, 0, len(Prefixes)+len(Suffixes))
+	for _, syls := range [][]string{Prefixes, Suffixes} {
+		for _, syl := range syls {
+			ps = append(ps, pronunciations[syl])
+		}
+	}
+
+	return ps
+}
+
+// spokenWords splits a @p or @q into groups of words, each a list of its
+// syllables. Only comets have more than one group, split at the double dash.
+func spokenWords(name string) ([][][]string, error) {
+
+	// IsValidPatq allows dashes anywhere, so a @q must be in the form Patq
+	// gives.
+	valid := IsValidPatp(name)
+	if !valid {
+		if n, err := Patq2Dec(name); err == nil {
+			q, err := Patq(n)
+			valid = err == nil && q == name && IsValidPatq(name)
+		}
+	}
+	if !valid {
+		return nil, fmt.Errorf(ugi.ErrInvalidName, name)
+	}
+
+	var groups [][][]string
+	for _, g := range strings.Split(strings.TrimPrefix(name, "~"), "--") {
+		var words [][]string
+		for _, w := range strings.Split(g, "-") {
+			words = append(words, patp2syls(w))
+		}
+		groups = append(groups, words)
+	}
+
+	return groups, nil
+}
+
+// Say renders a @p or @q as a phrase to be read aloud, respelled for readers
+// of English with the stressed first syllable of each word in capitals, such
+// as "SAM-pel PAL-net" for ~sampel-palnet. The halves of a comet are split
+// by a comma.
+func Say(name string) (string, error) {
+
+	groups, err := spokenWords(name)
+	if err != nil {
+		return "", err
+	}
+
+	return joinSpoken(groups, ", ", " ", func(i int, syl string) string {
+
+		r := pronunciations[syl].Respelling
+		if i == 0 {
+			return strings.ToUpper(r)
+		}
+		return "-" + r
+	}), nil
+}
+
+// SayIPA renders a @p or @q in the International Phonetic Alphabet, such as
+// /ˈsæm.pɛl ˈpæl.nɛt/ for ~sampel-palnet. The halves of a comet are split by
+// a minor break, |.
+func SayIPA(name string) (string, error) {
+
+	groups, err := spokenWords(name)
+	if err != nil {
+		return "", err
+	}
+
+	return "/" + joinSpoken(groups, " | ", " ", func(i int, syl string) string {
+
+		if i == 0 {
+			return "ˈ" + pronunciations[syl].IPA
+		}
+		return "." + pronunciations[syl].IPA
+	}) + "/", nil
+}
+
+// Spell spells a @p or @q out letter by letter in the spelling alphabet, with
+// the letters of a syllable joined by dashes, such as "sierra-alpha-mike
+// papa-echo-lima, papa-alpha-lima november-echo-tango" for ~sampel-palnet.
+// Syllables are split by spaces, words by commas and the halves of a comet by
+// a semicolon. The tilde is left out.
+func Spell(name string) (string, error) {
+
+	groups, err := spokenWords(name)
+	if err != nil {
+		return "", err
+	}
+
+	return joinSpoken(groups, "; ", ", ", func(i int, syl string) string {
+
+		letters := make([]string, len(syl))
+		for j := 0; j < len(syl); j++ {
+			letters[j] = Alphabet[syl[j]-'a']
+		}
+		s := strings.Join(letters, "-")
+		if i > 0 {
+			s = " " + s
+		}
+		return s
+	}), nil
+}
+
+// joinSpoken renders each syllable of groups with say, given its place in its
+// word, and joins the words with wordSep and the groups with groupSep.
+func joinSpoken(groups [][][]string, groupSep, wordSep string, say func(int, string) string) string {
+
+	var b strings.Builder
+	for i, words := range groups {
+		if i > 0 {
+			b.WriteString(groupSep)
+		}
+		for j, syls := range words {
+			if j > 0 {
+				b.WriteString(wordSep)
+			}
+			for k, syl := range syls {
+				b.WriteString(say(k, syl))
+			}
+		}
+	}
+
+	return b.String()
+}
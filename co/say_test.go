@@ -0,0 +1,104 @@
+package co
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPronounce(t *testing.T) {
+	tests := []struct {
+		syllable string
+		want     Pronunciation
+	}{
+		{"zod", Pronunciation{"zod", "zɑd", "zod"}},
+		{"sam", Pronunciation{"sam", "sæm", "sam"}},
+		{"nec", Pronunciation{"nec", "nɛk", "nek"}},
+		{"lyx", Pronunciation{"lyx", "lɪks", "liks"}},
+		{"tug", Pronunciation{"tug", "tʌɡ", "tug"}},
+		{"fip", Pronunciation{"fip", "fɪp", "fip"}},
+	}
+
+	for _, tt := range tests {
+		got, err := Pronounce(tt.syllable)
+		require.NoError(t, err, tt.syllable)
+		assert.Equal(t, tt.want, got)
+	}
+
+	for _, s := range []string{"", "zo", "zodd", "abc", "ZOD"} {
+		_, err := Pronounce(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestPronunciations(t *testing.T) {
+	ps := Pronunciations()
+	require.Len(t, ps, 512)
+	assert.Equal(t, "doz", ps[0].Syllable)
+	assert.Equal(t, "zod", ps[256].Syllable)
+
+	// Within the prefixes, and within the suffixes, no two syllables sound
+	// the same.
+	for _, half := range [][]Pronunciation{ps[:256], ps[256:]} {
+		seen := map[string]string{}
+		for _, p := range half {
+			assert.NotEmpty(t, p.IPA)
+			assert.NotEmpty(t, p.Respelling)
+			if other, ok := seen[p.IPA]; ok {
+				t.Errorf("%s and %s both sound like /%s/", other, p.Syllable, p.IPA)
+			}
+			seen[p.IPA] = p.Syllable
+		}
+	}
+}
+
+func TestSay(t *testing.T) {
+	tests := []struct {
+		name  string
+		say   string
+		ipa   string
+		spell string
+	}{
+		{"~zod", "ZOD", "/ˈzɑd/", "zulu-oscar-delta"},
+		{"~marzod", "MAR-zod", "/ˈmær.zɑd/", "mike-alpha-romeo zulu-oscar-delta"},
+		{"~sampel-palnet", "SAM-pel PAL-net", "/ˈsæm.pɛl ˈpæl.nɛt/",
+			"sierra-alpha-mike papa-echo-lima, papa-alpha-lima november-echo-tango"},
+		{"~doznec-dozzod", "DOZ-nek DOZ-zod", "/ˈdɑz.nɛk ˈdɑz.zɑd/",
+			"delta-oscar-zulu november-echo-charlie, delta-oscar-zulu zulu-oscar-delta"},
+		{"~fipfes-fipfes-fipfes-fipfes--dozzod-dozzod-dozzod-doznec",
+			"FIP-fes FIP-fes FIP-fes FIP-fes, DOZ-zod DOZ-zod DOZ-zod DOZ-nek",
+			"/ˈfɪp.fɛs ˈfɪp.fɛs ˈfɪp.fɛs ˈfɪp.fɛs | ˈdɑz.zɑd ˈdɑz.zɑd ˈdɑz.zɑd ˈdɑz.nɛk/", ""},
+	}
+
+	for _, tt := range tests {
+		say, err := Say(tt.name)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.say, say)
+
+		ipa, err := SayIPA(tt.name)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.ipa, ipa)
+
+		spell, err := Spell(tt.name)
+		require.NoError(t, err, tt.name)
+		if tt.spell != "" {
+			assert.Equal(t, tt.spell, spell)
+		}
+	}
+
+	spell, err := Spell("~fipfes-fipfes-fipfes-fipfes--dozzod-dozzod-dozzod-doznec")
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(spell, "foxtrot-echo-sierra; delta-oscar-zulu zulu-oscar-delta, "+
+		"delta-oscar-zulu zulu-oscar-delta, delta-oscar-zulu zulu-oscar-delta, delta-oscar-zulu november-echo-charlie"), spell)
+
+	for _, s := range []string{"", "zod", "~zodd", "~sam", "~sampelpalnet", "~lyxmex"} {
+		_, err := Say(s)
+		assert.Error(t, err, s)
+		_, err = SayIPA(s)
+		assert.Error(t, err, s)
+		_, err = Spell(s)
+		assert.Error(t, err, s)
+	}
+}
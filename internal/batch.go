@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// batchBlock is the number of consecutive items a worker claims at a time.
+const batchBlock = 256
+
+// ForEach calls fn for every index in [0, n) using up to workers goroutines,
+// or the calling goroutine alone if workers is less than two. Once ctx is done
+// no further items are started and ForEach returns ctx.Err().
+//
+// The returned slice is nil if fn succeeded for every index; otherwise it
+// holds an error per index, nil for the items that succeeded and ctx.Err()
+// for those that were never processed.
+func ForEach(ctx context.Context, n, workers int, fn func(i int) error) ([]error, error) {
+
+	var (
+		errs   []error
+		errsMu sync.Mutex
+		next   int64
+		done   = make([]bool, n)
+	)
+
+	fail := func(i int, err error) {
+		errsMu.Lock()
+		defer errsMu.Unlock()
+		if errs == nil {
+			errs = make([]error, n)
+		}
+		errs[i] = err
+	}
+
+	work := func() {
+		for ctx.Err() == nil {
+			start := int(atomic.AddInt64(&next, batchBlock)) - batchBlock
+			if start >= n {
+				return
+			}
+			end := start + batchBlock
+			if end > n {
+				end = n
+			}
+
+			for i := start; i < end; i++ {
+				if err := fn(i); err != nil {
+					fail(i, err)
+				}
+				done[i] = true
+			}
+		}
+	}
+
+	if workers < 2 {
+		work()
+	} else {
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				work()
+			}()
+		}
+		wg.Wait()
+	}
+
+	if err := ctx.Err(); err != nil {
+		for i, ok := range done {
+			if !ok {
+				fail(i, err)
+			}
+		}
+		return errs, err
+	}
+
+	return errs, nil
+}
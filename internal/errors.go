@@ -8,4 +8,55 @@ const (
 	ErrInvalidP   string = "invalid @p: %s"
 	ErrInvalidQ   string = "invalid @q: %s"
 	ErrInvalidI   string = "invalid integer: %s"
+
+	ErrInvalidName     string = "invalid @p or @q: %s"
+	ErrInvalidSyllable string = "invalid syllable: %s"
+
+	// ErrOutOfDomain takes the function name, the value and the inclusive bounds
+	// of the domain the function is defined on.
+	ErrOutOfDomain string = "%s: %v is outside the domain [%v, %v]"
+
+	// ErrNegativePoint takes the function name and the value given.
+	ErrNegativePoint string = "%s: invalid integer %v: a point must be non-negative (comets above 2^64 are allowed)"
+
+	ErrInvalidCursor  string = "invalid cursor: %s"
+	ErrCursorMismatch string = "cursor %s does not belong to this iterator"
+	ErrNotStar        string = "not a star: %s"
+	ErrInvalidRange   string = "invalid range: %s"
+
+	// ErrNoChildren takes the class and point of a ship that sponsors none.
+	ErrNoChildren string = "a %s sponsors no ships: %v"
+
+	// ErrBatchLength takes the function name and the lengths of dst and src.
+	ErrBatchLength string = "%s: dst has %d items, need at least %d"
+
+	// ErrInvalidPoint and ErrAmbiguousPoint take the input and the reason.
+	ErrInvalidPoint   string = "invalid point %q: %s"
+	ErrAmbiguousPoint string = "ambiguous point %q: %s"
+
+	// ErrInvalidLevel takes the level given. ErrQRTooLong takes the length of
+	// the text in bytes and the error correction level.
+	ErrInvalidLevel string = "invalid error correction level: %v (want L, M, Q or H)"
+	ErrQRTooLong    string = "%d bytes is too long for a QR code at level %s"
+
+	ErrInvalidSymbols   string = "invalid sigil symbols: %v"
+	ErrNoSymbol         string = "no sigil symbol for syllable %s"
+	ErrNoBuiltinSymbols string = "the sigil symbols are not built in: run go generate in the sigil package"
+
+	// ErrInvalidPath takes the path data and the offset of the error.
+	ErrInvalidPath      string = "invalid path data %q at offset %d"
+	ErrInvalidColour    string = "invalid colour: %s"
+	ErrInvalidTransform string = "invalid transform: %s"
+
+	// ErrInvalidAttribute takes the name and value of the attribute.
+	ErrInvalidAttribute string = "invalid %s attribute: %q"
+
+	// ErrInvalidSigilOptions takes the size and margin.
+	ErrInvalidSigilOptions string = "invalid sigil options: size %v, margin %v (want a size above 0 and a margin in [0, 0.5))"
+
+	// ErrInvalidAura takes the aura and the text given for it.
+	ErrInvalidAura string = "invalid @%s: %q"
+
+	// ErrAuraRange takes a value and what it cannot be represented as.
+	ErrAuraRange string = "%v is outside the range of %s"
 )
@@ -0,0 +1,31 @@
+// Package sigiltest builds sigil symbol files for tests.
+package sigiltest
+
+import (
+	"strings"
+
+	"github.com/deelawn/urbit-gob/co"
+)
+
+// Symbols returns a symbols file, as read by sigil.LoadSet, giving each
+// syllable the element tree in JSON that symbol returns for it, or leaving
+// it out if symbol returns "".
+func Symbols(symbol func(syl string) string) string {
+
+	var entries []string
+	for _, list := range [][]string{co.Prefixes, co.Suffixes} {
+		for _, syl := range list {
+			if s := symbol(syl); s != "" {
+				entries = append(entries, `"`+syl+`":`+s)
+			}
+		}
+	}
+
+	return "{" + strings.Join(entries, ",") + "}"
+}
+
+// Same returns a symbol function giving every syllable the same symbol.
+func Same(symbol string) func(syl string) string {
+
+	return func(string) string { return symbol }
+}
@@ -0,0 +1,166 @@
+/*
+Package analysis measures how well the @p scrambler hides the sequence of
+points behind their names.
+
+Three properties are measured over planets, the only points that ob.Fein
+scrambles:
+
+  - Avalanche: flipping a single input bit should flip each output bit with
+    probability 1/2.
+  - Syllable distribution: each of the four syllables of a planet name should
+    take each of its 256 values equally often.
+  - Adjacent similarity: the names of consecutive points should share no more
+    syllables, and be no closer in spelling, than the names of unrelated ones.
+
+Each analysis runs over random samples or, with Options.Full set, over every
+point in the range.
+*/
+package analysis
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+
+	"github.com/deelawn/urbit-gob/co"
+	"github.com/deelawn/urbit-gob/ob"
+)
+
+const (
+	// PlanetMin and PlanetMax bound the points scrambled by ob.Fein32.
+	PlanetMin uint64 = 0x10000
+	PlanetMax uint64 = 0xffffffff
+
+	// DefaultSamples is the number of samples taken by Analyze when
+	// Options.Samples is zero or negative.
+	DefaultSamples = 100000
+)
+
+// Options selects the points analysed.
+type Options struct {
+	// Lo and Hi bound the analysed points, [Lo, Hi]. Both default to the
+	// bounds of the planet range and must lie within it.
+	Lo, Hi uint64
+	// Samples is the number of random points to analyse. Zero or less means
+	// DefaultSamples, so the zero Options samples the whole planet range.
+	Samples int
+	// Full analyses every point in the range once instead of sampling, and
+	// Samples is then ignored.
+	Full bool
+	// Seed seeds the random sampling, so that reports are reproducible.
+	Seed int64
+	// Workers is the number of goroutines to spread the work across. Zero or
+	// less means runtime.NumCPU().
+	Workers int
+}
+
+func (o Options) normalize() (Options, error) {
+
+	if o.Lo == 0 && o.Hi == 0 {
+		o.Lo, o.Hi = PlanetMin, PlanetMax
+	}
+	if o.Lo < PlanetMin || o.Hi > PlanetMax || o.Lo > o.Hi {
+		return o, fmt.Errorf("invalid range [%#x, %#x]: must be within the planets [%#x, %#x]",
+			o.Lo, o.Hi, PlanetMin, PlanetMax)
+	}
+	if o.Samples <= 0 {
+		o.Samples = DefaultSamples
+	}
+	if o.Workers <= 0 {
+		o.Workers = runtime.NumCPU()
+	}
+
+	return o, nil
+}
+
+// size returns the number of points analysed.
+func (o Options) size() uint64 {
+
+	if !o.Full {
+		return uint64(o.Samples)
+	}
+
+	return o.Hi - o.Lo + 1
+}
+
+// Report combines the results of all three analyses.
+type Report struct {
+	Avalanche  AvalancheReport
+	Syllables  SyllableReport
+	Similarity SimilarityReport
+}
+
+// Analyze runs every analysis with the same options.
+func Analyze(opts Options) (Report, error) {
+
+	var (
+		r   Report
+		err error
+	)
+
+	if r.Avalanche, err = Avalanche(opts); err != nil {
+		return r, err
+	}
+	if r.Syllables, err = Syllables(opts); err != nil {
+		return r, err
+	}
+	if r.Similarity, err = Similarity(opts); err != nil {
+		return r, err
+	}
+
+	return r, nil
+}
+
+// syllables returns the indices of the four syllables of the planet whose
+// scrambled value is y, in the order they are written: prefix, suffix,
+// prefix, suffix.
+func syllables(y uint32) [4]uint8 {
+
+	return [4]uint8{uint8(y >> 24), uint8(y >> 16), uint8(y >> 8), uint8(y)}
+}
+
+// name renders the scrambled value y as a planet name.
+func name(y uint32) string {
+
+	s := syllables(y)
+	return "~" + co.Prefixes[s[0]] + co.Suffixes[s[1]] + "-" + co.Prefixes[s[2]] + co.Suffixes[s[3]]
+}
+
+// scramble is the permutation under analysis.
+var scramble = ob.Fein32
+
+// forEach calls visit with every point selected by opts, spread across
+// opts.Workers goroutines. Each worker is handed its own index so that it can
+// accumulate results without locking; rng is that worker's source of
+// randomness, seeded deterministically from opts.Seed.
+func forEach(opts Options, visit func(worker int, rng *rand.Rand, x uint32)) {
+
+	var wg sync.WaitGroup
+
+	n := opts.size()
+	per := n / uint64(opts.Workers)
+	for w := 0; w < opts.Workers; w++ {
+		start := uint64(w) * per
+		end := start + per
+		if w == opts.Workers-1 {
+			end = n
+		}
+
+		wg.Add(1)
+		go func(w int, start, end uint64) {
+			defer wg.Done()
+
+			rng := rand.New(rand.NewSource(opts.Seed + int64(w)))
+			for i := start; i < end; i++ {
+				x := opts.Lo + i
+				if !opts.Full {
+					x = opts.Lo + uint64(rng.Int63n(int64(opts.Hi-opts.Lo+1)))
+				}
+				visit(w, rng, uint32(x))
+			}
+		}(w, start, end)
+	}
+
+	wg.Wait()
+}
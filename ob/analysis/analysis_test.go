@@ -0,0 +1,172 @@
+package analysis
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/deelawn/urbit-gob/co"
+)
+
+func TestNameMatchesPatp(t *testing.T) {
+	for _, x := range []uint32{0x10000, 14287616, 0xffffffff} {
+		want, err := co.Patp(strconv.FormatUint(uint64(x), 10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := name(scramble(x)); got != want {
+			t.Errorf("name(scramble(%d)) = %s, want %s", x, got, want)
+		}
+	}
+}
+
+func TestOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		opts  Options
+		valid bool
+	}{
+		{"defaults", Options{}, true},
+		{"sub-range", Options{Lo: 0x20000, Hi: 0x30000}, true},
+		{"stars", Options{Lo: 0x100, Hi: 0xffff}, false},
+		{"moons", Options{Lo: 0x10000, Hi: 0x100000000}, false},
+		{"reversed", Options{Lo: 0x30000, Hi: 0x20000}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.opts.normalize()
+			if (err == nil) != tc.valid {
+				t.Errorf("normalize() error = %v, want valid = %v", err, tc.valid)
+			}
+		})
+	}
+}
+
+func TestOptionsSamples(t *testing.T) {
+	testCases := []struct {
+		opts Options
+		size uint64
+	}{
+		{Options{}, DefaultSamples},
+		{Options{Samples: -1}, DefaultSamples},
+		{Options{Samples: 10}, 10},
+		{Options{Lo: 0x10000, Hi: 0x1ffff, Full: true}, 0x10000},
+		{Options{Lo: 0x10000, Hi: 0x1ffff, Samples: 10, Full: true}, 0x10000},
+	}
+
+	for _, tc := range testCases {
+		opts, err := tc.opts.normalize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := opts.size(); got != tc.size {
+			t.Errorf("%+v: size() = %d, want %d", tc.opts, got, tc.size)
+		}
+	}
+}
+
+func TestAvalanche(t *testing.T) {
+	r, err := Avalanche(Options{Samples: 20000, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Flips == 0 || r.Flips > 20000*32 {
+		t.Fatalf("unexpected number of flips: %d", r.Flips)
+	}
+	if math.Abs(r.MeanFlipRate-0.5) > 0.05 {
+		t.Errorf("mean flip rate = %v, want close to 0.5", r.MeanFlipRate)
+	}
+	if r.MaxBias < r.MeanBias || r.MaxBias > 0.5 {
+		t.Errorf("inconsistent biases: mean %v, max %v", r.MeanBias, r.MaxBias)
+	}
+
+	var total uint64
+	for _, n := range r.Distance {
+		total += n
+	}
+	if total != r.Flips || r.Distance[0] != 0 {
+		t.Errorf("distance histogram %v does not match %d flips", r.Distance, r.Flips)
+	}
+}
+
+func TestSyllables(t *testing.T) {
+	// Every point of a range of 2^16 consecutive planets.
+	r, err := Syllables(Options{Lo: 0x10000, Hi: 0x1ffff, Full: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Names != 0x10000 {
+		t.Fatalf("counted %d names, want %d", r.Names, 0x10000)
+	}
+	for pos, p := range r.Positions {
+		if p.Prefix != (pos%2 == 0) {
+			t.Errorf("position %d: Prefix = %v", pos, p.Prefix)
+		}
+		if p.PValue < 1e-6 {
+			t.Errorf("position %d looks biased: chi-square %v, p = %v", pos, p.ChiSquare, p.PValue)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	r, err := Similarity(Options{Samples: 20000, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Pairs != 20000 {
+		t.Fatalf("compared %d pairs, want 20000", r.Pairs)
+	}
+	for _, s := range []PairStats{r.Adjacent, r.Random} {
+		if s.MeanSharedSyllables > 0.1 {
+			t.Errorf("names share %v syllables on average, want close to 4/256", s.MeanSharedSyllables)
+		}
+	}
+	if math.Abs(r.Adjacent.MeanEditDistance-r.Random.MeanEditDistance) > 0.2 {
+		t.Errorf("adjacent names are %v edits apart, random ones %v",
+			r.Adjacent.MeanEditDistance, r.Random.MeanEditDistance)
+	}
+}
+
+func TestChiSquarePValue(t *testing.T) {
+	testCases := []struct {
+		x    float64
+		df   int
+		want float64
+	}{
+		{0, 255, 1},
+		{255, 255, 0.4883},
+		{293.25, 255, 0.05},
+		{310.46, 255, 0.01},
+		{2, 2, math.Exp(-1)},
+		{20, 2, math.Exp(-10)},
+	}
+
+	for _, tc := range testCases {
+		if got := chiSquarePValue(tc.x, tc.df); math.Abs(got-tc.want) > 1e-3 {
+			t.Errorf("chiSquarePValue(%v, %d) = %v, want %v", tc.x, tc.df, got, tc.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	testCases := []struct {
+		s, t string
+		want int
+	}{
+		{"", "", 0},
+		{"~zod", "~zod", 0},
+		{"~zod", "~nec", 3},
+		{"~dapnep-ronmyl", "~dapnep-ronmel", 1},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tc := range testCases {
+		if got := editDistance(tc.s, tc.t); got != tc.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tc.s, tc.t, got, tc.want)
+		}
+	}
+}
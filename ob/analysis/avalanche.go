@@ -0,0 +1,104 @@
+package analysis
+
+import (
+	"math"
+	"math/bits"
+	"math/rand"
+)
+
+// AvalancheReport describes how single-bit changes to a point propagate to
+// its scrambled value. An ideal scrambler flips every output bit with
+// probability 1/2 whatever the input bit.
+type AvalancheReport struct {
+	// Flips is the number of single-bit input changes measured. Flips that
+	// would leave the planet range are skipped.
+	Flips uint64
+	// Matrix[i][j] is the fraction of flips of input bit i that flipped
+	// output bit j.
+	Matrix [32][32]float64
+	// MeanFlipRate is the mean fraction of output bits flipped per flip.
+	MeanFlipRate float64
+	// MeanBias and MaxBias are the mean and largest distance of a Matrix
+	// entry from 1/2.
+	MeanBias float64
+	MaxBias  float64
+	// Distance[d] counts the flips that changed exactly d output bits.
+	Distance [33]uint64
+}
+
+type avalancheCounts struct {
+	flips    [32]uint64
+	matrix   [32][32]uint64
+	distance [33]uint64
+}
+
+// Avalanche flips each of the 32 bits of every selected point and measures
+// which bits of the scrambled value change.
+func Avalanche(opts Options) (AvalancheReport, error) {
+
+	var r AvalancheReport
+
+	opts, err := opts.normalize()
+	if err != nil {
+		return r, err
+	}
+
+	counts := make([]avalancheCounts, opts.Workers)
+	forEach(opts, func(w int, _ *rand.Rand, x uint32) {
+		c := &counts[w]
+		y := scramble(x)
+		for i := 0; i < 32; i++ {
+			x2 := x ^ 1<<uint(i)
+			if uint64(x2) < PlanetMin {
+				continue
+			}
+
+			diff := y ^ scramble(x2)
+			c.flips[i]++
+			c.distance[bits.OnesCount32(diff)]++
+			for j := 0; j < 32; j++ {
+				if diff&(1<<uint(j)) != 0 {
+					c.matrix[i][j]++
+				}
+			}
+		}
+	})
+
+	var total avalancheCounts
+	for _, c := range counts {
+		for i := range c.flips {
+			total.flips[i] += c.flips[i]
+			for j := range c.matrix[i] {
+				total.matrix[i][j] += c.matrix[i][j]
+			}
+		}
+		for d := range c.distance {
+			total.distance[d] += c.distance[d]
+		}
+	}
+
+	var flipped, entries float64
+	for i := range total.flips {
+		r.Flips += total.flips[i]
+		if total.flips[i] == 0 {
+			continue
+		}
+		for j := range total.matrix[i] {
+			p := float64(total.matrix[i][j]) / float64(total.flips[i])
+			r.Matrix[i][j] = p
+			bias := math.Abs(p - 0.5)
+			r.MeanBias += bias
+			r.MaxBias = math.Max(r.MaxBias, bias)
+			flipped += float64(total.matrix[i][j])
+			entries++
+		}
+	}
+
+	r.Distance = total.distance
+	if entries > 0 {
+		r.MeanBias /= entries
+		r.MeanFlipRate = flipped / float64(r.Flips) / 32
+	}
+
+	return r, nil
+}
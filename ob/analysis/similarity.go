@@ -0,0 +1,142 @@
+package analysis
+
+import (
+	"math/rand"
+)
+
+// SimilarityReport compares the names of adjacent points with the names of
+// randomly paired points. If the scrambler hides the sequence well, the two
+// sets of figures agree.
+type SimilarityReport struct {
+	Pairs uint64
+	// Adjacent describes the pairs (x, x+1), Random pairs x with a random
+	// planet.
+	Adjacent PairStats
+	Random   PairStats
+}
+
+// PairStats summarises the similarity of a set of name pairs.
+type PairStats struct {
+	// MeanSharedSyllables is the mean number of positions, out of four, in
+	// which the two names have the same syllable. Unrelated names share 4/256.
+	MeanSharedSyllables float64
+	// Shared[n] counts the pairs sharing exactly n syllables.
+	Shared [5]uint64
+	// MeanEditDistance is the mean Levenshtein distance between the two names.
+	MeanEditDistance float64
+}
+
+type pairCounts struct {
+	shared [5]uint64
+	edits  uint64
+}
+
+func (c *pairCounts) add(y1, y2 uint32) {
+
+	s1, s2 := syllables(y1), syllables(y2)
+	n := 0
+	for i := range s1 {
+		if s1[i] == s2[i] {
+			n++
+		}
+	}
+
+	c.shared[n]++
+	c.edits += uint64(editDistance(name(y1), name(y2)))
+}
+
+func (c pairCounts) stats(pairs uint64) PairStats {
+
+	s := PairStats{Shared: c.shared}
+	if pairs == 0 {
+		return s
+	}
+
+	var shared uint64
+	for n, count := range c.shared {
+		shared += uint64(n) * count
+	}
+
+	s.MeanSharedSyllables = float64(shared) / float64(pairs)
+	s.MeanEditDistance = float64(c.edits) / float64(pairs)
+
+	return s
+}
+
+// Similarity compares the name of each selected point with the name of the
+// next point, and with the name of a random planet.
+func Similarity(opts Options) (SimilarityReport, error) {
+
+	var r SimilarityReport
+
+	opts, err := opts.normalize()
+	if err != nil {
+		return r, err
+	}
+
+	adjacent := make([]pairCounts, opts.Workers)
+	random := make([]pairCounts, opts.Workers)
+	forEach(opts, func(w int, rng *rand.Rand, x uint32) {
+		next := x + 1
+		if uint64(x) == PlanetMax {
+			next = x - 1
+		}
+
+		other := uint32(PlanetMin + uint64(rng.Int63n(int64(PlanetMax-PlanetMin+1))))
+
+		y := scramble(x)
+		adjacent[w].add(y, scramble(next))
+		random[w].add(y, scramble(other))
+	})
+
+	var a, b pairCounts
+	for w := range adjacent {
+		for n := range a.shared {
+			a.shared[n] += adjacent[w].shared[n]
+			b.shared[n] += random[w].shared[n]
+		}
+		a.edits += adjacent[w].edits
+		b.edits += random[w].edits
+	}
+
+	r.Pairs = opts.size()
+	r.Adjacent = a.stats(r.Pairs)
+	r.Random = b.stats(r.Pairs)
+
+	return r, nil
+}
+
+// editDistance returns the Levenshtein distance between two ASCII strings.
+func editDistance(s, t string) int {
+
+	prev := make([]int, len(t)+1)
+	cur := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		cur[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(t)]
+}
+
+func minInt(a int, rest ...int) int {
+
+	for _, b := range rest {
+		if b < a {
+			a = b
+		}
+	}
+
+	return a
+}
@@ -0,0 +1,152 @@
+package analysis
+
+import (
+	"math"
+	"math/rand"
+)
+
+// SyllablePosition describes how often each syllable appears in one position
+// of a planet name.
+type SyllablePosition struct {
+	// Prefix is true for the first and third positions, which are drawn from
+	// co.Prefixes, and false for the suffix positions.
+	Prefix bool
+	// Counts[i] is the number of names with syllable i in this position.
+	Counts [256]uint64
+	// Min and Max are the smallest and largest entries of Counts.
+	Min, Max uint64
+	// ChiSquare is Pearson's statistic for Counts against a uniform
+	// distribution, with 255 degrees of freedom.
+	ChiSquare float64
+	// PValue is the probability of a statistic at least as large from truly
+	// uniform syllables. Very small values indicate a biased position.
+	PValue float64
+}
+
+// SyllableReport describes the distribution of syllables over planet names.
+type SyllableReport struct {
+	Names     uint64
+	Positions [4]SyllablePosition
+}
+
+// Syllables counts the syllables in each position of the names of the
+// selected points.
+func Syllables(opts Options) (SyllableReport, error) {
+
+	var r SyllableReport
+
+	opts, err := opts.normalize()
+	if err != nil {
+		return r, err
+	}
+
+	counts := make([][4][256]uint64, opts.Workers)
+	forEach(opts, func(w int, _ *rand.Rand, x uint32) {
+		for pos, syl := range syllables(scramble(x)) {
+			counts[w][pos][syl]++
+		}
+	})
+
+	for _, c := range counts {
+		for pos := range c {
+			for syl, n := range c[pos] {
+				r.Positions[pos].Counts[syl] += n
+			}
+		}
+	}
+
+	r.Names = opts.size()
+	for pos := range r.Positions {
+		p := &r.Positions[pos]
+		p.Prefix = pos%2 == 0
+		p.Min, p.Max = p.Counts[0], p.Counts[0]
+		for _, n := range p.Counts {
+			if n < p.Min {
+				p.Min = n
+			}
+			if n > p.Max {
+				p.Max = n
+			}
+		}
+		p.ChiSquare = chiSquare(p.Counts[:])
+		p.PValue = chiSquarePValue(p.ChiSquare, len(p.Counts)-1)
+	}
+
+	return r, nil
+}
+
+// chiSquare returns Pearson's statistic for counts against a uniform
+// distribution over the same total.
+func chiSquare(counts []uint64) float64 {
+
+	var total uint64
+	for _, n := range counts {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+
+	expected := float64(total) / float64(len(counts))
+	var x float64
+	for _, n := range counts {
+		d := float64(n) - expected
+		x += d * d / expected
+	}
+
+	return x
+}
+
+// chiSquarePValue returns P(X >= x) for X chi-square distributed with df
+// degrees of freedom, that is the regularised upper incomplete gamma function
+// Q(df/2, x/2).
+func chiSquarePValue(x float64, df int) float64 {
+
+	a, z := float64(df)/2, x/2
+	if z <= 0 {
+		return 1
+	}
+
+	lg, _ := math.Lgamma(a)
+	front := math.Exp(a*math.Log(z) - z - lg)
+
+	if z < a+1 {
+		// Series for the lower function P(a, z).
+		sum, term := 1/a, 1/a
+		for n := 1; n < 1000; n++ {
+			term *= z / (a + float64(n))
+			sum += term
+			if term < sum*1e-15 {
+				break
+			}
+		}
+		return 1 - front*sum
+	}
+
+	// Lentz's continued fraction for Q(a, z).
+	const tiny = 1e-300
+	b := z + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < 1000; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+
+	return front * h
+}
@@ -0,0 +1,97 @@
+package ob
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+// BatchOptions configures the batch functions.
+type BatchOptions struct {
+	// Workers is the number of goroutines to spread the work over. Zero or one
+	// does all the work in the calling goroutine.
+	Workers int
+}
+
+// FeinBatch applies Fein to every item of src and stores the results in dst,
+// which must be at least as long as src and may be src itself. Non-nil items
+// of dst are reused rather than reallocated.
+//
+// The returned slice is nil if every item succeeded; otherwise it holds the
+// error for each item, nil for those that succeeded. If ctx is done before all
+// items are processed, the rest are left untouched, their errors are set to
+// ctx.Err() and that error is also returned.
+func FeinBatch(ctx context.Context, dst, src []*big.Int, opts BatchOptions) ([]error, error) {
+
+	return batch(ctx, "FeinBatch", dst, src, opts, Fein64)
+}
+
+// FyndBatch applies Fynd to every item of src, as FeinBatch does for Fein.
+func FyndBatch(ctx context.Context, dst, src []*big.Int, opts BatchOptions) ([]error, error) {
+
+	return batch(ctx, "FyndBatch", dst, src, opts, Fynd64)
+}
+
+// FeinBatch64 applies Fein64 to every item of src and stores the results in
+// dst, which must be at least as long as src and may be src itself. It only
+// fails if dst is too short or ctx is done before all items are processed.
+func FeinBatch64(ctx context.Context, dst, src []uint64, opts BatchOptions) error {
+
+	return batch64(ctx, "FeinBatch64", dst, src, opts, Fein64)
+}
+
+// FyndBatch64 applies Fynd64 to every item of src, as FeinBatch64 does for
+// Fein64.
+func FyndBatch64(ctx context.Context, dst, src []uint64, opts BatchOptions) error {
+
+	return batch64(ctx, "FyndBatch64", dst, src, opts, Fynd64)
+}
+
+func batch(
+	ctx context.Context,
+	fn string,
+	dst, src []*big.Int,
+	opts BatchOptions,
+	f func(uint64) uint64,
+) ([]error, error) {
+
+	if len(dst) < len(src) {
+		return nil, fmt.Errorf(ugi.ErrBatchLength, fn, len(dst), len(src))
+	}
+
+	return ugi.ForEach(ctx, len(src), opts.Workers, func(i int) error {
+		if err := checkBig(fn, src[i], uxFFFFFFFFFFFFFFFF); err != nil {
+			return err
+		}
+
+		v := f(src[i].Uint64())
+		if dst[i] == nil {
+			dst[i] = big.NewInt(0)
+		}
+		dst[i].SetUint64(v)
+
+		return nil
+	})
+}
+
+func batch64(
+	ctx context.Context,
+	fn string,
+	dst, src []uint64,
+	opts BatchOptions,
+	f func(uint64) uint64,
+) error {
+
+	if len(dst) < len(src) {
+		return fmt.Errorf(ugi.ErrBatchLength, fn, len(dst), len(src))
+	}
+
+	_, err := ugi.ForEach(ctx, len(src), opts.Workers, func(i int) error {
+		dst[i] = f(src[i])
+		return nil
+	})
+
+	return err
+}
@@ -0,0 +1,114 @@
+package ob
+
+import (
+	"context"
+	"math/big"
+	"testing"
+)
+
+func TestFeinBatch(t *testing.T) {
+
+	src := make([]*big.Int, 5000)
+	for i := range src {
+		src[i] = big.NewInt(int64(i) * 0x10001)
+	}
+	src[42] = big.NewInt(-1)
+
+	for _, workers := range []int{0, 1, 4} {
+		opts := BatchOptions{Workers: workers}
+
+		dst := make([]*big.Int, len(src))
+		errs, err := FeinBatch(context.Background(), dst, src, opts)
+		if err != nil {
+			t.Fatalf("workers %d: unexpected error: %v", workers, err)
+		}
+		if len(errs) != len(src) {
+			t.Fatalf("workers %d: got %d errors, want one per item", workers, len(errs))
+		}
+
+		for i, v := range src {
+			if i == 42 {
+				if errs[i] == nil || dst[i] != nil {
+					t.Errorf("workers %d: item %d should have failed", workers, i)
+				}
+				continue
+			}
+			if errs[i] != nil {
+				t.Errorf("workers %d: item %d: %v", workers, i, errs[i])
+				continue
+			}
+			want, _ := Fein(v)
+			if dst[i].Cmp(want) != 0 {
+				t.Errorf("workers %d: Fein(%v) = %v, want %v", workers, v, dst[i], want)
+			}
+		}
+
+		// Undo the scrambling in place.
+		back := append([]*big.Int(nil), dst...)
+		back[42] = big.NewInt(0)
+		errs, err = FyndBatch(context.Background(), back, back, opts)
+		if err != nil || errs != nil {
+			t.Fatalf("workers %d: FyndBatch failed: %v %v", workers, err, errs)
+		}
+		for i, v := range src {
+			if i != 42 && back[i].Cmp(v) != 0 {
+				t.Errorf("workers %d: Fynd(Fein(%v)) = %v", workers, v, back[i])
+			}
+		}
+	}
+}
+
+func TestFeinBatch64(t *testing.T) {
+
+	src := make([]uint64, 3000)
+	for i := range src {
+		src[i] = uint64(i)*0x1000193 + 0xffff
+	}
+
+	dst := make([]uint64, len(src))
+	if err := FeinBatch64(context.Background(), dst, src, BatchOptions{Workers: 3}); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range src {
+		if dst[i] != Fein64(v) {
+			t.Errorf("Fein64(%d) = %d, got %d", v, Fein64(v), dst[i])
+		}
+	}
+
+	if err := FyndBatch64(context.Background(), dst, dst, BatchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range src {
+		if dst[i] != v {
+			t.Errorf("Fynd64(Fein64(%d)) = %d", v, dst[i])
+		}
+	}
+}
+
+func TestBatchErrors(t *testing.T) {
+
+	_, err := FeinBatch(context.Background(), make([]*big.Int, 1), make([]*big.Int, 2), BatchOptions{})
+	if err == nil || err.Error() != "FeinBatch: dst has 1 items, need at least 2" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err = FyndBatch64(context.Background(), nil, []uint64{1}, BatchOptions{})
+	if err == nil || err.Error() != "FyndBatch64: dst has 0 items, need at least 1" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	src := []*big.Int{big.NewInt(1), big.NewInt(2)}
+	dst := make([]*big.Int, 2)
+	errs, err := FeinBatch(ctx, dst, src, BatchOptions{Workers: 2})
+	if err != context.Canceled {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	for i := range src {
+		if errs[i] != context.Canceled || dst[i] != nil {
+			t.Errorf("item %d was processed after cancellation", i)
+		}
+	}
+}
@@ -1,23 +1,13 @@
 package ob
 
-import (
-	"math/big"
-)
+// muk hashes the low 16 bits of key, as two bytes, with the given seed.
+func muk(seed uint32, key uint64) uint32 {
 
-var (
-	uxFF   = big.NewInt(0xff)
-	uxFF00 = big.NewInt(0xff00)
-	u256   = big.NewInt(256)
-)
+	lo := key & 0xff
+	hi := (key & 0xff00) / 256
+	hashKey := [2]rune{rune(lo), rune(hi)}
 
-func muk(seed uint32, key *big.Int) *big.Int {
-
-	lo := big.NewInt(0).And(key, uxFF).Int64()
-	hi := big.NewInt(0).Div(big.NewInt(0).And(key, uxFF00), u256).Int64()
-	hashKey := []rune{rune(lo), rune(hi)}
-
-	hash := murmurHash(hashKey, seed)
-	return big.NewInt(int64(hash))
+	return murmurHash(hashKey[:], seed)
 }
 
 func murmurHash(key []rune, seed uint32) uint32 {
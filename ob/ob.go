@@ -7,121 +7,344 @@ import (
 	ugi "github.com/deelawn/urbit-gob/internal"
 )
 
+const (
+	// Parameters of the @p Feistel cipher as used by Feis and Tail. The cipher
+	// operates on the domain [0, feisA*feisB) = [0, 0xffff0000).
+	feisRounds        = 4
+	feisA      uint64 = 0xffff
+	feisB      uint64 = 0x10000
+	feisK      uint64 = 0xffffffff
+
+	// FeisMax is the largest value accepted by Feis and Tail.
+	FeisMax uint32 = 0xfffeffff
+	// FeinMin is the smallest value that Fein and Fynd scramble; everything below
+	// it (galaxies and stars) is passed through unchanged.
+	FeinMin uint32 = 0x10000
+)
+
 var (
-	ux10000               = big.NewInt(0x10000)
-	uxFFFFFFFF            = big.NewInt(0xffffffff)
-	ux100000000           = big.NewInt(0x100000000)
 	uxFFFFFFFFFFFFFFFF, _ = big.NewInt(0).SetString("ffffffffffffffff", 16)
-	uxFFFFFFFF00000000, _ = big.NewInt(0).SetString("ffffffff00000000", 16)
 	u65535                = big.NewInt(65535)
-	u65536                = big.NewInt(65536)
+	bigFeisMax            = big.NewInt(int64(FeisMax))
 	raku                  = []uint32{0xb76d5eed, 0xee281300, 0x85bcae01, 0x4b387af7}
 )
 
-func F(j int, arg *big.Int) *big.Int {
+func domainErr(fn string, v interface{}, min, max interface{}) error {
 
-	return muk(raku[j], arg)
+	return fmt.Errorf(ugi.ErrOutOfDomain, fn, v, min, max)
 }
 
-// TODO: this looping code can be combined into a single loop function that accepts an additional
-// function argument. In Fein's case it would be Feis and in Fynd's case it would be Tail.
+// checkBig verifies that arg is a non-nil integer in [0, max].
+func checkBig(fn string, arg, max *big.Int) error {
 
-func Fein(arg string) (*big.Int, error) {
+	if arg == nil {
+		return domainErr(fn, "<nil>", 0, max)
+	}
 
-	v, ok := big.NewInt(0).SetString(arg, 10)
-	if !ok {
-		return nil, fmt.Errorf(ugi.ErrInvalidInt, arg)
+	if arg.Sign() < 0 || arg.Cmp(max) > 0 {
+		return domainErr(fn, arg, 0, max)
 	}
 
-	return feinLoop(v)
+	return nil
 }
 
-func feinLoop(pyn *big.Int) (*big.Int, error) {
+// F is the round function of the Feistel cipher: a murmur3 hash of the low 16
+// bits of arg, seeded with the key of round j. j must be in [0, 3].
+func F(j int, arg *big.Int) (*big.Int, error) {
 
-	hi, lo := loopHiLoInit(pyn)
+	if err := checkRound("F", j); err != nil {
+		return nil, err
+	}
 
-	if pyn.Cmp(ux10000) >= 0 && pyn.Cmp(uxFFFFFFFF) <= 0 {
-		v, err := Feis(big.NewInt(0).Sub(pyn, ux10000).String())
-		if err != nil {
-			return nil, err
-		}
-		return big.NewInt(0).Add(ux10000, v), nil
+	if arg == nil || arg.Sign() < 0 {
+		return nil, fmt.Errorf(ugi.ErrInvalidI, arg)
 	}
 
-	if pyn.Cmp(ux100000000) >= 0 && pyn.Cmp(uxFFFFFFFFFFFFFFFF) <= 0 {
-		v, err := feinLoop(lo)
-		if err != nil {
-			return nil, err
-		}
-		return big.NewInt(0).Or(hi, v), nil
+	lo := big.NewInt(0).And(arg, u65535).Uint64()
+	return big.NewInt(int64(muk(raku[j], lo))), nil
+}
+
+// F32 is F for uint32 arguments.
+func F32(j int, arg uint32) (uint32, error) {
+
+	if err := checkRound("F32", j); err != nil {
+		return 0, err
 	}
 
-	return pyn, nil
+	return muk(raku[j], uint64(arg)), nil
 }
 
-func Fynd(arg *big.Int) (*big.Int, error) {
+// F64 is F for uint64 arguments.
+func F64(j int, arg uint64) (uint32, error) {
+
+	if err := checkRound("F64", j); err != nil {
+		return 0, err
+	}
 
-	return fyndLoop(arg)
+	return muk(raku[j], arg), nil
 }
 
-func fyndLoop(cry *big.Int) (*big.Int, error) {
+func checkRound(fn string, j int) error {
 
-	hi, lo := loopHiLoInit(cry)
+	if j < 0 || j >= len(raku) {
+		return domainErr(fn, j, 0, len(raku)-1)
+	}
 
-	if cry.Cmp(ux10000) >= 0 && cry.Cmp(uxFFFFFFFF) <= 0 {
-		v, err := Tail(big.NewInt(0).Sub(cry, ux10000).String())
-		if err != nil {
-			return nil, err
-		}
-		return big.NewInt(0).Add(ux10000, v), nil
+	return nil
+}
+
+// Fein scrambles a point, mapping it to the value that is rendered as its @p.
+// Points below 0x10000 (galaxies and stars) are returned unchanged, the low
+// 32 bits of larger points are permuted. The domain is [0, 0xffffffffffffffff];
+// larger values (comets) are not scrambled by @p and yield an error.
+func Fein(pyn *big.Int) (*big.Int, error) {
+
+	if err := checkBig("Fein", pyn, uxFFFFFFFFFFFFFFFF); err != nil {
+		return nil, err
+	}
+
+	return big.NewInt(0).SetUint64(Fein64(pyn.Uint64())), nil
+}
+
+// Fein32 is Fein for uint32 points. Every uint32 is in its domain.
+func Fein32(pyn uint32) uint32 {
+
+	if pyn < FeinMin {
+		return pyn
+	}
+
+	return FeinMin + feis(pyn-FeinMin)
+}
+
+// Fein64 is Fein for uint64 points. Every uint64 is in its domain.
+func Fein64(pyn uint64) uint64 {
+
+	return pyn&^0xffffffff | uint64(Fein32(uint32(pyn)))
+}
+
+// Fynd is the inverse of Fein. Its domain is [0, 0xffffffffffffffff].
+func Fynd(cry *big.Int) (*big.Int, error) {
+
+	if err := checkBig("Fynd", cry, uxFFFFFFFFFFFFFFFF); err != nil {
+		return nil, err
 	}
 
-	if cry.Cmp(ux100000000) >= 0 && cry.Cmp(uxFFFFFFFFFFFFFFFF) <= 0 {
-		v, err := fyndLoop(lo)
-		if err != nil {
-			return nil, err
+	return big.NewInt(0).SetUint64(Fynd64(cry.Uint64())), nil
+}
+
+// Fynd32 is Fynd for uint32 values. Every uint32 is in its domain.
+func Fynd32(cry uint32) uint32 {
+
+	if cry < FeinMin {
+		return cry
+	}
+
+	return FeinMin + tail(cry-FeinMin)
+}
+
+// Fynd64 is Fynd for uint64 values. Every uint64 is in its domain.
+func Fynd64(cry uint64) uint64 {
+
+	return cry&^0xffffffff | uint64(Fynd32(uint32(cry)))
+}
+
+// Feis is the 4-round Feistel cipher underlying Fein. Its domain is
+// [0, FeisMax]; it is not a permutation outside of that range.
+func Feis(m *big.Int) (*big.Int, error) {
+
+	if err := checkBig("Feis", m, bigFeisMax); err != nil {
+		return nil, err
+	}
+
+	return big.NewInt(int64(feis(uint32(m.Uint64())))), nil
+}
+
+// Feis32 is Feis for uint32 values.
+func Feis32(m uint32) (uint32, error) {
+
+	if m > FeisMax {
+		return 0, domainErr("Feis32", m, 0, FeisMax)
+	}
+
+	return feis(m), nil
+}
+
+// Feis64 is Feis for uint64 values.
+func Feis64(m uint64) (uint64, error) {
+
+	if m > uint64(FeisMax) {
+		return 0, domainErr("Feis64", m, 0, FeisMax)
+	}
+
+	return uint64(feis(uint32(m))), nil
+}
+
+// Tail is the inverse of Feis. Its domain is [0, FeisMax].
+func Tail(m *big.Int) (*big.Int, error) {
+
+	if err := checkBig("Tail", m, bigFeisMax); err != nil {
+		return nil, err
+	}
+
+	return big.NewInt(int64(tail(uint32(m.Uint64())))), nil
+}
+
+// Tail32 is Tail for uint32 values.
+func Tail32(m uint32) (uint32, error) {
+
+	if m > FeisMax {
+		return 0, domainErr("Tail32", m, 0, FeisMax)
+	}
+
+	return tail(m), nil
+}
+
+// Tail64 is Tail for uint64 values.
+func Tail64(m uint64) (uint64, error) {
+
+	if m > uint64(FeisMax) {
+		return 0, domainErr("Tail64", m, 0, FeisMax)
+	}
+
+	return uint64(tail(uint32(m))), nil
+}
+
+// feis and tail are Fe and Fen specialised to the @p parameters, computed
+// with machine integers. The callers guarantee m <= FeisMax.
+
+func feis(m uint32) uint32 {
+
+	c := feRounds(uint64(m), nil)
+	if c < feisK {
+		return uint32(c)
+	}
+
+	return uint32(feRounds(c, nil))
+}
+
+// feRounds runs one pass of the cipher over m, recording it in p if p is not
+// nil.
+func feRounds(m uint64, p *Pass) uint64 {
+
+	ell, arr := m%feisA, m/feisA
+	if p != nil {
+		p.In, p.Left, p.Right = m, ell, arr
+	}
+
+	for j := 1; j <= feisRounds; j++ {
+		eff := uint64(muk(raku[j-1], arr))
+		mod := feisA
+		if j%2 == 0 {
+			mod = feisB
+		}
+		tmp := (ell + eff) % mod
+		if p != nil {
+			p.Rounds = append(p.Rounds, Round{J: j, Key: raku[j-1], Left: ell, Right: arr, F: uint32(eff), Mod: mod, Out: tmp})
 		}
-		return big.NewInt(0).Or(hi, v), nil
+		ell, arr = arr, tmp
+	}
+
+	// Kept for compatibility with the reference implementation, see Fe.
+	out := feisA*ell + arr
+	if feisRounds%2 != 0 || arr == feisA {
+		out = feisA*arr + ell
+	}
+	if p != nil {
+		p.Out = out
 	}
 
-	return cry, nil
+	return out
 }
 
-func loopHiLoInit(v *big.Int) (*big.Int, *big.Int) {
+func tail(m uint32) uint32 {
 
-	lo := big.NewInt(0).And(v, uxFFFFFFFF)
-	hi := big.NewInt(0).And(v, uxFFFFFFFF00000000)
+	c := fenRounds(uint64(m), nil)
+	if c < feisK {
+		return uint32(c)
+	}
 
-	return hi, lo
+	return uint32(fenRounds(c, nil))
 }
 
-func Feis(arg string) (*big.Int, error) {
+// fenRounds runs one pass of the inverse cipher over m, recording it in p if p
+// is not nil.
+func fenRounds(m uint64, p *Pass) uint64 {
 
-	v, ok := big.NewInt(0).SetString(arg, 10)
-	if !ok {
-		return nil, fmt.Errorf(ugi.ErrInvalidInt, arg)
+	ahh, ale := m%feisA, m/feisA
+	if feisRounds%2 != 0 {
+		ahh, ale = ale, ahh
+	}
+
+	ell, arr := ale, ahh
+	if ale == feisA {
+		ell, arr = arr, ell
+	}
+	if p != nil {
+		p.In, p.Left, p.Right = m, ell, arr
 	}
 
-	return Fe(4, u65535, u65536, uxFFFFFFFF, v), nil
+	for j := feisRounds; j >= 1; j-- {
+		eff := uint64(muk(raku[j-1], ell))
+		u := feisA
+		if j%2 == 0 {
+			u = feisB
+		}
+		tmp := (arr + u - eff%u) % u
+		if p != nil {
+			p.Rounds = append(p.Rounds, Round{J: j, Key: raku[j-1], Left: ell, Right: arr, F: uint32(eff), Mod: u, Out: tmp})
+		}
+		ell, arr = tmp, ell
+	}
+
+	out := feisA*arr + ell
+	if p != nil {
+		p.Out = out
+	}
+
+	return out
 }
 
 // TODO: merge Fe and Fen code to accept an additional function argument.
 
+// checkFeistel validates the arguments shared by Fe and Fen: r rounds with a
+// key each, positive a and b, and m in [0, a*b).
+func checkFeistel(fn string, r int, a, b, k, m *big.Int) error {
+
+	if r < 1 || r > len(raku) {
+		return domainErr(fn+" rounds", r, 1, len(raku))
+	}
+
+	for _, v := range []*big.Int{a, b, k} {
+		if v == nil || v.Sign() <= 0 {
+			return fmt.Errorf(ugi.ErrInvalidI, v)
+		}
+	}
+
+	max := big.NewInt(0).Mul(a, b)
+	return checkBig(fn, m, max.Sub(max, big.NewInt(1)))
+}
+
+// Fe is a generalised Feistel cipher over the domain [0, a*b) with r rounds,
+// cycle-walking any result that is not below k.
 func Fe(
 	r int,
 	a,
 	b,
 	k,
 	m *big.Int,
-) *big.Int {
+) (*big.Int, error) {
+
+	if err := checkFeistel("Fe", r, a, b, k, m); err != nil {
+		return nil, err
+	}
 
 	c := fe(r, a, b, m)
 
 	if c.Cmp(k) == -1 {
-		return c
+		return c, nil
 	}
 
-	return fe(r, a, b, c)
+	return fe(r, a, b, c), nil
 }
 
 func fe(
@@ -155,7 +378,7 @@ func feLoop(
 		return big.NewInt(0).Add(big.NewInt(0).Mul(a, ell), arr)
 	}
 
-	eff := F(j-1, arr)
+	eff := roundF(j-1, arr)
 	tmp := big.NewInt(0).Add(ell, eff)
 	if j%2 != 0 {
 		tmp = tmp.Mod(tmp, a)
@@ -166,31 +389,26 @@ func feLoop(
 	return feLoop(r, a, b, j+1, arr, tmp)
 }
 
-func Tail(arg string) (*big.Int, error) {
-
-	v, ok := big.NewInt(0).SetString(arg, 10)
-	if !ok {
-		return nil, fmt.Errorf(ugi.ErrInvalidInt, arg)
-	}
-
-	return Fen(4, u65535, u65536, uxFFFFFFFF, v), nil
-}
-
+// Fen is the inverse of Fe.
 func Fen(
 	r int,
 	a,
 	b,
 	k,
 	m *big.Int,
-) *big.Int {
+) (*big.Int, error) {
+
+	if err := checkFeistel("Fen", r, a, b, k, m); err != nil {
+		return nil, err
+	}
 
 	c := fen(r, a, b, m)
 
 	if c.Cmp(k) == -1 {
-		return c
+		return c, nil
 	}
 
-	return fen(r, a, b, c)
+	return fen(r, a, b, c), nil
 }
 
 func fen(
@@ -227,7 +445,7 @@ func fenLoop(
 		return big.NewInt(0).Add(big.NewInt(0).Mul(a, arr), ell)
 	}
 
-	eff := F(j-1, ell)
+	eff := roundF(j-1, ell)
 	tmp := big.NewInt(0)
 	useValue := a
 
@@ -241,3 +459,11 @@ func fenLoop(
 
 	return fenLoop(a, b, j-1, tmp, ell)
 }
+
+// roundF is F without argument checks, for use once the round number and
+// argument have been validated.
+func roundF(j int, arg *big.Int) *big.Int {
+
+	lo := big.NewInt(0).And(arg, u65535).Uint64()
+	return big.NewInt(int64(muk(raku[j], lo)))
+}
@@ -23,8 +23,10 @@ func TestEdgeCases(t *testing.T) {
 	
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
+			inputBig, _ := new(big.Int).SetString(tc.input, 10)
+
 			// Encrypt
-			encrypted, err := Fein(tc.input)
+			encrypted, err := Fein(inputBig)
 			if err != nil {
 				t.Fatalf("Fein failed for %s (%s): %v", tc.input, tc.desc, err)
 			}
@@ -36,7 +38,6 @@ func TestEdgeCases(t *testing.T) {
 			}
 			
 			// Verify round-trip
-			inputBig, _ := new(big.Int).SetString(tc.input, 10)
 			if decrypted.Cmp(inputBig) != 0 {
 				t.Errorf("Round-trip failed for %s (%s): got %s", tc.input, tc.desc, decrypted.String())
 			}
@@ -50,13 +51,13 @@ func TestSmallValues(t *testing.T) {
 	
 	for _, val := range testVals {
 		t.Run("small_"+val, func(t *testing.T) {
-			encrypted, err := Fein(val)
+			valBig, _ := new(big.Int).SetString(val, 10)
+			encrypted, err := Fein(valBig)
 			if err != nil {
 				t.Fatalf("Fein failed for %s: %v", val, err)
 			}
 			
 			// Small values should be unchanged
-			valBig, _ := new(big.Int).SetString(val, 10)
 			if encrypted.Cmp(valBig) != 0 {
 				t.Errorf("Small value %s was changed to %s", val, encrypted.String())
 			}
@@ -82,29 +83,22 @@ func TestLargeValues(t *testing.T) {
 	}{
 		{"4294967296", "0x100000000 (just outside 32-bit)"},
 		{"18446744073709551615", "Max uint64 (0xFFFFFFFFFFFFFFFF)"},
-		{"18446744073709551616", "Beyond uint64"},
 	}
 	
 	for _, tc := range testVals {
 		t.Run("large_"+tc.val[:8], func(t *testing.T) {
-			encrypted, err := Fein(tc.val)
+			valBig, _ := new(big.Int).SetString(tc.val, 10)
+			encrypted, err := Fein(valBig)
 			if err != nil {
 				t.Fatalf("Fein failed for %s (%s): %v", tc.val, tc.desc, err)
 			}
 			
-			valBig, _ := new(big.Int).SetString(tc.val, 10)
-			
-			// Values > 0xFFFFFFFFFFFFFFFF should be unchanged
-			// Values in [0x100000000, 0xFFFFFFFFFFFFFFFF] will be processed
-			beyondUint64, _ := new(big.Int).SetString("18446744073709551616", 10)
-			if valBig.Cmp(beyondUint64) >= 0 {
-				// Should be unchanged
-				if encrypted.Cmp(valBig) != 0 {
-					t.Errorf("Value beyond uint64 %s was changed to %s", tc.val, encrypted.String())
-				}
+			// Values in [0x100000000, 0xFFFFFFFFFFFFFFFF] keep their high 32 bits
+			hi := new(big.Int).Rsh(valBig, 32)
+			if new(big.Int).Rsh(encrypted, 32).Cmp(hi) != 0 {
+				t.Errorf("High bits of %s changed: got %s", tc.val, encrypted.String())
 			}
 			
-			// Regardless, round-trip should always work
 			decrypted, err := Fynd(encrypted)
 			if err != nil {
 				t.Fatalf("Fynd failed for %s: %v", tc.val, err)
@@ -125,7 +119,7 @@ func TestSpecificCollisionPrevention(t *testing.T) {
 	
 	for i := 0; i < 1000; i++ {
 		val := new(big.Int).Add(start, big.NewInt(int64(i)))
-		encrypted, err := Fein(val.String())
+		encrypted, err := Fein(val)
 		if err != nil {
 			t.Fatalf("Fein failed for %s: %v", val.String(), err)
 		}
@@ -1,148 +1,51 @@
-package ob
+package ob_test
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
 	"math/big"
+	"os"
 	"runtime"
-	"sync"
-	"sync/atomic"
 	"testing"
+
+	"github.com/deelawn/urbit-gob/ob"
+	"github.com/deelawn/urbit-gob/ob/verify"
 )
 
 // TestExhaustiveBijectivity verifies that the scrambler is fully bijective
 // by checking that every input maps to a unique output and can be reversed.
 // WARNING: This test is extremely expensive and should not be run in normal CI.
-// Use TestSpotCheckBijectivity for regular testing.
+// Set OB_EXHAUSTIVE=1 to run it; use TestSpotCheckBijectivity for regular testing.
 func TestExhaustiveBijectivity(t *testing.T) {
-	t.Skip("Skipping exhaustive test - use TestSpotCheckBijectivity or run explicitly with -run=TestExhaustiveBijectivity")
-
-	// Test ranges based on the Urbit @p system
-	testRanges := []struct {
-		name  string
-		start *big.Int
-		end   *big.Int
-	}{
-		// Test small values that don't go through Feistel
-		{"small values", big.NewInt(0), big.NewInt(0x10000)},
-		// Test the full Feistel cipher range
-		{"feistel range", big.NewInt(0x10000), big.NewInt(0x100000000)},
+	if os.Getenv("OB_EXHAUSTIVE") == "" {
+		t.Skip("Skipping exhaustive test - set OB_EXHAUSTIVE=1 to run it")
 	}
 
 	numWorkers := runtime.NumCPU()
-	
-	for _, tr := range testRanges {
-		t.Run(tr.name, func(t *testing.T) {
-			// Use sync.Map for thread-safe collision detection
-			seen := &sync.Map{} // encrypted -> original
-			
-			// Atomic counter for progress tracking
-			var count atomic.Int64
-			var collisions atomic.Int64
-			
-			// Calculate total work
-			rangeSize := new(big.Int).Sub(tr.end, tr.start)
-			
-			// Work channel and wait group
-			workChan := make(chan *big.Int, numWorkers*10)
-			var wg sync.WaitGroup
-			
-			// Error channel for collecting failures
-			errChan := make(chan error, numWorkers)
-			
-			// Start workers
-			for w := 0; w < numWorkers; w++ {
-				wg.Add(1)
-				go func(workerID int) {
-					defer wg.Done()
-					
-					for i := range workChan {
-						// Encrypt
-						encrypted, err := Fein(i.String())
-						if err != nil {
-							errChan <- fmt.Errorf("worker %d: Fein failed for %s: %v", workerID, i.String(), err)
-							continue
-						}
-						
-						encStr := encrypted.String()
-						
-						// Check for collisions
-						if original, loaded := seen.LoadOrStore(encStr, i.String()); loaded {
-							origStr := original.(string)
-							if origStr != i.String() {
-								collisions.Add(1)
-								errChan <- fmt.Errorf("COLLISION DETECTED: %s and %s both encrypt to %s", origStr, i.String(), encStr)
-							}
-							// If it's the same input, that's fine (can happen due to range overlap)
-							continue
-						}
-						
-						// Verify round-trip
-						decrypted, err := Fynd(encrypted)
-						if err != nil {
-							errChan <- fmt.Errorf("worker %d: Fynd failed for %s: %v", workerID, encrypted.String(), err)
-							continue
-						}
-						
-						if decrypted.Cmp(i) != 0 {
-							errChan <- fmt.Errorf("worker %d: Round-trip failed: %s -> %s -> %s", workerID, i.String(), encrypted.String(), decrypted.String())
-							continue
-						}
-						
-						// Update progress
-						currentCount := count.Add(1)
-						if currentCount%1000000 == 0 {
-							progress := float64(currentCount) / float64(rangeSize.Int64()) * 100
-							fmt.Printf("Progress: tested %d values (%.1f%%)\n", currentCount, progress)
-						}
-					}
-				}(w)
-			}
-			
-			// Generate work
-			go func() {
-				for i := new(big.Int).Set(tr.start); i.Cmp(tr.end) < 0; i.Add(i, big.NewInt(1)) {
-					workChan <- new(big.Int).Set(i) // Copy i to avoid race
-				}
-				close(workChan)
-			}()
-			
-			// Collect errors in a separate goroutine
-			var errors []error
-			errDone := make(chan struct{})
-			go func() {
-				for err := range errChan {
-					errors = append(errors, err)
-					// Stop on first collision
-					if collisions.Load() > 0 {
-						break
-					}
-				}
-				close(errDone)
-			}()
-			
-			// Wait for all workers to finish
-			wg.Wait()
-			close(errChan)
-			<-errDone
-			
-			// Check for errors
-			if len(errors) > 0 {
-				for i, err := range errors {
-					t.Errorf("Error %d: %v", i+1, err)
-					if i >= 10 && len(errors) > 11 {
-						t.Errorf("... and %d more errors", len(errors)-11)
-						break
-					}
-				}
-				t.FailNow()
+	report, err := verify.Full(context.Background(), verify.Options{
+		Workers: numWorkers,
+		Progress: func(checked uint64) {
+			if checked%(1<<28) == 0 {
+				fmt.Printf("Progress: tested %d values (%.1f%%)\n", checked, float64(checked)/float64(verify.Domain)*100)
 			}
-			
-			finalCount := count.Load()
-			fmt.Printf("Successfully tested %d values in range %s-%s using %d workers\n", 
-				finalCount, tr.start.String(), tr.end.String(), numWorkers)
-		})
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range report.Collisions {
+		t.Error(c)
+	}
+	for _, f := range report.RoundTripFailures {
+		t.Error(f)
+	}
+	if !report.OK() {
+		t.Fatalf("%d collisions and %d round-trip failures", report.NumCollisions, report.NumRoundTripFailures)
 	}
+
+	fmt.Printf("Successfully tested %d values using %d workers in %v\n", report.Checked, numWorkers, report.Elapsed)
 }
 
 // TestKnownCollisions tests the specific collision cases from issue #1105
@@ -159,13 +62,16 @@ func TestKnownCollisions(t *testing.T) {
 	
 	for _, c := range collisions {
 		t.Run(fmt.Sprintf("collision_%s_vs_%s", c.val1, c.val2), func(t *testing.T) {
+			val1Big, _ := new(big.Int).SetString(c.val1, 10)
+			val2Big, _ := new(big.Int).SetString(c.val2, 10)
+
 			// Encrypt both values
-			enc1, err := Fein(c.val1)
+			enc1, err := ob.Fein(val1Big)
 			if err != nil {
 				t.Fatalf("Failed to encrypt %s: %v", c.val1, err)
 			}
 			
-			enc2, err := Fein(c.val2)
+			enc2, err := ob.Fein(val2Big)
 			if err != nil {
 				t.Fatalf("Failed to encrypt %s: %v", c.val2, err)
 			}
@@ -176,19 +82,16 @@ func TestKnownCollisions(t *testing.T) {
 			}
 			
 			// Verify round trips
-			dec1, err := Fynd(enc1)
+			dec1, err := ob.Fynd(enc1)
 			if err != nil {
 				t.Fatalf("Failed to decrypt %s: %v", enc1.String(), err)
 			}
 			
-			dec2, err := Fynd(enc2)
+			dec2, err := ob.Fynd(enc2)
 			if err != nil {
 				t.Fatalf("Failed to decrypt %s: %v", enc2.String(), err)
 			}
 			
-			val1Big, _ := new(big.Int).SetString(c.val1, 10)
-			val2Big, _ := new(big.Int).SetString(c.val2, 10)
-			
 			if dec1.Cmp(val1Big) != 0 {
 				t.Errorf("Round trip failed for %s: got %s", c.val1, dec1.String())
 			}
@@ -235,7 +138,7 @@ func TestSpotCheckBijectivity(t *testing.T) {
 			testVal := new(big.Int).Add(r.start, randOffset)
 			
 			// Encrypt
-			encrypted, err := Fein(testVal.String())
+			encrypted, err := ob.Fein(testVal)
 			if err != nil {
 				t.Fatalf("Fein failed for %s: %v", testVal.String(), err)
 			}
@@ -253,7 +156,7 @@ func TestSpotCheckBijectivity(t *testing.T) {
 			}
 			
 			// Verify round-trip
-			decrypted, err := Fynd(encrypted)
+			decrypted, err := ob.Fynd(encrypted)
 			if err != nil {
 				t.Fatalf("Fynd failed for %s: %v", encrypted.String(), err)
 			}
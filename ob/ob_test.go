@@ -0,0 +1,235 @@
+package ob
+
+import (
+	"math/big"
+	"testing"
+)
+
+func mustBig(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		panic("invalid test integer: " + s)
+	}
+	return v
+}
+
+// TestFeinBoundaries documents how Fein treats the edges of each class of
+// point: 0xffff is the last star and passes through unchanged, 0x10000 is the
+// first planet and the first scrambled value, 0xffffffff is the last planet
+// and 0x100000000 is the first moon, whose low 32 bits (zero) are below
+// 0x10000 and so are left alone.
+func TestFeinBoundaries(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   uint64
+		out  uint64
+	}{
+		{"zero", 0, 0},
+		{"last star", 0xffff, 0xffff},
+		{"first planet", 0x10000, 1111384255},
+		{"last planet", 0xffffffff, 3148143822},
+		{"first moon", 0x100000000, 0x100000000},
+		{"first scrambled moon", 0x100010000, 0x100000000 + 1111384255},
+		{"last moon", 0xffffffffffffffff, 0xffffffff00000000 + 3148143822},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Fein64(tc.in); got != tc.out {
+				t.Errorf("Fein64(%#x) = %d, want %d", tc.in, got, tc.out)
+			}
+			if got := Fynd64(tc.out); got != tc.in {
+				t.Errorf("Fynd64(%d) = %#x, want %#x", tc.out, got, tc.in)
+			}
+
+			got, err := Fein(new(big.Int).SetUint64(tc.in))
+			if err != nil {
+				t.Fatalf("Fein(%#x) failed: %v", tc.in, err)
+			}
+			if got.Uint64() != tc.out {
+				t.Errorf("Fein(%#x) = %s, want %d", tc.in, got, tc.out)
+			}
+
+			back, err := Fynd(got)
+			if err != nil {
+				t.Fatalf("Fynd(%s) failed: %v", got, err)
+			}
+			if back.Uint64() != tc.in {
+				t.Errorf("Fynd(%s) = %s, want %d", got, back, tc.in)
+			}
+
+			if tc.in <= 0xffffffff {
+				if got := Fein32(uint32(tc.in)); uint64(got) != tc.out {
+					t.Errorf("Fein32(%#x) = %d, want %d", tc.in, got, tc.out)
+				}
+				if got := Fynd32(uint32(tc.out)); uint64(got) != tc.in {
+					t.Errorf("Fynd32(%d) = %#x, want %#x", tc.out, got, tc.in)
+				}
+			}
+		})
+	}
+}
+
+// TestFeisBoundaries documents the domain of the Feistel cipher itself:
+// [0, 0xfffeffff]. Fein hands it values offset by 0x10000, so 0 and
+// 0xfffeffff correspond to the first and last planets. At 0xffff0000 the
+// cipher would wrap around and collide with 0.
+func TestFeisBoundaries(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   uint32
+		out  uint32
+	}{
+		{"first planet", 0, 1111384255 - 0x10000},
+		{"last planet", 0xfffeffff, 3148143822 - 0x10000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Feis32(tc.in)
+			if err != nil || got != tc.out {
+				t.Errorf("Feis32(%#x) = %d, %v, want %d", tc.in, got, err, tc.out)
+			}
+			back, err := Tail32(got)
+			if err != nil || back != tc.in {
+				t.Errorf("Tail32(%d) = %#x, %v, want %#x", got, back, err, tc.in)
+			}
+
+			got64, err := Feis64(uint64(tc.in))
+			if err != nil || got64 != uint64(tc.out) {
+				t.Errorf("Feis64(%#x) = %d, %v, want %d", tc.in, got64, err, tc.out)
+			}
+			back64, err := Tail64(got64)
+			if err != nil || back64 != uint64(tc.in) {
+				t.Errorf("Tail64(%d) = %#x, %v, want %#x", got64, back64, err, tc.in)
+			}
+
+			gotBig, err := Feis(big.NewInt(int64(tc.in)))
+			if err != nil || gotBig.Uint64() != uint64(tc.out) {
+				t.Errorf("Feis(%#x) = %v, %v, want %d", tc.in, gotBig, err, tc.out)
+			}
+			backBig, err := Tail(gotBig)
+			if err != nil || backBig.Uint64() != uint64(tc.in) {
+				t.Errorf("Tail(%v) = %v, %v, want %#x", gotBig, backBig, err, tc.in)
+			}
+		})
+	}
+}
+
+// TestOutOfDomain checks that every function rejects inputs outside of the
+// domain it is defined on rather than passing them through.
+func TestOutOfDomain(t *testing.T) {
+	testCases := []struct {
+		name string
+		call func() error
+		want string
+	}{
+		{"Fein beyond uint64", func() error {
+			_, err := Fein(mustBig("0x10000000000000000"))
+			return err
+		}, "Fein: 18446744073709551616 is outside the domain [0, 18446744073709551615]"},
+		{"Fein negative", func() error {
+			_, err := Fein(big.NewInt(-1))
+			return err
+		}, "Fein: -1 is outside the domain [0, 18446744073709551615]"},
+		{"Fein nil", func() error {
+			_, err := Fein(nil)
+			return err
+		}, "Fein: <nil> is outside the domain [0, 18446744073709551615]"},
+		{"Fynd beyond uint64", func() error {
+			_, err := Fynd(mustBig("0x10000000000000000"))
+			return err
+		}, "Fynd: 18446744073709551616 is outside the domain [0, 18446744073709551615]"},
+		{"Feis first invalid", func() error {
+			_, err := Feis(big.NewInt(0xffff0000))
+			return err
+		}, "Feis: 4294901760 is outside the domain [0, 4294901759]"},
+		{"Feis32 first invalid", func() error {
+			_, err := Feis32(0xffff0000)
+			return err
+		}, "Feis32: 4294901760 is outside the domain [0, 4294901759]"},
+		{"Feis64 beyond uint32", func() error {
+			_, err := Feis64(0x100000000)
+			return err
+		}, "Feis64: 4294967296 is outside the domain [0, 4294901759]"},
+		{"Tail max uint32", func() error {
+			_, err := Tail(big.NewInt(0xffffffff))
+			return err
+		}, "Tail: 4294967295 is outside the domain [0, 4294901759]"},
+		{"Tail32 first invalid", func() error {
+			_, err := Tail32(0xffff0000)
+			return err
+		}, "Tail32: 4294901760 is outside the domain [0, 4294901759]"},
+		{"Tail64 first invalid", func() error {
+			_, err := Tail64(0xffff0000)
+			return err
+		}, "Tail64: 4294901760 is outside the domain [0, 4294901759]"},
+		{"F round", func() error {
+			_, err := F(4, big.NewInt(0))
+			return err
+		}, "F: 4 is outside the domain [0, 3]"},
+		{"F32 round", func() error {
+			_, err := F32(-1, 0)
+			return err
+		}, "F32: -1 is outside the domain [0, 3]"},
+		{"Fe rounds", func() error {
+			_, err := Fe(5, big.NewInt(0xffff), big.NewInt(0x10000), big.NewInt(0xffffffff), big.NewInt(0))
+			return err
+		}, "Fe rounds: 5 is outside the domain [1, 4]"},
+		{"Fen beyond a*b", func() error {
+			_, err := Fen(4, big.NewInt(0xffff), big.NewInt(0x10000), big.NewInt(0xffffffff), big.NewInt(0xffff0000))
+			return err
+		}, "Fen: 4294901760 is outside the domain [0, 4294901759]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.call()
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if err.Error() != tc.want {
+				t.Errorf("got error %q, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+// TestTypedVariantsAgree checks that the uint32, uint64 and *big.Int variants
+// of each function agree with one another and with the generic Fe and Fen.
+func TestTypedVariantsAgree(t *testing.T) {
+	a, b, k := big.NewInt(0xffff), big.NewInt(0x10000), big.NewInt(0xffffffff)
+
+	for _, m := range []uint32{0, 1, 0xfffe, 0xffff, 0x10000, 0xfffe0000, 0xfffeffff} {
+		fe, err := Fe(4, a, b, k, big.NewInt(int64(m)))
+		if err != nil {
+			t.Fatalf("Fe(%#x) failed: %v", m, err)
+		}
+		feis, _ := Feis32(m)
+		if fe.Uint64() != uint64(feis) {
+			t.Errorf("Fe(%#x) = %s, Feis32 = %d", m, fe, feis)
+		}
+
+		fen, err := Fen(4, a, b, k, fe)
+		if err != nil {
+			t.Fatalf("Fen(%s) failed: %v", fe, err)
+		}
+		if fen.Uint64() != uint64(m) {
+			t.Errorf("Fen(Fe(%#x)) = %s", m, fen)
+		}
+	}
+
+	for j := 0; j < 4; j++ {
+		for _, arg := range []uint64{0, 0xff, 0xffff, 0x10000, 0x12345678, 0xffffffffffffffff} {
+			f, err := F(j, new(big.Int).SetUint64(arg))
+			if err != nil {
+				t.Fatalf("F(%d, %#x) failed: %v", j, arg, err)
+			}
+			f32, _ := F32(j, uint32(arg))
+			f64, _ := F64(j, arg)
+			if f.Uint64() != uint64(f32) || f32 != f64 {
+				t.Errorf("F(%d, %#x) disagree: %s, %d, %d", j, arg, f, f32, f64)
+			}
+		}
+	}
+}
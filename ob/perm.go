@@ -0,0 +1,148 @@
+package ob
+
+import (
+	"math"
+)
+
+const (
+	permRounds = 4
+
+	// PermMax is the largest domain size supported by a Perm.
+	PermMax uint64 = 1 << 62
+)
+
+// Perm is a keyed pseudo-random permutation of [0, n). Like Feis it is a
+// Feistel cipher over [0, a*b) with murmur3 round functions, here seeded from
+// a secret key and hashing the whole half rather than its low 16 bits, and it
+// cycle-walks to stay within [0, n). Different keys give unrelated orders.
+type Perm struct {
+	n, a, b uint64
+	seeds   [permRounds]uint32
+}
+
+// NewPerm returns the permutation of [0, n) selected by key. n must be in
+// [1, PermMax].
+func NewPerm(key []byte, n uint64) (*Perm, error) {
+
+	if n < 1 || n > PermMax {
+		return nil, domainErr("NewPerm", n, 1, PermMax)
+	}
+
+	// Split the domain into halves as close to equal as possible, so that
+	// a*b exceeds n by less than a and cycle-walking is rare.
+	a := uint64(math.Sqrt(float64(n)))
+	for a*a > n {
+		a--
+	}
+	for a*a < n {
+		a++
+	}
+	b := (n + a - 1) / a
+
+	p := &Perm{n: n, a: a, b: b}
+
+	runes := make([]rune, len(key))
+	for i, c := range key {
+		runes[i] = rune(c)
+	}
+	for j := range p.seeds {
+		p.seeds[j] = murmurHash(runes, raku[j])
+	}
+
+	return p, nil
+}
+
+// Size returns n, the size of the permuted domain.
+func (p *Perm) Size() uint64 {
+
+	return p.n
+}
+
+// Permute returns the position of i in the permuted order.
+func (p *Perm) Permute(i uint64) (uint64, error) {
+
+	if i >= p.n {
+		return 0, domainErr("Permute", i, 0, p.n-1)
+	}
+
+	for {
+		i = p.encrypt(i)
+		if i < p.n {
+			return i, nil
+		}
+	}
+}
+
+// Invert is the inverse of Permute.
+func (p *Perm) Invert(x uint64) (uint64, error) {
+
+	if x >= p.n {
+		return 0, domainErr("Invert", x, 0, p.n-1)
+	}
+
+	for {
+		x = p.decrypt(x)
+		if x < p.n {
+			return x, nil
+		}
+	}
+}
+
+// round is the round function: a murmur3 hash of the 8 bytes of v.
+func (p *Perm) round(j int, v uint64) uint64 {
+
+	var key [8]rune
+	for i := range key {
+		key[i] = rune(v >> (8 * uint(i)) & 0xff)
+	}
+
+	return uint64(murmurHash(key[:], p.seeds[j]))
+}
+
+// encrypt permutes [0, a*b). The left half lives in [0, a) and the right in
+// [0, b); rounds alternate between reducing modulo a and b, so that after an
+// even number of rounds both halves are back in their own ranges.
+func (p *Perm) encrypt(m uint64) uint64 {
+
+	ell, arr := m%p.a, m/p.a
+	for j := 0; j < permRounds; j++ {
+		mod := p.a
+		if j%2 != 0 {
+			mod = p.b
+		}
+		ell, arr = arr, (ell+p.round(j, arr)%mod)%mod
+	}
+
+	return p.a*arr + ell
+}
+
+func (p *Perm) decrypt(c uint64) uint64 {
+
+	ell, arr := c%p.a, c/p.a
+	for j := permRounds - 1; j >= 0; j-- {
+		mod := p.a
+		if j%2 != 0 {
+			mod = p.b
+		}
+		ell, arr = (arr+mod-p.round(j, ell)%mod)%mod, ell
+	}
+
+	return p.a*arr + ell
+}
+
+// Fingerprint identifies the key and size of the permutation without
+// revealing the key, so that a saved position can be checked against it.
+func (p *Perm) Fingerprint() uint32 {
+
+	var key [4*permRounds + 8]rune
+	for j, seed := range p.seeds {
+		for i := 0; i < 4; i++ {
+			key[4*j+i] = rune(seed >> (8 * uint(i)) & 0xff)
+		}
+	}
+	for i := 0; i < 8; i++ {
+		key[4*permRounds+i] = rune(p.n >> (8 * uint(i)) & 0xff)
+	}
+
+	return murmurHash(key[:], 0)
+}
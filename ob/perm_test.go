@@ -0,0 +1,87 @@
+package ob
+
+import (
+	"testing"
+)
+
+func TestPermIsPermutation(t *testing.T) {
+	for _, n := range []uint64{1, 2, 3, 255, 256, 65535, 100003} {
+		p, err := NewPerm([]byte("secret"), n)
+		if err != nil {
+			t.Fatalf("NewPerm(%d) failed: %v", n, err)
+		}
+
+		seen := make([]bool, n)
+		for i := uint64(0); i < n; i++ {
+			x, err := p.Permute(i)
+			if err != nil {
+				t.Fatalf("Permute(%d) failed: %v", i, err)
+			}
+			if seen[x] {
+				t.Fatalf("n = %d: %d is produced twice", n, x)
+			}
+			seen[x] = true
+
+			back, err := p.Invert(x)
+			if err != nil || back != i {
+				t.Fatalf("n = %d: Invert(Permute(%d)) = %d, %v", n, i, back, err)
+			}
+		}
+	}
+}
+
+func TestPermKeys(t *testing.T) {
+	const n = 65535
+
+	p1, _ := NewPerm([]byte("one key"), n)
+	p2, _ := NewPerm([]byte("another key"), n)
+	p3, _ := NewPerm([]byte("one key"), n)
+
+	same, fixed := 0, 0
+	for i := uint64(0); i < n; i++ {
+		x1, _ := p1.Permute(i)
+		x2, _ := p2.Permute(i)
+		x3, _ := p3.Permute(i)
+		if x1 != x3 {
+			t.Fatalf("the same key gave different orders at %d", i)
+		}
+		if x1 == x2 {
+			same++
+		}
+		if x1 == i {
+			fixed++
+		}
+	}
+
+	// A random permutation agrees with another, or fixes a point, about once.
+	if same > 10 || fixed > 10 {
+		t.Errorf("orders are not independent: %d agreements between keys, %d fixed points", same, fixed)
+	}
+}
+
+func TestPermDomain(t *testing.T) {
+	if _, err := NewPerm(nil, 0); err == nil {
+		t.Error("expected an error for an empty domain")
+	}
+	if _, err := NewPerm(nil, PermMax+1); err == nil {
+		t.Error("expected an error beyond PermMax")
+	}
+
+	p, err := NewPerm(nil, PermMax)
+	if err != nil {
+		t.Fatalf("NewPerm(PermMax) failed: %v", err)
+	}
+	for _, i := range []uint64{0, 1, PermMax - 1} {
+		x, err := p.Permute(i)
+		if err != nil || x >= PermMax {
+			t.Fatalf("Permute(%d) = %d, %v", i, x, err)
+		}
+		if back, _ := p.Invert(x); back != i {
+			t.Errorf("Invert(Permute(%d)) = %d", i, back)
+		}
+	}
+
+	if _, err := p.Permute(PermMax); err == nil {
+		t.Error("expected an error for an index outside the domain")
+	}
+}
@@ -0,0 +1,84 @@
+package ob
+
+// Round is one round of the Feistel cipher. Left and Right are the halves
+// entering the round. F is the round function of the key and one half: Right
+// when scrambling, Left when unscrambling. Out is (Left + F) mod Mod when
+// scrambling and (Right - F) mod Mod when unscrambling, and becomes one half
+// of the next round.
+type Round struct {
+	J           int
+	Key         uint32
+	Left, Right uint64
+	F           uint32
+	Mod         uint64
+	Out         uint64
+}
+
+// Pass is one pass of the cipher over In, which is split into Left and Right
+// before the rounds and recombined into Out after them. When scrambling Left
+// is In mod 0xffff and Right is In / 0xffff; when unscrambling they are the
+// other way around.
+type Pass struct {
+	In          uint64
+	Left, Right uint64
+	Rounds      []Round
+	Out         uint64
+}
+
+// Trace records how Fein or Fynd computed Out from In.
+type Trace struct {
+	In uint64
+
+	// High holds the bits of In above the low 32, which are kept as they are.
+	// Low is the rest, which is permuted if Scrambled is set, that is if it is
+	// at least FeinMin.
+	High      uint64
+	Low       uint32
+	Scrambled bool
+
+	// Passes are the passes of the cipher over Low - FeinMin. A second pass
+	// is made if the first gives a value outside of [0, FeisMax], which is
+	// known as cycle walking.
+	Passes []Pass
+
+	Out uint64
+}
+
+// FeinTrace is Fein64, recording each step of the computation.
+func FeinTrace(pyn uint64) Trace {
+
+	return trace(pyn, feRounds)
+}
+
+// FyndTrace is Fynd64, recording each step of the computation.
+func FyndTrace(cry uint64) Trace {
+
+	return trace(cry, fenRounds)
+}
+
+func trace(in uint64, rounds func(uint64, *Pass) uint64) Trace {
+
+	t := Trace{
+		In:   in,
+		High: in &^ 0xffffffff,
+		Low:  uint32(in),
+		Out:  in,
+	}
+	if t.Low < FeinMin {
+		return t
+	}
+	t.Scrambled = true
+
+	var p Pass
+	c := rounds(uint64(t.Low-FeinMin), &p)
+	t.Passes = append(t.Passes, p)
+	if c >= feisK {
+		p = Pass{}
+		c = rounds(c, &p)
+		t.Passes = append(t.Passes, p)
+	}
+
+	t.Out = t.High | uint64(FeinMin+uint32(c))
+
+	return t
+}
@@ -0,0 +1,55 @@
+package ob
+
+import (
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	for _, v := range []uint64{0, 0xffff, 0x10000, 0x10001, 0x60daf13f, 0xffffffff, 0x100010000, 0xfedcba9876543210} {
+		ft := FeinTrace(v)
+		if ft.Out != Fein64(v) {
+			t.Errorf("FeinTrace(%#x).Out = %#x, want %#x", v, ft.Out, Fein64(v))
+		}
+		yt := FyndTrace(ft.Out)
+		if yt.Out != v {
+			t.Errorf("FyndTrace(%#x).Out = %#x, want %#x", ft.Out, yt.Out, v)
+		}
+
+		if ft.Scrambled != (uint32(v) >= FeinMin) || ft.High|uint64(ft.Low) != v {
+			t.Errorf("FeinTrace(%#x) splits into %#x and %#x, scrambled %v", v, ft.High, ft.Low, ft.Scrambled)
+		}
+		if !ft.Scrambled {
+			if len(ft.Passes) != 0 || ft.Out != v {
+				t.Errorf("FeinTrace(%#x) scrambled a value below FeinMin", v)
+			}
+			continue
+		}
+
+		for _, tt := range []struct {
+			tr   Trace
+			fein bool
+		}{{ft, true}, {yt, false}} {
+			tr := tt.tr
+			p := tr.Passes[0]
+			if p.In != uint64(tr.Low-FeinMin) || len(p.Rounds) != feisRounds {
+				t.Fatalf("trace of %#x: pass over %#x with %d rounds", tr.In, p.In, len(p.Rounds))
+			}
+			for i, r := range p.Rounds {
+				if r.Key != raku[r.J-1] || r.Out >= r.Mod {
+					t.Errorf("trace of %#x: round %d has key %#x and output %#x mod %#x", tr.In, r.J, r.Key, r.Out, r.Mod)
+				}
+				if i == 0 {
+					continue
+				}
+				// Each round takes the previous round's output as one half.
+				prev := p.Rounds[i-1]
+				if tt.fein && (r.Right != prev.Out || r.Left != prev.Right) {
+					t.Errorf("FeinTrace(%#x): round %d does not follow from round %d", tr.In, r.J, prev.J)
+				}
+				if !tt.fein && (r.Left != prev.Out || r.Right != prev.Left) {
+					t.Errorf("FyndTrace(%#x): round %d does not follow from round %d", tr.In, r.J, prev.J)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package verify
+
+import (
+	"math/bits"
+	"sync"
+	"sync/atomic"
+)
+
+// sparseLimit is the number of values a set holds in a map before moving them
+// to a Bitset of the whole domain. A million map entries take a few tens of
+// MiB, well under the 512 MiB of the Bitset.
+const sparseLimit = 1 << 20
+
+// Bitset is a fixed-size set of bits that can be set from many goroutines at
+// once. A Bitset covering the full 32-bit domain takes 512 MiB, but the memory
+// is only committed by the operating system as its pages are first written.
+type Bitset struct {
+	words []uint64
+	size  uint64
+}
+
+// NewBitset returns a Bitset holding bits [0, size).
+func NewBitset(size uint64) *Bitset {
+
+	return &Bitset{
+		words: make([]uint64, (size+63)/64),
+		size:  size,
+	}
+}
+
+// Size returns the number of bits in the set.
+func (b *Bitset) Size() uint64 {
+
+	return b.size
+}
+
+// TestAndSet sets bit i and reports whether it was already set.
+func (b *Bitset) TestAndSet(i uint64) bool {
+
+	word := &b.words[i/64]
+	mask := uint64(1) << (i % 64)
+
+	for {
+		old := atomic.LoadUint64(word)
+		if old&mask != 0 {
+			return true
+		}
+		if atomic.CompareAndSwapUint64(word, old, old|mask) {
+			return false
+		}
+	}
+}
+
+// Test reports whether bit i is set.
+func (b *Bitset) Test(i uint64) bool {
+
+	return atomic.LoadUint64(&b.words[i/64])&(uint64(1)<<(i%64)) != 0
+}
+
+// Count returns the number of bits that are set. It must not be called while
+// other goroutines are still setting bits if an exact answer is needed.
+func (b *Bitset) Count() uint64 {
+
+	var n uint64
+	for i := range b.words {
+		n += uint64(bits.OnesCount64(atomic.LoadUint64(&b.words[i])))
+	}
+
+	return n
+}
+
+// set records values of the 32-bit domain from many goroutines at once. It
+// holds them in a map while there are few, and moves them to a Bitset of the
+// whole domain once there are limit of them.
+type set struct {
+	limit int
+
+	mu     sync.Mutex
+	sparse map[uint64]struct{}
+	bits   atomic.Value // *Bitset, once the set has grown
+}
+
+// newSet returns an empty set for about n values. Sets for more than
+// sparseLimit values start as a Bitset.
+func newSet(n uint64) *set {
+
+	s := &set{limit: sparseLimit, sparse: map[uint64]struct{}{}}
+	if n > sparseLimit {
+		s.grow()
+	}
+
+	return s
+}
+
+// grow moves the values of s to a Bitset. s.mu must be held, unless s is not
+// yet shared.
+func (s *set) grow() {
+
+	b := NewBitset(Domain)
+	for x := range s.sparse {
+		b.TestAndSet(x)
+	}
+	s.sparse = nil
+	s.bits.Store(b)
+}
+
+// TestAndSet adds x and reports whether it was already in the set.
+func (s *set) TestAndSet(x uint64) bool {
+
+	if b, ok := s.bits.Load().(*Bitset); ok {
+		return b.TestAndSet(x)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Another goroutine may have grown the set while this one waited.
+	if b, ok := s.bits.Load().(*Bitset); ok {
+		return b.TestAndSet(x)
+	}
+	if _, ok := s.sparse[x]; ok {
+		return true
+	}
+	s.sparse[x] = struct{}{}
+	if len(s.sparse) >= s.limit {
+		s.grow()
+	}
+
+	return false
+}
@@ -0,0 +1,331 @@
+/*
+Package verify checks that the @p scrambler is a bijection.
+
+Every input is scrambled with ob.Fein32, its output is recorded, and the
+output is unscrambled with ob.Fynd32. An output that has been seen before is a
+collision, an output that does not unscramble to its input is a round-trip
+failure. The work is sharded across goroutines, so the full domain of 2^32
+points can be checked in minutes on a multi-core machine using 512 MiB of
+memory. Outputs are kept in a map until there are too many for it, so small
+runs take little memory.
+
+Range checks a contiguous range of inputs; a Verifier checks inputs one at a
+time, as used for random sampling.
+*/
+package verify
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/deelawn/urbit-gob/ob"
+)
+
+const (
+	// Domain is the number of values in the 32-bit domain of the scrambler.
+	Domain uint64 = 1 << 32
+
+	// DefaultMaxExamples is the number of collisions and round-trip failures
+	// kept in a Report when Options.MaxExamples is zero.
+	DefaultMaxExamples = 100
+
+	// shardSize is the number of consecutive inputs a worker checks between
+	// looking at the context and reporting progress.
+	shardSize uint64 = 1 << 16
+)
+
+// Options configures a verification run. The zero value checks ob.Fein32 and
+// ob.Fynd32 using one worker per CPU.
+type Options struct {
+	// Workers is the number of goroutines to shard the work across. Zero or
+	// less means runtime.NumCPU().
+	Workers int
+	// MaxExamples bounds the number of collisions and round-trip failures
+	// recorded in the Report; all of them are still counted.
+	MaxExamples int
+	// Progress, if set, is called with the running total of checked inputs
+	// after each shard. It is called from worker goroutines and must be safe
+	// for concurrent use.
+	Progress func(checked uint64)
+	// Forward and Inverse are the permutation under test and its inverse.
+	// They default to ob.Fein32 and ob.Fynd32.
+	Forward func(uint32) uint32
+	Inverse func(uint32) uint32
+}
+
+// Collision records an input whose output had already been produced by an
+// earlier input.
+type Collision struct {
+	Input  uint32
+	Output uint32
+}
+
+func (c Collision) String() string {
+
+	return fmt.Sprintf("collision: %d -> %d, which was already taken", c.Input, c.Output)
+}
+
+// RoundTripFailure records an input whose output did not map back to it.
+type RoundTripFailure struct {
+	Input  uint32
+	Output uint32
+	Back   uint32
+}
+
+func (f RoundTripFailure) String() string {
+
+	return fmt.Sprintf("round-trip failure: %d -> %d -> %d", f.Input, f.Output, f.Back)
+}
+
+// Report is the outcome of a verification run.
+type Report struct {
+	// Lo and Hi bound the checked inputs, [Lo, Hi).
+	Lo, Hi uint64
+	// Checked is the number of inputs checked.
+	Checked uint64
+	// Duplicates is the number of inputs passed to Verifier.Check more than
+	// once; they are skipped rather than counted as collisions.
+	Duplicates uint64
+	// Distinct is the number of distinct outputs produced.
+	Distinct uint64
+	// NumCollisions and NumRoundTripFailures count all failures, while
+	// Collisions and RoundTripFailures hold at most Options.MaxExamples of each.
+	NumCollisions        uint64
+	NumRoundTripFailures uint64
+	Collisions           []Collision
+	RoundTripFailures    []RoundTripFailure
+	// Coverage is Distinct as a fraction of the number of inputs checked; a
+	// bijection covers exactly 1.
+	Coverage float64
+	// Complete is false if the run was cancelled before every input was
+	// checked.
+	Complete bool
+	Elapsed  time.Duration
+}
+
+// OK reports whether the run completed without collisions or round-trip
+// failures.
+func (r Report) OK() bool {
+
+	return r.Complete && r.NumCollisions == 0 && r.NumRoundTripFailures == 0
+}
+
+// Verifier checks inputs one at a time. It is safe for concurrent use.
+type Verifier struct {
+	opts    Options
+	outputs *set
+	inputs  *set
+	start   time.Time
+	lo, hi  uint64
+
+	checked    uint64
+	duplicates uint64
+	collisions uint64
+	failures   uint64
+
+	mu       sync.Mutex
+	examples struct {
+		collisions []Collision
+		failures   []RoundTripFailure
+	}
+}
+
+// New returns a Verifier with an empty record of outputs.
+func New(opts Options) *Verifier {
+
+	return newVerifier(opts, 0)
+}
+
+// newVerifier returns a Verifier with room for about n inputs.
+func newVerifier(opts Options, n uint64) *Verifier {
+
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.MaxExamples == 0 {
+		opts.MaxExamples = DefaultMaxExamples
+	}
+	if opts.Forward == nil {
+		opts.Forward = ob.Fein32
+	}
+	if opts.Inverse == nil {
+		opts.Inverse = ob.Fynd32
+	}
+
+	return &Verifier{
+		opts:    opts,
+		outputs: newSet(n),
+		inputs:  newSet(0),
+		start:   time.Now(),
+		lo:      Domain,
+	}
+}
+
+// Check scrambles x, records its output and verifies that it round-trips. It
+// returns false if x had already been checked by this Verifier, in which case
+// it is counted as a duplicate and not checked again.
+func (v *Verifier) Check(x uint32) bool {
+
+	if v.inputs.TestAndSet(uint64(x)) {
+		atomic.AddUint64(&v.duplicates, 1)
+		return false
+	}
+
+	storeMin(&v.lo, uint64(x))
+	storeMax(&v.hi, uint64(x)+1)
+	v.check(x)
+	atomic.AddUint64(&v.checked, 1)
+
+	return true
+}
+
+func storeMin(addr *uint64, v uint64) {
+
+	for old := atomic.LoadUint64(addr); v < old; old = atomic.LoadUint64(addr) {
+		if atomic.CompareAndSwapUint64(addr, old, v) {
+			return
+		}
+	}
+}
+
+func storeMax(addr *uint64, v uint64) {
+
+	for old := atomic.LoadUint64(addr); v > old; old = atomic.LoadUint64(addr) {
+		if atomic.CompareAndSwapUint64(addr, old, v) {
+			return
+		}
+	}
+}
+
+func (v *Verifier) check(x uint32) {
+
+	y := v.opts.Forward(x)
+
+	if v.outputs.TestAndSet(uint64(y)) {
+		if atomic.AddUint64(&v.collisions, 1) <= uint64(v.opts.MaxExamples) {
+			v.mu.Lock()
+			v.examples.collisions = append(v.examples.collisions, Collision{Input: x, Output: y})
+			v.mu.Unlock()
+		}
+	}
+
+	if back := v.opts.Inverse(y); back != x {
+		if atomic.AddUint64(&v.failures, 1) <= uint64(v.opts.MaxExamples) {
+			v.mu.Lock()
+			v.examples.failures = append(v.examples.failures, RoundTripFailure{Input: x, Output: y, Back: back})
+			v.mu.Unlock()
+		}
+	}
+}
+
+// Report summarises the inputs checked so far.
+func (v *Verifier) Report() Report {
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	r := Report{
+		Lo:                   atomic.LoadUint64(&v.lo),
+		Hi:                   atomic.LoadUint64(&v.hi),
+		Checked:              atomic.LoadUint64(&v.checked),
+		Duplicates:           atomic.LoadUint64(&v.duplicates),
+		NumCollisions:        atomic.LoadUint64(&v.collisions),
+		NumRoundTripFailures: atomic.LoadUint64(&v.failures),
+		Collisions:           append([]Collision(nil), v.examples.collisions...),
+		RoundTripFailures:    append([]RoundTripFailure(nil), v.examples.failures...),
+		Complete:             true,
+		Elapsed:              time.Since(v.start),
+	}
+
+	// Every check either sets a new output bit or is a collision.
+	r.Distinct = r.Checked - r.NumCollisions
+
+	if r.Lo > r.Hi {
+		r.Lo = r.Hi
+	}
+	if r.Checked > 0 {
+		r.Coverage = float64(r.Distinct) / float64(r.Checked)
+	}
+
+	return r
+}
+
+// Full checks every input in the 32-bit domain.
+func Full(ctx context.Context, opts Options) (Report, error) {
+
+	return Range(ctx, 0, Domain, opts)
+}
+
+// Range checks every input in [lo, hi), sharding the work across
+// opts.Workers goroutines. If ctx is cancelled the partial Report is returned
+// along with the context's error.
+func Range(ctx context.Context, lo, hi uint64, opts Options) (Report, error) {
+
+	if lo > hi || hi > Domain {
+		return Report{}, fmt.Errorf("invalid range [%d, %d): lo and hi must satisfy 0 <= lo <= hi <= %d", lo, hi, Domain)
+	}
+
+	v := newVerifier(opts, hi-lo)
+	v.lo, v.hi = lo, hi
+
+	var (
+		next = lo
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+	)
+
+	// shard hands out the next block of inputs, or reports that there are
+	// none left.
+	shard := func() (uint64, uint64, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if next >= hi || ctx.Err() != nil {
+			return 0, 0, false
+		}
+
+		start := next
+		end := start + shardSize
+		if end > hi {
+			end = hi
+		}
+		next = end
+
+		return start, end, true
+	}
+
+	for w := 0; w < v.opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				start, end, ok := shard()
+				if !ok {
+					return
+				}
+
+				for x := start; x < end; x++ {
+					v.check(uint32(x))
+				}
+
+				checked := atomic.AddUint64(&v.checked, end-start)
+				if v.opts.Progress != nil {
+					v.opts.Progress(checked)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	r := v.Report()
+	r.Lo, r.Hi = lo, hi
+	r.Complete = r.Checked == hi-lo
+
+	return r, ctx.Err()
+}
@@ -0,0 +1,177 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRange(t *testing.T) {
+	testCases := []struct {
+		name   string
+		lo, hi uint64
+	}{
+		{"galaxies and stars", 0, 0x10000},
+		{"first planets", 0x10000, 0x50000},
+		{"across the star boundary", 0xff00, 0x10100},
+		{"last planets", Domain - 0x40000, Domain},
+		{"empty", 0x10000, 0x10000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := Range(context.Background(), tc.lo, tc.hi, Options{})
+			if err != nil {
+				t.Fatalf("Range failed: %v", err)
+			}
+			if !r.OK() {
+				t.Fatalf("expected a clean report, got %+v", r)
+			}
+			if r.Checked != tc.hi-tc.lo || r.Distinct != r.Checked {
+				t.Errorf("checked %d with %d distinct outputs, want %d", r.Checked, r.Distinct, tc.hi-tc.lo)
+			}
+			if r.Checked > 0 && r.Coverage != 1 {
+				t.Errorf("coverage = %v, want 1", r.Coverage)
+			}
+		})
+	}
+}
+
+func TestRangeInvalid(t *testing.T) {
+	if _, err := Range(context.Background(), 2, 1, Options{}); err == nil {
+		t.Error("expected an error for lo > hi")
+	}
+	_, err := Range(context.Background(), 0, Domain+1, Options{})
+	if err == nil {
+		t.Fatal("expected an error for hi beyond the domain")
+	}
+
+	// hi is exclusive, so the domain itself is the largest hi allowed.
+	if want := fmt.Sprintf("hi <= %d", Domain); !strings.Contains(err.Error(), want) {
+		t.Errorf("got %q, want it to state %q", err, want)
+	}
+}
+
+func TestRangeDetectsFailures(t *testing.T) {
+	opts := Options{
+		MaxExamples: 3,
+		// Halving maps pairs of inputs to the same output and cannot be undone.
+		Forward: func(x uint32) uint32 { return x / 2 },
+		Inverse: func(y uint32) uint32 { return y * 2 },
+	}
+
+	r, err := Range(context.Background(), 0, 1000, opts)
+	if err != nil {
+		t.Fatalf("Range failed: %v", err)
+	}
+	if r.OK() {
+		t.Fatal("expected failures to be reported")
+	}
+	if r.NumCollisions != 500 || r.NumRoundTripFailures != 500 {
+		t.Errorf("got %d collisions and %d round-trip failures, want 500 of each",
+			r.NumCollisions, r.NumRoundTripFailures)
+	}
+	if len(r.Collisions) != 3 || len(r.RoundTripFailures) != 3 {
+		t.Errorf("kept %d and %d examples, want 3 of each", len(r.Collisions), len(r.RoundTripFailures))
+	}
+	if r.Distinct != 500 || r.Coverage != 0.5 {
+		t.Errorf("got %d distinct outputs and coverage %v, want 500 and 0.5", r.Distinct, r.Coverage)
+	}
+}
+
+func TestRangeCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var calls int32
+	opts := Options{
+		Workers: 1,
+		Progress: func(uint64) {
+			if atomic.AddInt32(&calls, 1) == 2 {
+				cancel()
+			}
+		},
+	}
+
+	r, err := Range(ctx, 0, 100*shardSize, opts)
+	if err != context.Canceled {
+		t.Fatalf("got error %v, want context.Canceled", err)
+	}
+	if r.Complete || r.OK() {
+		t.Error("a cancelled run must not be complete")
+	}
+	if r.Checked != 2*shardSize {
+		t.Errorf("checked %d inputs, want %d", r.Checked, 2*shardSize)
+	}
+}
+
+func TestVerifierCheck(t *testing.T) {
+	v := New(Options{})
+
+	for _, x := range []uint32{0x10000, 5, 0xffffffff, 5} {
+		v.Check(x)
+	}
+
+	r := v.Report()
+	if !r.OK() {
+		t.Fatalf("expected a clean report, got %+v", r)
+	}
+	if r.Checked != 3 || r.Duplicates != 1 {
+		t.Errorf("checked %d with %d duplicates, want 3 and 1", r.Checked, r.Duplicates)
+	}
+	if r.Lo != 5 || r.Hi != Domain {
+		t.Errorf("got range [%d, %d), want [5, %d)", r.Lo, r.Hi, Domain)
+	}
+}
+
+func TestBitset(t *testing.T) {
+	b := NewBitset(130)
+
+	for _, i := range []uint64{0, 63, 64, 129} {
+		if b.TestAndSet(i) {
+			t.Errorf("bit %d reported as already set", i)
+		}
+		if !b.TestAndSet(i) || !b.Test(i) {
+			t.Errorf("bit %d not set", i)
+		}
+	}
+
+	if b.Test(1) {
+		t.Error("bit 1 should not be set")
+	}
+	if n := b.Count(); n != 4 {
+		t.Errorf("Count() = %d, want 4", n)
+	}
+}
+
+func TestSet(t *testing.T) {
+	s := newSet(0)
+	s.limit = 4
+
+	for i, x := range []uint64{0, 7, Domain - 1, 7} {
+		if got := s.TestAndSet(x); got != (i == 3) {
+			t.Errorf("%d: TestAndSet = %v while sparse", x, got)
+		}
+	}
+	if _, ok := s.bits.Load().(*Bitset); ok {
+		t.Fatal("set grew before reaching its limit")
+	}
+
+	s.TestAndSet(42)
+	if _, ok := s.bits.Load().(*Bitset); !ok || s.sparse != nil {
+		t.Fatal("set did not grow at its limit")
+	}
+	for _, x := range []uint64{0, 7, 42, Domain - 1} {
+		if !s.TestAndSet(x) {
+			t.Errorf("%d lost when the set grew", x)
+		}
+	}
+	if s.TestAndSet(43) {
+		t.Error("43 reported as already set")
+	}
+
+	if _, ok := newSet(sparseLimit + 1).bits.Load().(*Bitset); !ok {
+		t.Error("a set for many values should start as a Bitset")
+	}
+}
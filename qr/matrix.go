@@ -0,0 +1,324 @@
+package qr
+
+// matrix is a symbol being built. fn marks the modules of function patterns
+// and format and version information, which hold no data and are not masked.
+type matrix struct {
+	size     int
+	dark, fn []bool
+}
+
+// newMatrix returns a symbol of version v with its function patterns drawn
+// and the areas for format and version information reserved.
+func newMatrix(v int) *matrix {
+
+	size := 4*v + 17
+	m := &matrix{size: size, dark: make([]bool, size*size), fn: make([]bool, size*size)}
+
+	for i := 0; i < size; i++ {
+		m.set(6, i, i%2 == 0)
+		m.set(i, 6, i%2 == 0)
+	}
+
+	m.finder(3, 3)
+	m.finder(size-4, 3)
+	m.finder(3, size-4)
+
+	pos := alignmentPositions(v)
+	last := len(pos) - 1
+	for i, x := range pos {
+		for j, y := range pos {
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+			m.alignment(x, y)
+		}
+	}
+
+	m.format(0, 0)
+	m.version(v)
+
+	return m
+}
+
+func (m *matrix) set(x, y int, dark bool) {
+
+	m.dark[y*m.size+x] = dark
+	m.fn[y*m.size+x] = true
+}
+
+// finder draws a finder pattern centred on x, y with its separator.
+func (m *matrix) finder(x, y int) {
+
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			if x+dx < 0 || x+dx >= m.size || y+dy < 0 || y+dy >= m.size {
+				continue
+			}
+			d := chebyshev(dx, dy)
+			m.set(x+dx, y+dy, d != 2 && d != 4)
+		}
+	}
+}
+
+// alignment draws an alignment pattern centred on x, y.
+func (m *matrix) alignment(x, y int) {
+
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			m.set(x+dx, y+dy, chebyshev(dx, dy) != 1)
+		}
+	}
+}
+
+func chebyshev(dx, dy int) int {
+
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	if dx > dy {
+		return dx
+	}
+
+	return dy
+}
+
+// alignmentPositions returns the rows and columns of the centres of the
+// alignment patterns of version v, ascending.
+func alignmentPositions(v int) []int {
+
+	if v == 1 {
+		return nil
+	}
+
+	n := v/7 + 2
+	step := 26
+	if v != 32 {
+		step = (v*4 + n*2 + 1) / (n*2 - 2) * 2
+	}
+
+	pos := make([]int, n)
+	pos[0] = 6
+	for i, p := n-1, 4*v+10; i >= 1; i, p = i-1, p-step {
+		pos[i] = p
+	}
+
+	return pos
+}
+
+// formatBits returns the format information for level l and mask k, with its
+// BCH error correction.
+func formatBits(l Level, k int) uint {
+
+	d := levelBits[l]<<3 | uint(k)
+	r := d
+	for i := 0; i < 10; i++ {
+		r = r<<1 ^ (r>>9)*0x537
+	}
+
+	return (d<<10 | r&0x3ff) ^ 0x5412
+}
+
+// versionBits returns the version information for version v, with its BCH
+// error correction.
+func versionBits(v int) uint {
+
+	r := uint(v)
+	for i := 0; i < 12; i++ {
+		r = r<<1 ^ (r>>11)*0x1f25
+	}
+
+	return uint(v)<<12 | r&0xfff
+}
+
+// format draws both copies of the format information, and the dark module.
+func (m *matrix) format(l Level, k int) {
+
+	bits := formatBits(l, k)
+	bit := func(i int) bool { return bits>>uint(i)&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		m.set(8, i, bit(i))
+	}
+	m.set(8, 7, bit(6))
+	m.set(8, 8, bit(7))
+	m.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		m.set(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		m.set(m.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		m.set(8, m.size-15+i, bit(i))
+	}
+	m.set(8, m.size-8, true)
+}
+
+// version draws both copies of the version information, which only versions
+// 7 and above have.
+func (m *matrix) version(v int) {
+
+	if v < 7 {
+		return
+	}
+
+	bits := versionBits(v)
+	for i := 0; i < 18; i++ {
+		dark := bits>>uint(i)&1 != 0
+		a, b := m.size-11+i%3, i/3
+		m.set(a, b, dark)
+		m.set(b, a, dark)
+	}
+}
+
+// place draws codewords in the modules left free, in pairs of columns from
+// the right, alternately upwards and downwards, skipping the vertical timing
+// pattern. Modules left over are remainder bits and stay light.
+func (m *matrix) place(codewords []byte) {
+
+	i := 0
+	for right := m.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < m.size; vert++ {
+			y := vert
+			if upward {
+				y = m.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if m.fn[y*m.size+x] || i >= len(codewords)*8 {
+					continue
+				}
+				m.dark[y*m.size+x] = codewords[i/8]>>uint(7-i%8)&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+// masked reports whether mask k inverts the module at x, y.
+func masked(k, x, y int) bool {
+
+	switch k {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// mask applies mask k to the modules that are not function patterns. Applying
+// it twice removes it.
+func (m *matrix) mask(k int) {
+
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			if !m.fn[y*m.size+x] && masked(k, x, y) {
+				m.dark[y*m.size+x] = !m.dark[y*m.size+x]
+			}
+		}
+	}
+}
+
+// Penalty weights for runs, blocks, finder-like patterns and imbalance.
+const (
+	penaltyN1 = 3
+	penaltyN2 = 3
+	penaltyN3 = 40
+	penaltyN4 = 10
+)
+
+// finderLike are the patterns of a finder's row with four light modules on
+// one side, with dark as '1'.
+var finderLike = [2]string{"10111010000", "00001011101"}
+
+// penalty scores how hard the symbol would be to scan; the mask giving the
+// lowest score is chosen.
+func (m *matrix) penalty() int {
+
+	p := 0
+
+	// Each row and column is checked with four light modules on either side,
+	// as the quiet zone, for finder-like patterns at its ends.
+	line := make([]byte, m.size+8)
+	for i := range line {
+		line[i] = '0'
+	}
+	for _, columns := range []bool{false, true} {
+		for i := 0; i < m.size; i++ {
+			for j := 0; j < m.size; j++ {
+				x, y := j, i
+				if columns {
+					x, y = i, j
+				}
+				line[j+4] = '0'
+				if m.dark[y*m.size+x] {
+					line[j+4] = '1'
+				}
+			}
+
+			run := 1
+			for j := 5; j <= m.size+4; j++ {
+				if j < m.size+4 && line[j] == line[j-1] {
+					run++
+					continue
+				}
+				if run >= 5 {
+					p += penaltyN1 + run - 5
+				}
+				run = 1
+			}
+
+			for j := 0; j+len(finderLike[0]) <= len(line); j++ {
+				for _, f := range finderLike {
+					if string(line[j:j+len(f)]) == f {
+						p += penaltyN3
+					}
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			d := m.dark[y*m.size+x]
+			if d {
+				dark++
+			}
+			if x+1 < m.size && y+1 < m.size &&
+				d == m.dark[y*m.size+x+1] && d == m.dark[(y+1)*m.size+x] && d == m.dark[(y+1)*m.size+x+1] {
+				p += penaltyN2
+			}
+		}
+	}
+
+	// Ten points for every full 5% that dark modules are away from half.
+	total := m.size * m.size
+	k := dark*20 - total*10
+	if k < 0 {
+		k = -k
+	}
+	p += k / total * penaltyN4
+
+	return p
+}
@@ -0,0 +1,382 @@
+/*
+Package qr encodes text as QR codes, following ISO/IEC 18004, and renders them
+for a terminal, as an image or as SVG.
+
+Text is encoded in a single segment, in numeric, alphanumeric or byte mode,
+whichever is the most compact that can hold it, in the smallest version that
+fits at the error correction level asked for. The mask is chosen by the
+standard's penalty rules.
+*/
+package qr
+
+import (
+	"fmt"
+	"strings"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+// Level is an error correction level.
+type Level int
+
+// The levels, by the share of codewords that can be recovered.
+const (
+	L Level = iota // 7%
+	M              // 15%
+	Q              // 25%
+	H              // 30%
+)
+
+var levelNames = [...]string{"L", "M", "Q", "H"}
+
+// levelBits are the levels as written in the format information.
+var levelBits = [...]uint{1, 0, 3, 2}
+
+func (l Level) String() string {
+
+	if l < L || l > H {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+
+	return levelNames[l]
+}
+
+// ParseLevel returns the level named by s, which is L, M, Q or H in either
+// case.
+func ParseLevel(s string) (Level, error) {
+
+	for i, n := range levelNames {
+		if strings.EqualFold(s, n) {
+			return Level(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf(ugi.ErrInvalidLevel, s)
+}
+
+const (
+	minVersion = 1
+	maxVersion = 40
+)
+
+// ecPerBlock is the number of error correction codewords in each block, and
+// numBlocks the number of blocks, by level and version.
+var ecPerBlock = [4][maxVersion + 1]int{
+	{0, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{0, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{0, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+var numBlocks = [4][maxVersion + 1]int{
+	{0, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{0, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{0, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+// rawModules returns the number of modules in a symbol of version v that hold
+// codewords or remainder bits, that is those not taken by function patterns
+// or format and version information.
+func rawModules(v int) int {
+
+	n := (16*v+128)*v + 64
+	if v >= 2 {
+		a := v/7 + 2
+		n -= (25*a-10)*a - 55
+		if v >= 7 {
+			n -= 36
+		}
+	}
+
+	return n
+}
+
+// dataCodewords returns the number of data codewords in a symbol of version v
+// at level l.
+func dataCodewords(v int, l Level) int {
+
+	return rawModules(v)/8 - ecPerBlock[l][v]*numBlocks[l][v]
+}
+
+// Code is a QR code, a square of Size by Size modules.
+type Code struct {
+	Version int
+	Level   Level
+	Mask    int
+	Size    int
+
+	dark []bool
+}
+
+// Dark reports whether the module in column x and row y is dark. Modules
+// outside of the symbol, as in its quiet zone, are light.
+func (c *Code) Dark(x, y int) bool {
+
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+
+	return c.dark[y*c.Size+x]
+}
+
+// Encode returns the smallest QR code that holds text at level l.
+func Encode(text string, l Level) (*Code, error) {
+
+	if l < L || l > H {
+		return nil, fmt.Errorf(ugi.ErrInvalidLevel, l)
+	}
+
+	m := modeFor(text)
+	v := minVersion
+	for ; v <= maxVersion; v++ {
+		if 4+m.countBits(v)+m.payloadBits(len(text)) <= dataCodewords(v, l)*8 {
+			break
+		}
+	}
+	if v > maxVersion {
+		return nil, fmt.Errorf(ugi.ErrQRTooLong, len(text), l)
+	}
+
+	codewords := interleave(dataBytes(text, m, v, l), v, l)
+
+	mx := newMatrix(v)
+	mx.place(codewords)
+
+	best, bestPenalty := 0, -1
+	for k := 0; k < 8; k++ {
+		mx.mask(k)
+		mx.format(l, k)
+		if p := mx.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = k, p
+		}
+		mx.mask(k)
+	}
+	mx.mask(best)
+	mx.format(l, best)
+
+	return &Code{Version: v, Level: l, Mask: best, Size: mx.size, dark: mx.dark}, nil
+}
+
+// mode is a way of packing characters into bits.
+type mode struct {
+	indicator uint
+
+	// count is the width of the character count for versions 1-9, 10-26 and
+	// 27-40.
+	count [3]int
+}
+
+var (
+	numericMode      = mode{1, [3]int{10, 12, 14}}
+	alphanumericMode = mode{2, [3]int{9, 11, 13}}
+	byteMode         = mode{4, [3]int{8, 16, 16}}
+)
+
+const alphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// modeFor returns the most compact mode that can hold text.
+func modeFor(text string) mode {
+
+	numeric, alnum := true, true
+	for i := 0; i < len(text); i++ {
+		if text[i] < '0' || text[i] > '9' {
+			numeric = false
+		}
+		if strings.IndexByte(alphanumeric, text[i]) < 0 {
+			alnum = false
+		}
+	}
+
+	switch {
+	case numeric:
+		return numericMode
+	case alnum:
+		return alphanumericMode
+	default:
+		return byteMode
+	}
+}
+
+func (m mode) countBits(v int) int {
+
+	switch {
+	case v <= 9:
+		return m.count[0]
+	case v <= 26:
+		return m.count[1]
+	default:
+		return m.count[2]
+	}
+}
+
+// payloadBits returns the number of bits taken by n characters.
+func (m mode) payloadBits(n int) int {
+
+	switch m {
+	case numericMode:
+		return 10*(n/3) + [3]int{0, 4, 7}[n%3]
+	case alphanumericMode:
+		return 11*(n/2) + 6*(n%2)
+	default:
+		return 8 * n
+	}
+}
+
+// bitBuffer is a sequence of bits, packed most significant first.
+type bitBuffer struct {
+	data []byte
+	n    int
+}
+
+func (b *bitBuffer) write(v uint, bits int) {
+
+	for i := bits - 1; i >= 0; i-- {
+		if b.n%8 == 0 {
+			b.data = append(b.data, 0)
+		}
+		if v>>uint(i)&1 != 0 {
+			b.data[b.n/8] |= 0x80 >> uint(b.n%8)
+		}
+		b.n++
+	}
+}
+
+// dataBytes returns the data codewords of a symbol of version v at level l
+// holding text in mode m, with the terminator and padding.
+func dataBytes(text string, m mode, v int, l Level) []byte {
+
+	var b bitBuffer
+	b.write(m.indicator, 4)
+	b.write(uint(len(text)), m.countBits(v))
+
+	switch m {
+	case numericMode:
+		for i := 0; i < len(text); i += 3 {
+			group := text[i:]
+			if len(group) > 3 {
+				group = group[:3]
+			}
+			n := uint(0)
+			for j := 0; j < len(group); j++ {
+				n = n*10 + uint(group[j]-'0')
+			}
+			b.write(n, 3*len(group)+1)
+		}
+	case alphanumericMode:
+		for i := 0; i < len(text); i += 2 {
+			n := uint(strings.IndexByte(alphanumeric, text[i]))
+			if i+1 < len(text) {
+				b.write(n*45+uint(strings.IndexByte(alphanumeric, text[i+1])), 11)
+			} else {
+				b.write(n, 6)
+			}
+		}
+	default:
+		for i := 0; i < len(text); i++ {
+			b.write(uint(text[i]), 8)
+		}
+	}
+
+	capacity := dataCodewords(v, l) * 8
+	terminator := capacity - b.n
+	if terminator > 4 {
+		terminator = 4
+	}
+	b.write(0, terminator)
+	b.write(0, (8-b.n%8)%8)
+	for pad := uint(0xec); b.n < capacity; pad ^= 0xec ^ 0x11 {
+		b.write(pad, 8)
+	}
+
+	return b.data
+}
+
+// interleave splits data into the blocks of a symbol of version v at level l,
+// appends error correction to each, and interleaves them in the order they
+// are placed in the symbol.
+func interleave(data []byte, v int, l Level) []byte {
+
+	nb, ec := numBlocks[l][v], ecPerBlock[l][v]
+	raw := rawModules(v) / 8
+
+	// The first blocks are short; the rest hold one more data codeword.
+	short, shortLen := nb-raw%nb, raw/nb
+	g := rsGenerator(ec)
+
+	blocks := make([][]byte, nb)
+	for i, k := 0, 0; i < nb; i++ {
+		n := shortLen - ec
+		if i >= short {
+			n++
+		}
+		blocks[i] = append(append([]byte(nil), data[k:k+n]...), rsRemainder(data[k:k+n], g)...)
+		k += n
+	}
+
+	out := make([]byte, 0, raw)
+	for i := 0; i <= shortLen-ec; i++ {
+		for _, b := range blocks {
+			if i < len(b)-ec {
+				out = append(out, b[i])
+			}
+		}
+	}
+	for i := 0; i < ec; i++ {
+		for _, b := range blocks {
+			out = append(out, b[len(b)-ec+i])
+		}
+	}
+
+	return out
+}
+
+// gfMul multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMul(x, y byte) byte {
+
+	var z byte
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x1d
+		z ^= (y >> uint(i) & 1) * x
+	}
+
+	return z
+}
+
+// rsGenerator returns the Reed-Solomon generator polynomial of degree n, the
+// product of (x - 2^i) for i in [0, n), without its leading coefficient and
+// highest power first.
+func rsGenerator(n int) []byte {
+
+	g := make([]byte, n)
+	g[n-1] = 1
+
+	root := byte(1)
+	for i := 0; i < n; i++ {
+		for j := range g {
+			g[j] = gfMul(g[j], root)
+			if j+1 < n {
+				g[j] ^= g[j+1]
+			}
+		}
+		root = gfMul(root, 2)
+	}
+
+	return g
+}
+
+// rsRemainder returns the error correction codewords of data for generator g.
+func rsRemainder(data, g []byte) []byte {
+
+	r := make([]byte, len(g))
+	for _, b := range data {
+		f := b ^ r[0]
+		copy(r, r[1:])
+		r[len(r)-1] = 0
+		for i := range r {
+			r[i] ^= gfMul(g[i], f)
+		}
+	}
+
+	return r
+}
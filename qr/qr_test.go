@@ -0,0 +1,338 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHelloWorld(t *testing.T) {
+
+	// The worked example of version 1-M from the standard's tutorials.
+	data := dataBytes("HELLO WORLD", alphanumericMode, 1, M)
+	want := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	if !bytes.Equal(data, want) {
+		t.Errorf("data: got %v, want %v", data, want)
+	}
+
+	ec := rsRemainder(data, rsGenerator(10))
+	wantEC := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if !bytes.Equal(ec, wantEC) {
+		t.Errorf("error correction: got %v, want %v", ec, wantEC)
+	}
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+
+	formats := []struct {
+		level Level
+		mask  int
+		want  uint
+	}{
+		{L, 0, 0x77c4},
+		{M, 0, 0x5412},
+		{Q, 0, 0x355f},
+		{H, 0, 0x1689},
+	}
+	for _, tt := range formats {
+		if got := formatBits(tt.level, tt.mask); got != tt.want {
+			t.Errorf("format %s-%d: got %015b, want %015b", tt.level, tt.mask, got, tt.want)
+		}
+	}
+
+	versions := []struct {
+		version int
+		want    uint
+	}{
+		{7, 0x07c94},
+		{8, 0x085bc},
+		{40, 0x28c69},
+	}
+	for _, tt := range versions {
+		if got := versionBits(tt.version); got != tt.want {
+			t.Errorf("version %d: got %018b, want %018b", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestAlignmentPositions(t *testing.T) {
+
+	tests := map[int][]int{
+		1:  nil,
+		2:  {6, 18},
+		7:  {6, 22, 38},
+		22: {6, 26, 50, 74, 98},
+		32: {6, 34, 60, 86, 112, 138},
+		36: {6, 24, 50, 76, 102, 128, 154},
+		40: {6, 30, 58, 86, 114, 142, 170},
+	}
+	for v, want := range tests {
+		if got := alignmentPositions(v); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("version %d: got %v, want %v", v, got, want)
+		}
+	}
+}
+
+func TestDataCodewords(t *testing.T) {
+
+	tests := []struct {
+		version int
+		want    [4]int
+	}{
+		{1, [4]int{19, 16, 13, 9}},
+		{5, [4]int{108, 86, 62, 46}},
+		{10, [4]int{274, 216, 154, 122}},
+		{40, [4]int{2956, 2334, 1666, 1276}},
+	}
+	for _, tt := range tests {
+		for l := L; l <= H; l++ {
+			if got := dataCodewords(tt.version, l); got != tt.want[l] {
+				t.Errorf("version %d-%s: got %d, want %d", tt.version, l, got, tt.want[l])
+			}
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+
+	tests := []struct {
+		text    string
+		level   Level
+		version int
+	}{
+		{"~zod", M, 1},
+		{"~sampel-palnet", M, 1},
+		{"~sampel-palnet", H, 2},
+		{"~ronler-talpur-sampel-palnet", L, 2},
+		{"1624961343", H, 1},
+		{"HELLO WORLD", Q, 1},
+		{"", L, 1},
+		{strings.Repeat("7", 7089), L, 40},
+		{strings.Repeat("A", 1852), H, 40},
+		{strings.Repeat("~", 2953), L, 40},
+	}
+	for _, tt := range tests {
+		c, err := Encode(tt.text, tt.level)
+		if err != nil {
+			t.Errorf("%.20q: %v", tt.text, err)
+			continue
+		}
+		if c.Version != tt.version {
+			t.Errorf("%.20q: got version %d, want %d", tt.text, c.Version, tt.version)
+		}
+		if got := decode(t, c); got != tt.text {
+			t.Errorf("%.20q: decoded as %.20q", tt.text, got)
+		}
+	}
+
+	if _, err := Encode(strings.Repeat("~", 2954), L); err == nil {
+		t.Error("expected an error for text too long")
+	}
+	if _, err := Encode("~zod", Level(4)); err == nil {
+		t.Error("expected an error for an invalid level")
+	}
+}
+
+func TestEncodeEveryVersion(t *testing.T) {
+
+	// The longest text in byte mode for each version and level should need
+	// exactly that version.
+	for l := L; l <= H; l++ {
+		for v := minVersion; v <= maxVersion; v++ {
+			n := (dataCodewords(v, l)*8 - 4 - byteMode.countBits(v)) / 8
+			text := make([]byte, n)
+			for i := range text {
+				text[i] = byte(i*7 + v)
+			}
+
+			c, err := Encode(string(text), l)
+			if err != nil {
+				t.Fatalf("%d-%s: %v", v, l, err)
+			}
+			if c.Version != v || c.Size != 4*v+17 {
+				t.Errorf("%d-%s: got version %d of size %d", v, l, c.Version, c.Size)
+			}
+			if got := decode(t, c); got != string(text) {
+				t.Errorf("%d-%s: decoded text differs", v, l)
+			}
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+
+	for _, s := range []string{"l", "M", "q", "H"} {
+		l, err := ParseLevel(s)
+		if err != nil || l.String() != strings.ToUpper(s) {
+			t.Errorf("%s: got %v, %v", s, l, err)
+		}
+	}
+	if _, err := ParseLevel("X"); err == nil {
+		t.Error("expected an error for X")
+	}
+}
+
+// decode reads c back as a scanner would once it has located the modules:
+// the format and version information, the codewords with the mask removed,
+// the error correction of each block and the segment.
+func decode(t *testing.T, c *Code) string {
+
+	t.Helper()
+
+	v := (c.Size - 17) / 4
+	fn := newMatrix(v)
+
+	var format uint
+	for i := 14; i >= 0; i-- {
+		var x, y int
+		switch {
+		case i <= 5:
+			x, y = 8, i
+		case i == 6:
+			x, y = 8, 7
+		case i == 7:
+			x, y = 8, 8
+		case i == 8:
+			x, y = 7, 8
+		default:
+			x, y = 14-i, 8
+		}
+		format <<= 1
+		if c.Dark(x, y) {
+			format |= 1
+		}
+	}
+	if format != formatBits(c.Level, c.Mask) {
+		t.Fatalf("format information %015b does not match %s-%d", format, c.Level, c.Mask)
+	}
+	for i := 0; i < 15; i++ {
+		x, y := c.Size-1-i, 8
+		if i >= 8 {
+			x, y = 8, c.Size-15+i
+		}
+		if c.Dark(x, y) != (format>>uint(i)&1 != 0) {
+			t.Fatalf("copies of the format information differ at bit %d", i)
+		}
+	}
+
+	if v >= 7 {
+		var version uint
+		for i := 17; i >= 0; i-- {
+			version <<= 1
+			if c.Dark(c.Size-11+i%3, i/3) {
+				version |= 1
+			}
+		}
+		if version != versionBits(v) {
+			t.Fatalf("version information %018b does not match %d", version, v)
+		}
+	}
+
+	raw := rawModules(v) / 8
+	codewords := make([]byte, raw)
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if (right+1)&2 == 0 {
+				y = c.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if fn.fn[y*c.Size+x] || i >= raw*8 {
+					continue
+				}
+				if c.Dark(x, y) != masked(c.Mask, x, y) {
+					codewords[i/8] |= 0x80 >> uint(i%8)
+				}
+				i++
+			}
+		}
+	}
+
+	nb, ec := numBlocks[c.Level][v], ecPerBlock[c.Level][v]
+	short, shortLen := nb-raw%nb, raw/nb
+	blocks := make([][]byte, nb)
+	k := 0
+	for i := 0; i <= shortLen-ec; i++ {
+		for b := range blocks {
+			if i < shortLen-ec || b >= short {
+				blocks[b] = append(blocks[b], codewords[k])
+				k++
+			}
+		}
+	}
+	var data []byte
+	g := rsGenerator(ec)
+	for b := range blocks {
+		var got []byte
+		for i := 0; i < ec; i++ {
+			got = append(got, codewords[k+i*nb+b])
+		}
+		if !bytes.Equal(got, rsRemainder(blocks[b], g)) {
+			t.Fatalf("block %d fails its error correction", b)
+		}
+		data = append(data, blocks[b]...)
+	}
+
+	r := bitReader{data: data}
+	var m mode
+	switch r.read(4) {
+	case numericMode.indicator:
+		m = numericMode
+	case alphanumericMode.indicator:
+		m = alphanumericMode
+	case byteMode.indicator:
+		m = byteMode
+	default:
+		t.Fatal("unknown mode")
+	}
+
+	n := int(r.read(m.countBits(v)))
+	var s strings.Builder
+	switch m {
+	case numericMode:
+		for ; n > 0; n -= 3 {
+			digits := n
+			if digits > 3 {
+				digits = 3
+			}
+			fmt.Fprintf(&s, "%0*d", digits, r.read(3*digits+1))
+		}
+	case alphanumericMode:
+		for ; n > 1; n -= 2 {
+			pair := r.read(11)
+			s.WriteByte(alphanumeric[pair/45])
+			s.WriteByte(alphanumeric[pair%45])
+		}
+		if n == 1 {
+			s.WriteByte(alphanumeric[r.read(6)])
+		}
+	default:
+		for ; n > 0; n-- {
+			s.WriteByte(byte(r.read(8)))
+		}
+	}
+
+	return s.String()
+}
+
+type bitReader struct {
+	data []byte
+	n    int
+}
+
+func (r *bitReader) read(bits int) uint {
+
+	var v uint
+	for i := 0; i < bits; i++ {
+		v = v<<1 | uint(r.data[r.n/8]>>uint(7-r.n%8)&1)
+		r.n++
+	}
+
+	return v
+}
@@ -0,0 +1,100 @@
+package qr
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// QuietZone is the width in modules of the light border that scanners need
+// around a symbol.
+const QuietZone = 4
+
+// Text renders c for a terminal with a border of quiet modules, two rows of
+// modules to a line in Unicode half blocks. Blocks are drawn for the light
+// modules, to suit light text on a dark background; with invert they are
+// drawn for the dark modules instead.
+func (c *Code) Text(quiet int, invert bool) string {
+
+	end := c.Size + quiet
+	ink := func(x, y int) bool {
+		return y < end && c.Dark(x, y) == invert
+	}
+
+	var b strings.Builder
+	for y := -quiet; y < end; y += 2 {
+		for x := -quiet; x < end; x++ {
+			top, bottom := ink(x, y), ink(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
+
+// Image renders c in black and white, each module scale pixels square, with
+// a border of quiet modules.
+func (c *Code) Image(scale, quiet int) image.Image {
+
+	n := (c.Size + 2*quiet) * scale
+	img := image.NewPaletted(image.Rect(0, 0, n, n), color.Palette{color.White, color.Black})
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			if c.Dark(x/scale-quiet, y/scale-quiet) {
+				img.Pix[y*img.Stride+x] = 1
+			}
+		}
+	}
+
+	return img
+}
+
+// PNG writes c to w as a PNG image, as rendered by Image.
+func (c *Code) PNG(w io.Writer, scale, quiet int) error {
+
+	return png.Encode(w, c.Image(scale, quiet))
+}
+
+// SVG writes c to w as an SVG image scale pixels to a module, with a border
+// of quiet modules. The dark modules are drawn as a single path, a rectangle
+// for each run of them in a row.
+func (c *Code) SVG(w io.Writer, scale, quiet int) error {
+
+	n := c.Size + 2*quiet
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n",
+		n*scale, n*scale, n, n)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/>`+"\n", n, n)
+	b.WriteString(`<path fill="#000" d="`)
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; {
+			if !c.Dark(x, y) {
+				x++
+				continue
+			}
+			start := x
+			for x < c.Size && c.Dark(x, y) {
+				x++
+			}
+			fmt.Fprintf(&b, "M%d %dh%dv1h-%dz", start+quiet, y+quiet, x-start, x-start)
+		}
+	}
+	b.WriteString("\"/>\n</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
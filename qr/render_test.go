@@ -0,0 +1,117 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestText(t *testing.T) {
+
+	c, err := Encode("~sampel-palnet", M)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, invert := range []bool{false, true} {
+		text := c.Text(QuietZone, invert)
+		lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+		width := c.Size + 2*QuietZone
+		if len(lines) != (width+1)/2 {
+			t.Fatalf("invert %v: got %d lines, want %d", invert, len(lines), (width+1)/2)
+		}
+
+		for i, line := range lines {
+			if n := utf8.RuneCountInString(line); n != width {
+				t.Fatalf("invert %v: line %d has %d characters, want %d", invert, i, n, width)
+			}
+			for j, r := range []rune(line) {
+				x, y := j-QuietZone, 2*i-QuietZone
+				top, bottom := r == '█' || r == '▀', r == '█' || r == '▄'
+				if top != (c.Dark(x, y) == invert) {
+					t.Fatalf("invert %v: wrong module at %d, %d", invert, x, y)
+				}
+				if y+1 < c.Size+QuietZone && bottom != (c.Dark(x, y+1) == invert) {
+					t.Fatalf("invert %v: wrong module at %d, %d", invert, x, y+1)
+				}
+			}
+		}
+	}
+}
+
+func TestPNG(t *testing.T) {
+
+	c, err := Encode("~sampel-palnet", Q)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	if err := c.PNG(&b, 3, 2); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := (c.Size + 4) * 3
+	if img.Bounds().Dx() != n || img.Bounds().Dy() != n {
+		t.Fatalf("got %v, want %d pixels square", img.Bounds(), n)
+	}
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			r, _, _, _ := img.At(x, y).RGBA()
+			if (r == 0) != c.Dark(x/3-2, y/3-2) {
+				t.Fatalf("wrong pixel at %d, %d", x, y)
+			}
+		}
+	}
+}
+
+func TestSVG(t *testing.T) {
+
+	c, err := Encode("~zod", L)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := c.SVG(&b, 10, QuietZone); err != nil {
+		t.Fatal(err)
+	}
+	svg := b.String()
+
+	for _, want := range []string{
+		`width="290" height="290" viewBox="0 0 29 29"`,
+		// The top row of the upper finder patterns.
+		"M4 4h7v1h-7z",
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG does not contain %q:\n%s", want, svg)
+		}
+	}
+
+	dark := 0
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Dark(x, y) {
+				dark++
+			}
+		}
+	}
+	runs := 0
+	for _, run := range strings.Split(svg, "M")[1:] {
+		var x, y, n int
+		if _, err := fmt.Sscanf(run, "%d %dh%d", &x, &y, &n); err != nil {
+			t.Fatalf("cannot parse %q: %v", run, err)
+		}
+		runs += n
+	}
+	if runs != dark {
+		t.Errorf("paths cover %d modules, want %d", runs, dark)
+	}
+}
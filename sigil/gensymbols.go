@@ -0,0 +1,71 @@
+//go:build ignore
+// +build ignore
+
+// gensymbols writes symbols.go, the symbols built into the sigil package, from
+// the symbol data of sigil-js: a JSON object mapping each of the 512 syllables
+// to its symbol as an SVG element tree.
+//
+//	go run gensymbols.go -in path/to/sigil-js/symbols.json -out symbols.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"strconv"
+
+	"github.com/deelawn/urbit-gob/co"
+)
+
+func main() {
+
+	var in, out string
+	flag.StringVar(&in, "in", "", "JSON file of the sigil-js symbols")
+	flag.StringVar(&out, "out", "symbols.go", "Go file to write")
+	flag.Parse()
+
+	if in == "" {
+		log.Fatal("no symbols: give -in, or set $SIGIL_JS_SYMBOLS for go generate")
+	}
+
+	data, err := ioutil.ReadFile(in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var symbols map[string]json.RawMessage
+	if err := json.Unmarshal(data, &symbols); err != nil {
+		log.Fatalf("%s: %v", in, err)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by gensymbols.go from the symbols of sigil-js; DO NOT EDIT.\n\n")
+	b.WriteString("package sigil\n\n")
+	b.WriteString("func init() {\n\n")
+	b.WriteString("builtinSymbols = map[string]string{\n")
+	for _, list := range [][]string{co.Prefixes, co.Suffixes} {
+		for _, syl := range list {
+			raw, ok := symbols[syl]
+			if !ok {
+				log.Fatalf("%s: no symbol for %s", in, syl)
+			}
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, raw); err != nil {
+				log.Fatalf("%s: %s: %v", in, syl, err)
+			}
+			fmt.Fprintf(&b, "%q: %s,\n", syl, strconv.Quote(compact.String()))
+		}
+	}
+	b.WriteString("}\n}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,390 @@
+package sigil
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/deelawn/urbit-gob/co"
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+// Image draws the sigil of ship, Size pixels square rounded up.
+//
+// Symbols are drawn with a subset of SVG: the g, path, circle, ellipse, rect,
+// line, polyline and polygon elements, with their fill, fill-rule, stroke,
+// stroke-width, stroke-linecap, stroke-linejoin, stroke-miterlimit and
+// transform attributes. Other elements are skipped. Colours are written as
+// #rgb, #rrggbb, rgb(r, g, b), none or one of a few basic names.
+func (s *Set) Image(ship *co.Ship, opts Options) (image.Image, error) {
+
+	symbols, err := s.layout(ship, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	n := int(math.Ceil(opts.Size))
+	d := &drawer{
+		img:     image.NewRGBA(image.Rect(0, 0, n, n)),
+		r:       rasterizer{w: n, h: n},
+		colours: strings.NewReplacer(placeholderFG, opts.Foreground, placeholderBG, opts.Background),
+	}
+
+	bg, err := parseColour(opts.Background)
+	if err != nil {
+		return nil, err
+	}
+	all := make([]float32, n*n)
+	for i := range all {
+		all[i] = 1
+	}
+	d.paint(all, bg)
+
+	for _, p := range symbols {
+		st := defaultStyle
+		st.m = affine{p.scale, 0, 0, p.scale, p.x, p.y}
+		if err := d.draw(p.symbol, st); err != nil {
+			return nil, err
+		}
+	}
+
+	return d.img, nil
+}
+
+// PNG writes the sigil of ship to w as a PNG image, as drawn by Image.
+func (s *Set) PNG(w io.Writer, ship *co.Ship, opts Options) error {
+
+	img, err := s.Image(ship, opts)
+	if err != nil {
+		return err
+	}
+
+	return png.Encode(w, img)
+}
+
+// affine is the transform (x, y) -> (a*x + c*y + e, b*x + d*y + f) of the
+// matrix a, b, c, d, e, f.
+type affine [6]float64
+
+var identity = affine{1, 0, 0, 1, 0, 0}
+
+func (m affine) apply(p point) point {
+
+	return point{m[0]*p.x + m[2]*p.y + m[4], m[1]*p.x + m[3]*p.y + m[5]}
+}
+
+// then returns the transform of n followed by m.
+func (m affine) then(n affine) affine {
+
+	return affine{
+		m[0]*n[0] + m[2]*n[1], m[1]*n[0] + m[3]*n[1],
+		m[0]*n[2] + m[2]*n[3], m[1]*n[2] + m[3]*n[3],
+		m[0]*n[4] + m[2]*n[5] + m[4], m[1]*n[4] + m[3]*n[5] + m[5],
+	}
+}
+
+// scale returns the factor by which m scales lengths, on average.
+func (m affine) scale() float64 {
+
+	return math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+}
+
+// parseTransform parses an SVG transform list.
+func parseTransform(s string) (affine, error) {
+
+	m := identity
+	sc := pathScanner{s: s}
+	for {
+		sc.skip()
+		if sc.i >= len(sc.s) {
+			return m, nil
+		}
+
+		open := strings.IndexByte(sc.s[sc.i:], '(')
+		end := strings.IndexByte(sc.s[sc.i:], ')')
+		if open < 0 || end < open {
+			return m, fmt.Errorf(ugi.ErrInvalidTransform, s)
+		}
+		name := strings.TrimSpace(sc.s[sc.i : sc.i+open])
+		args := pathScanner{s: sc.s[sc.i+open+1 : sc.i+end]}
+		sc.i += end + 1
+
+		var v []float64
+		for args.more() {
+			f, err := args.number()
+			if err != nil {
+				return m, fmt.Errorf(ugi.ErrInvalidTransform, s)
+			}
+			v = append(v, f)
+		}
+		if args.skip(); args.i < len(args.s) {
+			return m, fmt.Errorf(ugi.ErrInvalidTransform, s)
+		}
+
+		var t affine
+		switch {
+		case name == "matrix" && len(v) == 6:
+			copy(t[:], v)
+		case name == "translate" && len(v) == 1:
+			t = affine{1, 0, 0, 1, v[0], 0}
+		case name == "translate" && len(v) == 2:
+			t = affine{1, 0, 0, 1, v[0], v[1]}
+		case name == "scale" && len(v) == 1:
+			t = affine{v[0], 0, 0, v[0], 0, 0}
+		case name == "scale" && len(v) == 2:
+			t = affine{v[0], 0, 0, v[1], 0, 0}
+		case name == "rotate" && (len(v) == 1 || len(v) == 3):
+			sin, cos := math.Sincos(v[0] * math.Pi / 180)
+			t = affine{cos, sin, -sin, cos, 0, 0}
+			if len(v) == 3 {
+				t = affine{1, 0, 0, 1, v[1], v[2]}.then(t).then(affine{1, 0, 0, 1, -v[1], -v[2]})
+			}
+		case name == "skewX" && len(v) == 1:
+			t = affine{1, 0, math.Tan(v[0] * math.Pi / 180), 1, 0, 0}
+		case name == "skewY" && len(v) == 1:
+			t = affine{1, math.Tan(v[0] * math.Pi / 180), 0, 1, 0, 0}
+		default:
+			return m, fmt.Errorf(ugi.ErrInvalidTransform, s)
+		}
+		m = m.then(t)
+	}
+}
+
+// namedColours are the colour names understood besides none.
+var namedColours = map[string]color.NRGBA{
+	"black":       {0, 0, 0, 0xff},
+	"white":       {0xff, 0xff, 0xff, 0xff},
+	"red":         {0xff, 0, 0, 0xff},
+	"green":       {0, 0x80, 0, 0xff},
+	"blue":        {0, 0, 0xff, 0xff},
+	"yellow":      {0xff, 0xff, 0, 0xff},
+	"gray":        {0x80, 0x80, 0x80, 0xff},
+	"grey":        {0x80, 0x80, 0x80, 0xff},
+	"transparent": {},
+	"none":        {},
+}
+
+// parseColour parses a colour. None is transparent.
+func parseColour(s string) (color.NRGBA, error) {
+
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, ok := namedColours[s]; ok {
+		return c, nil
+	}
+
+	if strings.HasPrefix(s, "#") {
+		hex := s[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if v, err := strconv.ParseUint(hex, 16, 32); err == nil && len(hex) == 6 {
+			return color.NRGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}, nil
+		}
+	}
+
+	if strings.HasPrefix(s, "rgb(") && strings.HasSuffix(s, ")") {
+		parts := strings.Split(s[4:len(s)-1], ",")
+		var rgb [3]uint8
+		ok := len(parts) == 3
+		for i := 0; ok && i < 3; i++ {
+			v, err := strconv.ParseUint(strings.TrimSpace(parts[i]), 10, 8)
+			rgb[i], ok = uint8(v), err == nil
+		}
+		if ok {
+			return color.NRGBA{rgb[0], rgb[1], rgb[2], 0xff}, nil
+		}
+	}
+
+	return color.NRGBA{}, fmt.Errorf(ugi.ErrInvalidColour, s)
+}
+
+// style holds the presentation attributes in effect for an element, which it
+// inherits from its parent.
+type style struct {
+	fill, stroke string
+	evenOdd      bool
+	strokeWidth  float64
+	miterLimit   float64
+	lineCap      string
+	lineJoin     string
+	m            affine
+}
+
+var defaultStyle = style{
+	fill:        "black",
+	stroke:      "none",
+	strokeWidth: 1,
+	miterLimit:  4,
+	lineCap:     "butt",
+	lineJoin:    "miter",
+	m:           identity,
+}
+
+// with returns st updated by the attributes of e.
+func (st style) with(e element, colours *strings.Replacer) (style, error) {
+
+	for k, v := range e.Attributes {
+		v = strings.TrimSpace(colours.Replace(v))
+
+		var err error
+		switch k {
+		case "fill":
+			st.fill = v
+		case "stroke":
+			st.stroke = v
+		case "fill-rule":
+			st.evenOdd = v == "evenodd"
+		case "stroke-width":
+			st.strokeWidth, err = strconv.ParseFloat(strings.TrimSuffix(v, "px"), 64)
+		case "stroke-miterlimit":
+			st.miterLimit, err = strconv.ParseFloat(v, 64)
+		case "stroke-linecap":
+			st.lineCap = v
+		case "stroke-linejoin":
+			st.lineJoin = v
+		case "transform":
+			var t affine
+			if t, err = parseTransform(v); err == nil {
+				st.m = st.m.then(t)
+			}
+		}
+		if err != nil {
+			return st, fmt.Errorf(ugi.ErrInvalidAttribute, k, v)
+		}
+	}
+
+	return st, nil
+}
+
+// drawer draws the elements of symbols into img.
+type drawer struct {
+	img     *image.RGBA
+	r       rasterizer
+	colours *strings.Replacer
+}
+
+func (d *drawer) draw(e element, st style) error {
+
+	st, err := st.with(e, d.colours)
+	if err != nil {
+		return err
+	}
+
+	var data string
+	switch e.Name {
+	case "g", "svg":
+		for _, c := range e.Children {
+			if err := d.draw(c, st); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "path":
+		data = e.Attributes["d"]
+	case "circle", "ellipse", "rect", "line", "polyline", "polygon":
+		if data, err = shapePath(e); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+
+	paths, err := parsePath(data)
+	if err != nil {
+		return err
+	}
+	for i := range paths {
+		p := &paths[i]
+		p.start = st.m.apply(p.start)
+		for j := range p.segs {
+			for k := range p.segs[j].p {
+				p.segs[j].p[k] = st.m.apply(p.segs[j].p[k])
+			}
+		}
+	}
+
+	fill, err := parseColour(st.fill)
+	if err != nil {
+		return err
+	}
+	if fill.A != 0 {
+		for _, pts := range flatten(paths) {
+			d.r.addPolygon(pts, false)
+		}
+		d.paint(d.r.coverage(st.evenOdd), fill)
+	}
+
+	stroke, err := parseColour(st.stroke)
+	if err != nil {
+		return err
+	}
+	if w := st.strokeWidth * st.m.scale(); stroke.A != 0 && w > 0 {
+		d.r.stroke(paths, w, st.lineCap, st.lineJoin, st.miterLimit)
+		d.paint(d.r.coverage(false), stroke)
+	}
+
+	return nil
+}
+
+// shapePath returns the path data of a basic shape.
+func shapePath(e element) (string, error) {
+
+	a := map[string]float64{}
+	for _, k := range []string{"cx", "cy", "r", "rx", "ry", "x", "y", "width", "height", "x1", "y1", "x2", "y2"} {
+		v, ok := e.Attributes[k]
+		if !ok {
+			continue
+		}
+		f, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(v), "px"), 64)
+		if err != nil {
+			return "", fmt.Errorf(ugi.ErrInvalidAttribute, k, v)
+		}
+		a[k] = f
+	}
+
+	switch e.Name {
+	case "circle":
+		a["rx"], a["ry"] = a["r"], a["r"]
+		fallthrough
+	case "ellipse":
+		if a["rx"] <= 0 || a["ry"] <= 0 {
+			return "", nil
+		}
+		return fmt.Sprintf("M%g %gA%g %g 0 1 0 %g %gA%g %g 0 1 0 %g %gZ",
+			a["cx"]+a["rx"], a["cy"], a["rx"], a["ry"], a["cx"]-a["rx"], a["cy"], a["rx"], a["ry"], a["cx"]+a["rx"], a["cy"]), nil
+	case "rect":
+		if a["width"] <= 0 || a["height"] <= 0 {
+			return "", nil
+		}
+		return fmt.Sprintf("M%g %gh%gv%gh%gZ", a["x"], a["y"], a["width"], a["height"], -a["width"]), nil
+	case "line":
+		return fmt.Sprintf("M%g %gL%g %g", a["x1"], a["y1"], a["x2"], a["y2"]), nil
+	default:
+		d := "M" + e.Attributes["points"]
+		if e.Name == "polygon" {
+			d += "Z"
+		}
+		return d, nil
+	}
+}
+
+// paint blends c into the image in proportion to the coverage of each pixel.
+func (d *drawer) paint(cover []float32, c color.NRGBA) {
+
+	pix := d.img.Pix
+	for i, cv := range cover {
+		if cv == 0 {
+			continue
+		}
+		a := float64(cv) * float64(c.A) / 0xff
+		px := pix[i*4 : i*4+4]
+		for k, v := range [4]uint8{c.R, c.G, c.B, 0xff} {
+			px[k] = uint8(math.Round(float64(v)*a + float64(px[k])*(1-a)))
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package sigil
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/deelawn/urbit-gob/co"
+	"github.com/deelawn/urbit-gob/internal/sigiltest"
+)
+
+// symbolSet returns a set with the same symbol for every syllable.
+func symbolSet(t *testing.T, symbol string) *Set {
+
+	t.Helper()
+
+	s, err := LoadSet(strings.NewReader(sigiltest.Symbols(sigiltest.Same(symbol))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func rgba(img image.Image, x, y int) color.RGBA {
+
+	return color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+}
+
+func TestImage(t *testing.T) {
+
+	// A square filling the left half of each symbol, and a line along the
+	// middle of its right half.
+	s := symbolSet(t, `{"name": "g", "attributes": {"fill": "@FG", "stroke": "@FG"}, "children": [
+		{"name": "rect", "attributes": {"width": "64", "height": "128", "stroke": "none"}},
+		{"name": "line", "attributes": {"x1": "64", "y1": "64", "x2": "128", "y2": "64", "stroke-width": "16"}}
+	]}`)
+
+	ship, err := co.Describe("~sampel-palnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{Size: 100, Foreground: "#f00", Background: "white", Margin: 0.1}
+	img, err := s.Image(ship, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 100, 100) {
+		t.Fatalf("got bounds %v", img.Bounds())
+	}
+
+	red, white := color.RGBA{0xff, 0, 0, 0xff}, color.RGBA{0xff, 0xff, 0xff, 0xff}
+
+	// Each symbol is 40 pixels square, from 10 or 50; the line is 5 pixels
+	// wide, across the middle of the right half of the symbol.
+	tests := []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{5, 5, white},
+		{95, 95, white},
+		{12, 12, red},
+		{29, 48, red},
+		{52, 52, red},
+		{69, 89, red},
+		{40, 30, red},
+		{40, 26, white},
+		{40, 12, white},
+		{80, 30, red},
+		{80, 70, red},
+	}
+	for _, tt := range tests {
+		if got := rgba(img, tt.x, tt.y); got != tt.want {
+			t.Errorf("pixel %d, %d: got %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+
+	// The edges of the square at x = 30 and 70 fall on pixel boundaries,
+	// and those of the line at y = 27.5 and 67.5 halfway across pixels.
+	if got := rgba(img, 30, 20); got != white {
+		t.Errorf("pixel right of the square: got %v, want white", got)
+	}
+	for _, y := range []int{27, 67} {
+		if got := rgba(img, 40, y); got.G < 0x70 || got.G > 0x90 || got.R != 0xff {
+			t.Errorf("pixel on the edge of the line at y = %d: got %v, want half red", y, got)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := s.PNG(&b, ship, opts); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rgba(decoded, 12, 12); got != red {
+		t.Errorf("PNG pixel: got %v, want red", got)
+	}
+}
+
+func TestImageShapes(t *testing.T) {
+
+	ship, err := co.Describe("~zod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{Size: 128, Foreground: "black", Background: "white"}
+
+	tests := []struct {
+		symbol string
+		inside []image.Point
+		out    []image.Point
+	}{
+		// The galaxy's symbol is drawn half size at the centre, so symbol
+		// coordinates map to 32 + x/2.
+		{`{"name": "circle", "attributes": {"cx": "64", "cy": "64", "r": "32"}}`,
+			[]image.Point{{64, 64}, {64, 49}, {79, 64}}, []image.Point{{64, 46}, {40, 40}}},
+		{`{"name": "circle", "attributes": {"cx": "64", "cy": "64", "r": "32", "fill": "none", "stroke": "black", "stroke-width": "8"}}`,
+			[]image.Point{{64, 48}, {80, 64}}, []image.Point{{64, 64}, {64, 44}}},
+		{`{"name": "path", "attributes": {"d": "M0 0H128V128H0Z M32 32H96V96H32Z", "fill-rule": "evenodd"}}`,
+			[]image.Point{{33, 33}, {94, 94}}, []image.Point{{64, 64}}},
+		{`{"name": "path", "attributes": {"d": "M0 0H128V128H0Z M32 32H96V96H32Z"}}`,
+			[]image.Point{{33, 33}, {64, 64}}, []image.Point{{20, 20}}},
+		{`{"name": "polygon", "attributes": {"points": "0,0 128,0 0,128"}}`,
+			[]image.Point{{34, 34}, {60, 34}}, []image.Point{{90, 90}}},
+		{`{"name": "g", "attributes": {"transform": "translate(128 0) scale(-1 1)"}, "children": [
+			{"name": "polygon", "attributes": {"points": "0,0 128,0 0,128"}}]}`,
+			[]image.Point{{94, 34}, {68, 34}}, []image.Point{{38, 90}}},
+		{`{"name": "polyline", "attributes": {"points": "0,64 128,64", "fill": "none", "stroke": "black", "stroke-width": "4", "stroke-linecap": "square"}}`,
+			[]image.Point{{31, 64}, {96, 64}}, []image.Point{{64, 60}, {29, 64}}},
+		{`{"name": "title", "attributes": {}}`,
+			nil, []image.Point{{64, 64}}},
+	}
+
+	for _, tt := range tests {
+		img, err := symbolSet(t, tt.symbol).Image(ship, opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.symbol, err)
+		}
+		for _, p := range tt.inside {
+			if got := rgba(img, p.X, p.Y); got.R > 0x20 {
+				t.Errorf("%s: pixel %v: got %v, want black", tt.symbol, p, got)
+			}
+		}
+		for _, p := range tt.out {
+			if got := rgba(img, p.X, p.Y); got.R < 0xe0 {
+				t.Errorf("%s: pixel %v: got %v, want white", tt.symbol, p, got)
+			}
+		}
+	}
+}
+
+func TestStrokeJoins(t *testing.T) {
+
+	// A right angle with a thick stroke: a miter fills the outer corner, a
+	// bevel cuts it and a round join rounds it.
+	corner := image.Point{X: 78, Y: 21}
+	tests := []struct {
+		join  string
+		inner bool
+	}{
+		{"miter", true},
+		{"bevel", false},
+		{"round", false},
+	}
+	for _, tt := range tests {
+		r := rasterizer{w: 100, h: 100}
+		paths, err := parsePath("M20 30H70V90")
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.stroke(paths, 20, "butt", tt.join, 4)
+		cover := r.coverage(false)
+		if got := cover[corner.Y*100+corner.X] > 0.5; got != tt.inner {
+			t.Errorf("%s: corner covered %v, want %v", tt.join, got, tt.inner)
+		}
+		if c := cover[30*100+70]; c != 1 {
+			t.Errorf("%s: vertex covered %g, want 1", tt.join, c)
+		}
+		if c := cover[50*100+50]; c != 0 {
+			t.Errorf("%s: inside of the turn covered %g, want 0", tt.join, c)
+		}
+	}
+}
+
+func TestCoverage(t *testing.T) {
+
+	// A square from 1.5 to 3.5 covers half of the pixels on its edges.
+	r := rasterizer{w: 5, h: 5}
+	r.addPolygon([]point{{1.5, 1.5}, {3.5, 1.5}, {3.5, 3.5}, {1.5, 3.5}}, false)
+	cover := r.coverage(false)
+
+	want := []float32{
+		0, 0, 0, 0, 0,
+		0, 0.25, 0.5, 0.25, 0,
+		0, 0.5, 1, 0.5, 0,
+		0, 0.25, 0.5, 0.25, 0,
+		0, 0, 0, 0, 0,
+	}
+	for i, c := range cover {
+		if math.Abs(float64(c-want[i])) > 1e-6 {
+			t.Errorf("pixel %d, %d: got %g, want %g", i%5, i/5, c, want[i])
+		}
+	}
+}
+
+func TestParseColour(t *testing.T) {
+
+	tests := []struct {
+		s    string
+		want color.NRGBA
+	}{
+		{"#fff", color.NRGBA{0xff, 0xff, 0xff, 0xff}},
+		{"#12AB34", color.NRGBA{0x12, 0xab, 0x34, 0xff}},
+		{"rgb(1, 2, 3)", color.NRGBA{1, 2, 3, 0xff}},
+		{"Red", color.NRGBA{0xff, 0, 0, 0xff}},
+		{"none", color.NRGBA{}},
+	}
+	for _, tt := range tests {
+		got, err := parseColour(tt.s)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: got %v, %v, want %v", tt.s, got, err, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", "#ff", "#gggggg", "rgb(1,2)", "rgb(1,2,300)", "teal"} {
+		if _, err := parseColour(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+
+	tests := []struct {
+		s    string
+		p    point
+		want point
+	}{
+		{"translate(10 20)", point{1, 1}, point{11, 21}},
+		{"translate(10)", point{1, 1}, point{11, 1}},
+		{"scale(2)", point{1, 3}, point{2, 6}},
+		{"scale(2, -1)", point{1, 3}, point{2, -3}},
+		{"rotate(90)", point{1, 0}, point{0, 1}},
+		{"rotate(90 10 10)", point{11, 10}, point{10, 11}},
+		{"translate(10,0) scale(2)", point{1, 1}, point{12, 2}},
+		{"matrix(1 0 0 1 5 6)", point{0, 0}, point{5, 6}},
+		{"skewX(45)", point{0, 1}, point{1, 1}},
+	}
+	for _, tt := range tests {
+		m, err := parseTransform(tt.s)
+		if err != nil {
+			t.Errorf("%s: %v", tt.s, err)
+			continue
+		}
+		if got := m.apply(tt.p); math.Abs(got.x-tt.want.x) > 1e-9 || math.Abs(got.y-tt.want.y) > 1e-9 {
+			t.Errorf("%s: %v becomes %v, want %v", tt.s, tt.p, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"translate(1 2 3)", "spin(1)", "scale(", "scale(1) x"} {
+		if _, err := parseTransform(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
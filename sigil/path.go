@@ -0,0 +1,358 @@
+package sigil
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+type point struct {
+	x, y float64
+}
+
+func (p point) add(q point) point {
+
+	return point{p.x + q.x, p.y + q.y}
+}
+
+func (p point) sub(q point) point {
+
+	return point{p.x - q.x, p.y - q.y}
+}
+
+func (p point) mul(k float64) point {
+
+	return point{p.x * k, p.y * k}
+}
+
+func (p point) len() float64 {
+
+	return math.Hypot(p.x, p.y)
+}
+
+// segment is a line to p[0], or with cubic set a cubic Bézier curve through
+// the control points p[0] and p[1] to p[2].
+type segment struct {
+	cubic bool
+	p     [3]point
+}
+
+func (s segment) end() point {
+
+	if s.cubic {
+		return s.p[2]
+	}
+
+	return s.p[0]
+}
+
+// subpath is a run of segments from start.
+type subpath struct {
+	start  point
+	segs   []segment
+	closed bool
+}
+
+// pathArgs is the number of arguments each path command takes.
+var pathArgs = map[byte]int{'M': 2, 'L': 2, 'H': 1, 'V': 1, 'C': 6, 'S': 4, 'Q': 4, 'T': 2, 'A': 7, 'Z': 0}
+
+// parsePath parses SVG path data into subpaths of lines and cubic curves.
+// Quadratic curves and arcs are converted to cubic curves.
+func parsePath(d string) ([]subpath, error) {
+
+	var b pathBuilder
+	sc := pathScanner{s: d}
+
+	for {
+		sc.skip()
+		if sc.i >= len(sc.s) {
+			break
+		}
+
+		cmd := sc.s[sc.i]
+		up := cmd &^ 0x20
+		n, ok := pathArgs[up]
+		if !ok {
+			return nil, fmt.Errorf(ugi.ErrInvalidPath, d, sc.i)
+		}
+		sc.i++
+		rel := cmd != up
+
+		if up == 'Z' {
+			b.close()
+			continue
+		}
+
+		// A command is repeated while numbers follow it, a move becoming a
+		// line.
+		for first := true; first || sc.more(); first = false {
+			var args [7]float64
+			for j := 0; j < n; j++ {
+				var err error
+				if up == 'A' && (j == 3 || j == 4) {
+					args[j], err = sc.flag()
+				} else {
+					args[j], err = sc.number()
+				}
+				if err != nil {
+					return nil, fmt.Errorf(ugi.ErrInvalidPath, d, sc.i)
+				}
+			}
+
+			var base point
+			if rel {
+				base = b.cur
+			}
+			at := func(i int) point { return point{args[i], args[i+1]}.add(base) }
+
+			switch up {
+			case 'M':
+				b.moveTo(at(0))
+				up = 'L'
+			case 'L':
+				b.lineTo(at(0))
+			case 'H':
+				b.lineTo(point{args[0] + base.x, b.cur.y})
+			case 'V':
+				b.lineTo(point{b.cur.x, args[0] + base.y})
+			case 'C':
+				b.cubicTo(at(0), at(2), at(4))
+			case 'S':
+				b.cubicTo(b.reflect(true), at(0), at(2))
+			case 'Q':
+				b.quadTo(at(0), at(2))
+			case 'T':
+				b.quadTo(b.reflect(false), at(0))
+			case 'A':
+				b.arcTo(args[0], args[1], args[2], args[3] != 0, args[4] != 0, at(5))
+			}
+		}
+	}
+
+	return b.paths, nil
+}
+
+// pathScanner reads the numbers and flags of path data.
+type pathScanner struct {
+	s string
+	i int
+}
+
+func (sc *pathScanner) skip() {
+
+	for sc.i < len(sc.s) {
+		switch sc.s[sc.i] {
+		case ' ', ',', '\t', '\n', '\r', '\f':
+			sc.i++
+		default:
+			return
+		}
+	}
+}
+
+// more reports whether a number follows.
+func (sc *pathScanner) more() bool {
+
+	sc.skip()
+	if sc.i >= len(sc.s) {
+		return false
+	}
+	c := sc.s[sc.i]
+
+	return c == '+' || c == '-' || c == '.' || isDigit(c)
+}
+
+func (sc *pathScanner) number() (float64, error) {
+
+	sc.skip()
+	start := sc.i
+	if sc.i < len(sc.s) && (sc.s[sc.i] == '+' || sc.s[sc.i] == '-') {
+		sc.i++
+	}
+	digits := false
+	for sc.i < len(sc.s) && isDigit(sc.s[sc.i]) {
+		sc.i++
+		digits = true
+	}
+	if sc.i < len(sc.s) && sc.s[sc.i] == '.' {
+		sc.i++
+		for sc.i < len(sc.s) && isDigit(sc.s[sc.i]) {
+			sc.i++
+			digits = true
+		}
+	}
+	if !digits {
+		return 0, strconv.ErrSyntax
+	}
+	if sc.i < len(sc.s) && (sc.s[sc.i] == 'e' || sc.s[sc.i] == 'E') {
+		j := sc.i + 1
+		if j < len(sc.s) && (sc.s[j] == '+' || sc.s[j] == '-') {
+			j++
+		}
+		if j < len(sc.s) && isDigit(sc.s[j]) {
+			for sc.i = j; sc.i < len(sc.s) && isDigit(sc.s[sc.i]); sc.i++ {
+			}
+		}
+	}
+
+	return strconv.ParseFloat(sc.s[start:sc.i], 64)
+}
+
+// flag reads an arc flag, which need not be separated from what follows.
+func (sc *pathScanner) flag() (float64, error) {
+
+	sc.skip()
+	if sc.i < len(sc.s) && (sc.s[sc.i] == '0' || sc.s[sc.i] == '1') {
+		sc.i++
+		return float64(sc.s[sc.i-1] - '0'), nil
+	}
+
+	return 0, strconv.ErrSyntax
+}
+
+func isDigit(c byte) bool {
+
+	return c >= '0' && c <= '9'
+}
+
+// pathBuilder collects subpaths. cur is the current point, and ctrl the last
+// control point of the previous segment, for the smooth curve commands.
+type pathBuilder struct {
+	paths []subpath
+	open  bool
+	cur   point
+
+	ctrl  point
+	cubic bool
+	quad  bool
+}
+
+func (b *pathBuilder) moveTo(p point) {
+
+	b.paths = append(b.paths, subpath{start: p})
+	b.open = true
+	b.cur = p
+	b.cubic, b.quad = false, false
+}
+
+func (b *pathBuilder) add(s segment) {
+
+	// Drawing after a close starts a new subpath where the last one started.
+	if !b.open {
+		b.moveTo(b.cur)
+	}
+	last := &b.paths[len(b.paths)-1]
+	last.segs = append(last.segs, s)
+	b.cur = s.end()
+	b.cubic, b.quad = false, false
+}
+
+func (b *pathBuilder) lineTo(p point) {
+
+	b.add(segment{p: [3]point{p}})
+}
+
+func (b *pathBuilder) cubicTo(c1, c2, p point) {
+
+	b.add(segment{cubic: true, p: [3]point{c1, c2, p}})
+	b.ctrl, b.cubic = c2, true
+}
+
+func (b *pathBuilder) quadTo(q, p point) {
+
+	p0 := b.cur
+	b.add(segment{cubic: true, p: [3]point{p0.add(q.sub(p0).mul(2.0 / 3)), p.add(q.sub(p).mul(2.0 / 3)), p}})
+	b.ctrl, b.quad = q, true
+}
+
+// reflect returns the control point of a smooth curve: the last control point
+// reflected in the current point if the previous segment was a curve of the
+// same kind, else the current point.
+func (b *pathBuilder) reflect(cubic bool) point {
+
+	if cubic && b.cubic || !cubic && b.quad {
+		return b.cur.mul(2).sub(b.ctrl)
+	}
+
+	return b.cur
+}
+
+func (b *pathBuilder) close() {
+
+	if b.open {
+		last := &b.paths[len(b.paths)-1]
+		last.closed = true
+		b.cur = last.start
+	}
+	b.open = false
+	b.cubic, b.quad = false, false
+}
+
+// arcTo adds an elliptical arc to p as cubic curves, converting from the
+// endpoint to the centre parameterization as in the appendix of the SVG
+// specification.
+func (b *pathBuilder) arcTo(rx, ry, angle float64, large, sweep bool, p point) {
+
+	p0 := b.cur
+	if p0 == p {
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		b.lineTo(p)
+		return
+	}
+
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	h := p0.sub(p).mul(0.5)
+	x1, y1 := cos*h.x+sin*h.y, -sin*h.x+cos*h.y
+
+	// Radii too small to reach p are scaled up until they do.
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx, ry = rx*math.Sqrt(l), ry*math.Sqrt(l)
+	}
+
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	k := 0.0
+	if num > 0 {
+		k = math.Sqrt(num / den)
+	}
+	if large == sweep {
+		k = -k
+	}
+	cx1, cy1 := k*rx*y1/ry, -k*ry*x1/rx
+	mid := p0.add(p).mul(0.5)
+	c := point{cos*cx1 - sin*cy1 + mid.x, sin*cx1 + cos*cy1 + mid.y}
+
+	angleOf := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	theta := angleOf(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	delta := angleOf((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	// Each quarter turn or less is one cubic curve.
+	n := int(math.Ceil(math.Abs(delta) / (math.Pi / 2)))
+	step := delta / float64(n)
+	t := 4.0 / 3 * math.Tan(step/4)
+	onEllipse := func(ux, uy float64) point {
+		return point{c.x + rx*ux*cos - ry*uy*sin, c.y + rx*ux*sin + ry*uy*cos}
+	}
+	for i := 0; i < n; i++ {
+		a0, a1 := theta+float64(i)*step, theta+float64(i+1)*step
+		s0, c0 := math.Sincos(a0)
+		s1, c1 := math.Sincos(a1)
+		end := onEllipse(c1, s1)
+		if i == n-1 {
+			end = p
+		}
+		b.cubicTo(onEllipse(c0-t*s0, s0+t*c0), onEllipse(c1+t*s1, s1-t*c1), end)
+	}
+}
@@ -0,0 +1,91 @@
+package sigil
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// describe renders subpaths as a string of their end points, rounded, with
+// C before the end of a curve and Z for closed subpaths.
+func describe(paths []subpath) string {
+
+	var parts []string
+	for _, sp := range paths {
+		s := fmt.Sprintf("%.4g,%.4g", sp.start.x, sp.start.y)
+		for _, seg := range sp.segs {
+			e := seg.end()
+			if seg.cubic {
+				s += " C"
+			} else {
+				s += " "
+			}
+			s += fmt.Sprintf("%.4g,%.4g", e.x+0, e.y+0)
+		}
+		if sp.closed {
+			s += " Z"
+		}
+		parts = append(parts, s)
+	}
+
+	return strings.Join(parts, " | ")
+}
+
+func TestParsePath(t *testing.T) {
+
+	tests := []struct {
+		d    string
+		want string
+	}{
+		{"M0 0L10 0L10 10Z", "0,0 10,0 10,10 Z"},
+		{"M 1,2 3,4 5,6", "1,2 3,4 5,6"},
+		{"m1 2 3 4l1 1", "1,2 4,6 5,7"},
+		{"M0 0h10v10H0V0z", "0,0 10,0 10,10 0,10 0,0 Z"},
+		{"M0 0h10zl5 5", "0,0 10,0 Z | 0,0 5,5"},
+		{"M0 0h10zm5 5h1", "0,0 10,0 Z | 5,5 6,5"},
+		{"M0 0C1 1 2 2 3 3S5 5 6 6", "0,0 C3,3 C6,6"},
+		{"M0 0Q5 5 10 0T20 0", "0,0 C10,0 C20,0"},
+		{"M-1.5-2.5.5.5", "-1.5,-2.5 0.5,0.5"},
+		{"M1e1 2E-1", "10,0.2"},
+		{"M0 0A5 5 0 0 1 10 0", "0,0 C5,-5 C10,0"},
+		{"M0 0a5 5 0 1110 0", "0,0 C5,-5 C10,0"},
+		{"M0 0A5 5 0 0 0 10 0", "0,0 C5,5 C10,0"},
+		{"M0 0A1 1 0 0 1 10 0", "0,0 C5,-5 C10,0"},
+		{"M0 0A0 5 0 0 1 10 0", "0,0 10,0"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		paths, err := parsePath(tt.d)
+		if err != nil {
+			t.Errorf("%q: %v", tt.d, err)
+			continue
+		}
+		if got := describe(paths); got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.d, got, tt.want)
+		}
+	}
+
+	for _, d := range []string{"0 0", "M0", "M0 0L", "M0 0X1 1", "M0 0A1 1 0 2 0 1 1", "M0 0L1 1 2"} {
+		if _, err := parsePath(d); err == nil {
+			t.Errorf("%q: expected an error", d)
+		}
+	}
+}
+
+func TestArc(t *testing.T) {
+
+	// Every point of a flattened circle should be on it.
+	paths, err := parsePath("M60 50A10 10 0 1 0 40 50A10 10 0 1 0 60 50Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pts := range flatten(paths) {
+		for _, p := range pts {
+			if r := math.Hypot(p.x-50, p.y-50); math.Abs(r-10) > 0.05 {
+				t.Fatalf("%v is %g from the centre, want 10", p, r)
+			}
+		}
+	}
+}
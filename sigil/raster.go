@@ -0,0 +1,298 @@
+package sigil
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// subrows is the number of rows sampled in each row of pixels. Along a
+	// row coverage is exact, so edges are anti-aliased in both directions.
+	subrows = 4
+
+	// flatness is the greatest distance in pixels between a curve and the
+	// lines it is drawn with.
+	flatness = 0.1
+)
+
+// edge is an edge of a polygon, with y0 < y1 and dir the winding it adds.
+type edge struct {
+	x0, y0, x1, y1 float64
+	dir            int
+}
+
+// rasterizer accumulates polygons and computes the share of each pixel they
+// cover.
+type rasterizer struct {
+	w, h  int
+	edges []edge
+}
+
+// addPolygon adds the closed polygon pts. With positive set its points are
+// put in clockwise order first, so that under the nonzero rule a group of
+// such polygons covers their union.
+func (r *rasterizer) addPolygon(pts []point, positive bool) {
+
+	if len(pts) < 3 {
+		return
+	}
+
+	reverse := false
+	if positive {
+		area := 0.0
+		for i, p := range pts {
+			q := pts[(i+1)%len(pts)]
+			area += p.x*q.y - q.x*p.y
+		}
+		reverse = area < 0
+	}
+
+	for i, p := range pts {
+		q := pts[(i+1)%len(pts)]
+		if reverse {
+			p, q = q, p
+		}
+		switch {
+		case p.y < q.y:
+			r.edges = append(r.edges, edge{p.x, p.y, q.x, q.y, 1})
+		case p.y > q.y:
+			r.edges = append(r.edges, edge{q.x, q.y, p.x, p.y, -1})
+		}
+	}
+}
+
+// crossing is where an edge crosses a row of samples.
+type crossing struct {
+	x   float64
+	dir int
+}
+
+// coverage returns the share of each pixel covered by the polygons added
+// since the last call, under the nonzero winding rule or with evenOdd the
+// even-odd rule.
+func (r *rasterizer) coverage(evenOdd bool) []float32 {
+
+	cover := make([]float32, r.w*r.h)
+	edges := r.edges
+	r.edges = nil
+
+	sort.Slice(edges, func(i, j int) bool { return edges[i].y0 < edges[j].y0 })
+
+	var active []edge
+	var xs []crossing
+	next := 0
+	for row := 0; row < r.h*subrows; row++ {
+		y := (float64(row) + 0.5) / subrows
+
+		for ; next < len(edges) && edges[next].y0 <= y; next++ {
+			active = append(active, edges[next])
+		}
+		xs = xs[:0]
+		kept := active[:0]
+		for _, e := range active {
+			if e.y1 <= y {
+				continue
+			}
+			kept = append(kept, e)
+			if e.y0 <= y {
+				xs = append(xs, crossing{e.x0 + (y-e.y0)*(e.x1-e.x0)/(e.y1-e.y0), e.dir})
+			}
+		}
+		active = kept
+		sort.Slice(xs, func(i, j int) bool { return xs[i].x < xs[j].x })
+
+		line := cover[(row/subrows)*r.w : (row/subrows+1)*r.w]
+		winding := 0
+		for i, c := range xs {
+			winding += c.dir
+			inside := winding != 0
+			if evenOdd {
+				inside = winding%2 != 0
+			}
+			if inside && i+1 < len(xs) {
+				addSpan(line, c.x, xs[i+1].x)
+			}
+		}
+	}
+
+	for i, c := range cover {
+		if c > 1 {
+			cover[i] = 1
+		}
+	}
+
+	return cover
+}
+
+// addSpan adds a row of samples covering [x0, x1) to line.
+func addSpan(line []float32, x0, x1 float64) {
+
+	x0 = math.Max(x0, 0)
+	x1 = math.Min(x1, float64(len(line)))
+	if x0 >= x1 {
+		return
+	}
+
+	const share = 1.0 / subrows
+	first, last := int(x0), int(math.Ceil(x1))-1
+	if first == last {
+		line[first] += float32((x1 - x0) * share)
+		return
+	}
+	line[first] += float32((float64(first+1) - x0) * share)
+	for i := first + 1; i < last; i++ {
+		line[i] += share
+	}
+	line[last] += float32((x1 - float64(last)) * share)
+}
+
+// flatten returns the points of each subpath, with curves replaced by lines.
+func flatten(paths []subpath) [][]point {
+
+	var out [][]point
+	for _, sp := range paths {
+		pts := []point{sp.start}
+		cur := sp.start
+		for _, s := range sp.segs {
+			if !s.cubic {
+				pts = append(pts, s.p[0])
+				cur = s.p[0]
+				continue
+			}
+
+			// The distance of the lines from the curve is at most 3/4 of its
+			// second differences over the square of the number of lines.
+			dd := math.Max(cur.sub(s.p[0].mul(2)).add(s.p[1]).len(), s.p[0].sub(s.p[1].mul(2)).add(s.p[2]).len())
+			n := int(math.Ceil(math.Sqrt(0.75 * dd / flatness)))
+			if n < 1 {
+				n = 1
+			} else if n > 1000 {
+				n = 1000
+			}
+			for i := 1; i <= n; i++ {
+				t := float64(i) / float64(n)
+				u := 1 - t
+				pts = append(pts, point{
+					u*u*u*cur.x + 3*u*u*t*s.p[0].x + 3*u*t*t*s.p[1].x + t*t*t*s.p[2].x,
+					u*u*u*cur.y + 3*u*u*t*s.p[0].y + 3*u*t*t*s.p[1].y + t*t*t*s.p[2].y,
+				})
+			}
+			cur = s.p[2]
+		}
+		out = append(out, pts)
+	}
+
+	return out
+}
+
+// stroke adds the outline of a line of width w along each subpath to r, with
+// the given caps and joins.
+func (r *rasterizer) stroke(paths []subpath, w float64, lineCap, join string, miterLimit float64) {
+
+	hw := w / 2
+	for i, pts := range flatten(paths) {
+		closed := paths[i].closed
+
+		// Points repeated, as at the end of a closed subpath, give no
+		// direction.
+		var ps []point
+		for _, p := range pts {
+			if len(ps) == 0 || p.sub(ps[len(ps)-1]).len() > 1e-9 {
+				ps = append(ps, p)
+			}
+		}
+		if closed && len(ps) > 1 && ps[0].sub(ps[len(ps)-1]).len() <= 1e-9 {
+			ps = ps[:len(ps)-1]
+		}
+
+		if len(ps) == 1 {
+			switch lineCap {
+			case "round":
+				r.addPolygon(circle(ps[0], hw), true)
+			case "square":
+				p := ps[0]
+				r.addPolygon([]point{{p.x - hw, p.y - hw}, {p.x + hw, p.y - hw}, {p.x + hw, p.y + hw}, {p.x - hw, p.y + hw}}, true)
+			}
+			continue
+		}
+
+		n := len(ps) - 1
+		if closed {
+			n = len(ps)
+		}
+		for j := 0; j < n; j++ {
+			a, b := ps[j], ps[(j+1)%len(ps)]
+			d := b.sub(a).mul(1 / b.sub(a).len())
+			if !closed && lineCap == "square" {
+				if j == 0 {
+					a = a.sub(d.mul(hw))
+				}
+				if j == n-1 {
+					b = b.add(d.mul(hw))
+				}
+			}
+			off := point{-d.y, d.x}.mul(hw)
+			r.addPolygon([]point{a.add(off), b.add(off), b.sub(off), a.sub(off)}, true)
+		}
+
+		if !closed && lineCap == "round" {
+			r.addPolygon(circle(ps[0], hw), true)
+			r.addPolygon(circle(ps[len(ps)-1], hw), true)
+		}
+
+		for j := 0; j < len(ps); j++ {
+			if !closed && (j == 0 || j == len(ps)-1) {
+				continue
+			}
+			prev, v, next := ps[(j+len(ps)-1)%len(ps)], ps[j], ps[(j+1)%len(ps)]
+			r.join(prev, v, next, hw, join, miterLimit)
+		}
+	}
+}
+
+// join adds the join at v between the segments from prev and to next.
+func (r *rasterizer) join(prev, v, next point, hw float64, join string, miterLimit float64) {
+
+	if join == "round" {
+		r.addPolygon(circle(v, hw), true)
+		return
+	}
+
+	d1 := v.sub(prev).mul(1 / v.sub(prev).len())
+	d2 := next.sub(v).mul(1 / next.sub(v).len())
+	n1, n2 := point{-d1.y, d1.x}, point{-d2.y, d2.x}
+
+	// Only the outer side of the turn needs filling; the inner one lies
+	// within the segments.
+	side := 1.0
+	if d1.x*d2.y-d1.y*d2.x > 0 {
+		side = -1
+	}
+	a, b := v.add(n1.mul(side*hw)), v.add(n2.mul(side*hw))
+	cosTheta := n1.x*n2.x + n1.y*n2.y
+	if join == "miter" && cosTheta > -1+1e-9 && math.Sqrt(2/(1+cosTheta)) <= miterLimit {
+		tip := v.add(n1.add(n2).mul(side * hw / (1 + cosTheta)))
+		r.addPolygon([]point{v, a, tip, b}, true)
+	} else {
+		r.addPolygon([]point{v, a, b}, true)
+	}
+}
+
+// circle returns a polygon close to the circle of radius r about c.
+func circle(c point, r float64) []point {
+
+	n := int(math.Ceil(2 * math.Pi * r / 2))
+	if n < 8 {
+		n = 8
+	} else if n > 128 {
+		n = 128
+	}
+
+	pts := make([]point, n)
+	for i := range pts {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(n))
+		pts[i] = point{c.x + r*cos, c.y + r*sin}
+	}
+
+	return pts
+}
@@ -0,0 +1,267 @@
+/*
+Package sigil draws the sigil of a ship, the glyph for each of its syllables
+laid out on a grid, as SVG.
+
+The glyphs are those of sigil-js. Builtin returns them once they have been
+generated into symbols.go by go generate, which reads the symbol data of
+sigil-js: a JSON object mapping each of the 512 syllables to its symbol, an SVG
+element written as an object of name, attributes and children, drawn in a
+square of SymbolSize units. LoadSet reads another set in the same form.
+Attribute values @FG and @BG in the symbols stand for the foreground and
+background colours.
+
+A planet's four syllables fill a 2x2 grid. A star's two fill its middle row
+and a galaxy's one its centre, so that symbols are drawn at the same size for
+every class. Moons and comets, whose names are too long for the grid, are
+drawn in the reduced form of their last four syllables, as a planet.
+*/
+package sigil
+
+//go:generate go run gensymbols.go -in $SIGIL_JS_SYMBOLS -out symbols.go
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/deelawn/urbit-gob/co"
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+const (
+	// SymbolSize is the width and height of the square each symbol is drawn
+	// in, in the units of its coordinates.
+	SymbolSize float64 = 128
+
+	// Placeholders for the colours in symbol attributes
+	placeholderFG string = "@FG"
+	placeholderBG string = "@BG"
+)
+
+// Options choose how a sigil is drawn.
+type Options struct {
+	// Size is the width and height of the sigil in pixels.
+	Size float64
+
+	// Foreground and Background are the colours of the symbols and of the
+	// square behind them, in any form SVG accepts.
+	Foreground string
+	Background string
+
+	// Margin is the border left around the grid, as a fraction of Size. It
+	// must be less than half.
+	Margin float64
+}
+
+// DefaultOptions are white symbols on black, 128 pixels square with a margin
+// of an eighth on each side.
+var DefaultOptions = Options{
+	Size:       128,
+	Foreground: "#ffffff",
+	Background: "#000000",
+	Margin:     0.125,
+}
+
+// element is an SVG element of a symbol.
+type element struct {
+	Name       string            `json:"name"`
+	Attributes map[string]string `json:"attributes"`
+	Children   []element         `json:"children"`
+	Value      string            `json:"value"`
+}
+
+// Set is a set of symbols, one for each syllable.
+type Set struct {
+	symbols map[string]element
+}
+
+// LoadSet reads a set of symbols.
+func LoadSet(r io.Reader) (*Set, error) {
+
+	var symbols map[string]element
+	if err := json.NewDecoder(r).Decode(&symbols); err != nil {
+		return nil, fmt.Errorf(ugi.ErrInvalidSymbols, err)
+	}
+
+	return &Set{symbols: symbols}, nil
+}
+
+// builtinSymbols holds the JSON of the symbol of each syllable, as generated
+// into symbols.go from sigil-js. It is empty until they are generated.
+var builtinSymbols map[string]string
+
+var (
+	builtinOnce sync.Once
+	builtinSet  *Set
+	builtinErr  error
+)
+
+// Builtin returns the symbols of sigil-js built into the package.
+func Builtin() (*Set, error) {
+
+	builtinOnce.Do(func() {
+		if len(builtinSymbols) == 0 {
+			builtinErr = fmt.Errorf(ugi.ErrNoBuiltinSymbols)
+			return
+		}
+
+		symbols := make(map[string]element, len(builtinSymbols))
+		for syl, data := range builtinSymbols {
+			var e element
+			if err := json.Unmarshal([]byte(data), &e); err != nil {
+				builtinErr = fmt.Errorf(ugi.ErrInvalidSymbols, err)
+				return
+			}
+			symbols[syl] = e
+		}
+		builtinSet = &Set{symbols: symbols}
+	})
+
+	return builtinSet, builtinErr
+}
+
+// Missing returns the syllables that s has no symbol for, in order.
+func (s *Set) Missing() []string {
+
+	var missing []string
+	for _, list := range [][]string{co.Prefixes, co.Suffixes} {
+		for _, syl := range list {
+			if _, ok := s.symbols[syl]; !ok {
+				missing = append(missing, syl)
+			}
+		}
+	}
+
+	return missing
+}
+
+// cell is the position of a symbol on the 2x2 grid, in units of half its
+// width, so that a symbol can sit between columns or rows.
+type cell struct {
+	x, y float64
+}
+
+// layouts are the cells of the symbols of a galaxy, a star and a planet, by
+// the number of syllables.
+var layouts = map[int][]cell{
+	1: {{0.5, 0.5}},
+	2: {{0, 0.5}, {1, 0.5}},
+	4: {{0, 0}, {1, 0}, {0, 1}, {1, 1}},
+}
+
+// Syllables returns the syllables drawn in the sigil of a ship, in order.
+func Syllables(ship *co.Ship) []string {
+
+	var syls []string
+	for _, w := range strings.Split(strings.TrimPrefix(ship.Patp, "~"), "-") {
+		for i := 0; i+3 <= len(w); i += 3 {
+			syls = append(syls, w[i:i+3])
+		}
+	}
+
+	if len(syls) > 4 {
+		syls = syls[len(syls)-4:]
+	}
+
+	return syls
+}
+
+// placed is a symbol at its place in a sigil: scaled by scale, then moved to
+// x, y.
+type placed struct {
+	symbol      element
+	x, y, scale float64
+}
+
+// layout returns the symbols of the sigil of ship in their places.
+func (s *Set) layout(ship *co.Ship, opts Options) ([]placed, error) {
+
+	if opts.Size <= 0 || opts.Margin < 0 || opts.Margin >= 0.5 {
+		return nil, fmt.Errorf(ugi.ErrInvalidSigilOptions, opts.Size, opts.Margin)
+	}
+
+	tile := opts.Size * (1 - 2*opts.Margin) / 2
+	margin := opts.Size * opts.Margin
+
+	syls := Syllables(ship)
+	cells := layouts[len(syls)]
+	symbols := make([]placed, len(syls))
+	for i, syl := range syls {
+		e, ok := s.symbols[syl]
+		if !ok {
+			return nil, fmt.Errorf(ugi.ErrNoSymbol, syl)
+		}
+		symbols[i] = placed{e, margin + cells[i].x*tile, margin + cells[i].y*tile, tile / SymbolSize}
+	}
+
+	return symbols, nil
+}
+
+// SVG writes the sigil of ship to w.
+func (s *Set) SVG(w io.Writer, ship *co.Ship, opts Options) error {
+
+	symbols, err := s.layout(ship, opts)
+	if err != nil {
+		return err
+	}
+
+	colours := strings.NewReplacer(placeholderFG, opts.Foreground, placeholderBG, opts.Background)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n",
+		num(opts.Size), num(opts.Size), num(opts.Size), num(opts.Size))
+	fmt.Fprintf(&b, `<rect width="%s" height="%s" fill="%s"/>`+"\n", num(opts.Size), num(opts.Size), escape(opts.Background))
+	for _, p := range symbols {
+		fmt.Fprintf(&b, `<g transform="translate(%s %s) scale(%s)">`, num(p.x), num(p.y), num(p.scale))
+		writeElement(&b, p.symbol, colours)
+		b.WriteString("</g>\n")
+	}
+	b.WriteString("</svg>\n")
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// writeElement writes e with its attributes in order, replacing the colour
+// placeholders in their values.
+func writeElement(b *strings.Builder, e element, colours *strings.Replacer) {
+
+	keys := make([]string, 0, len(e.Attributes))
+	for k := range e.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b.WriteString("<" + e.Name)
+	for _, k := range keys {
+		fmt.Fprintf(b, ` %s="%s"`, k, escape(colours.Replace(e.Attributes[k])))
+	}
+	if len(e.Children) == 0 && e.Value == "" {
+		b.WriteString("/>")
+		return
+	}
+	b.WriteString(">")
+	b.WriteString(escape(e.Value))
+	for _, c := range e.Children {
+		writeElement(b, c, colours)
+	}
+	b.WriteString("</" + e.Name + ">")
+}
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+func escape(s string) string {
+
+	return escaper.Replace(s)
+}
+
+// num formats a coordinate to four places, without trailing zeros.
+func num(f float64) string {
+
+	return strconv.FormatFloat(math.Round(f*1e4)/1e4, 'f', -1, 64)
+}
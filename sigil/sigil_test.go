@@ -0,0 +1,254 @@
+package sigil
+
+import (
+	"encoding/xml"
+	"image"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/deelawn/urbit-gob/co"
+	"github.com/deelawn/urbit-gob/internal/sigiltest"
+)
+
+// testSet returns a set whose symbol for each syllable is a path naming it,
+// filled with the foreground colour.
+func testSet(t *testing.T, skip string) *Set {
+
+	t.Helper()
+
+	data := sigiltest.Symbols(func(syl string) string {
+		if syl == skip {
+			return ""
+		}
+		return `{"name": "g", "children": [{"name": "path", "attributes": {"d": "M0 0L128 128", "id": "` + syl + `", "fill": "@FG", "stroke": "@BG"}}]}`
+	})
+	s, err := LoadSet(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestSVG(t *testing.T) {
+
+	s := testSet(t, "")
+	if m := s.Missing(); len(m) != 0 {
+		t.Fatalf("missing %v", m)
+	}
+
+	tests := []struct {
+		name  string
+		opts  Options
+		syls  []string
+		cells []string
+	}{
+		{"~zod", DefaultOptions, []string{"zod"}, []string{"translate(40 40) scale(0.375)"}},
+		{"~marzod", DefaultOptions, []string{"mar", "zod"}, []string{
+			"translate(16 40) scale(0.375)",
+			"translate(64 40) scale(0.375)",
+		}},
+		{"~sampel-palnet", DefaultOptions, []string{"sam", "pel", "pal", "net"}, []string{
+			"translate(16 16) scale(0.375)",
+			"translate(64 16) scale(0.375)",
+			"translate(16 64) scale(0.375)",
+			"translate(64 64) scale(0.375)",
+		}},
+		{"~doznec-sampel-palnet", Options{Size: 256, Foreground: "red", Background: "#fff"}, []string{"sam", "pel", "pal", "net"}, []string{
+			"translate(0 0) scale(1)",
+			"translate(128 0) scale(1)",
+			"translate(0 128) scale(1)",
+			"translate(128 128) scale(1)",
+		}},
+	}
+
+	for _, tt := range tests {
+		ship, err := co.Describe(tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := Syllables(ship); strings.Join(got, " ") != strings.Join(tt.syls, " ") {
+			t.Errorf("%s: got syllables %v, want %v", tt.name, got, tt.syls)
+		}
+
+		var b strings.Builder
+		if err := s.SVG(&b, ship, tt.opts); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		svg := b.String()
+
+		size := num(tt.opts.Size)
+		want := []string{
+			`width="` + size + `" height="` + size + `" viewBox="0 0 ` + size + " " + size + `"`,
+			`<rect width="` + size + `" height="` + size + `" fill="` + tt.opts.Background + `"/>`,
+		}
+		for i, c := range tt.cells {
+			want = append(want, `<g transform="`+c+`"><g><path d="M0 0L128 128" fill="`+tt.opts.Foreground+
+				`" id="`+tt.syls[i]+`" stroke="`+tt.opts.Background+`"/></g></g>`)
+		}
+		for _, w := range want {
+			if !strings.Contains(svg, w) {
+				t.Errorf("%s: SVG does not contain %q:\n%s", tt.name, w, svg)
+			}
+		}
+		if n := strings.Count(svg, "<path"); n != len(tt.cells) {
+			t.Errorf("%s: got %d symbols, want %d", tt.name, n, len(tt.cells))
+		}
+	}
+}
+
+func TestSVGErrors(t *testing.T) {
+
+	ship, err := co.Describe("~sampel-palnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := testSet(t, "pal")
+	if m := s.Missing(); len(m) != 1 || m[0] != "pal" {
+		t.Errorf("got missing %v, want [pal]", m)
+	}
+	var b strings.Builder
+	if err := s.SVG(&b, ship, DefaultOptions); err == nil {
+		t.Error("expected an error for a missing symbol")
+	}
+
+	s = testSet(t, "")
+	for _, opts := range []Options{{Size: 0}, {Size: 64, Margin: 0.5}, {Size: 64, Margin: -1}} {
+		if err := s.SVG(&b, ship, opts); err == nil {
+			t.Errorf("%+v: expected an error", opts)
+		}
+	}
+
+	if _, err := LoadSet(strings.NewReader(`{"zod": 1}`)); err == nil {
+		t.Error("expected an error for invalid symbols")
+	}
+}
+
+// decodeSVG reads an SVG document as an element tree.
+func decodeSVG(r io.Reader) (element, error) {
+
+	d := xml.NewDecoder(r)
+	var open []element
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return element{}, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			e := element{Name: tok.Name.Local, Attributes: map[string]string{}}
+			for _, a := range tok.Attr {
+				e.Attributes[a.Name.Local] = a.Value
+			}
+			open = append(open, e)
+		case xml.EndElement:
+			e := open[len(open)-1]
+			open = open[:len(open)-1]
+			if len(open) == 0 {
+				return e, nil
+			}
+			open[len(open)-1].Children = append(open[len(open)-1].Children, e)
+		}
+	}
+}
+
+// drawSVG draws the SVG document at path n pixels square, scaling its
+// viewBox to fit.
+func drawSVG(t *testing.T, path string, n int) image.Image {
+
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	root, err := decodeSVG(f)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+
+	st := defaultStyle
+	if box := strings.Fields(root.Attributes["viewBox"]); len(box) == 4 {
+		w, err := strconv.ParseFloat(box[2], 64)
+		if err != nil || w <= 0 {
+			t.Fatalf("%s: invalid viewBox %q", path, root.Attributes["viewBox"])
+		}
+		st.m = affine{float64(n) / w, 0, 0, float64(n) / w, 0, 0}
+	}
+
+	d := &drawer{
+		img:     image.NewRGBA(image.Rect(0, 0, n, n)),
+		r:       rasterizer{w: n, h: n},
+		colours: strings.NewReplacer(),
+	}
+	if err := d.draw(root, st); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+
+	return d.img
+}
+
+// difference returns the mean difference of the colours of a and b, from 0
+// for the same image to 1.
+func difference(a, b image.Image) float64 {
+
+	r := a.Bounds()
+	if r != b.Bounds() {
+		return 1
+	}
+
+	var sum float64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			p, q := rgba(a, x, y), rgba(b, x, y)
+			sum += math.Abs(float64(p.R)-float64(q.R)) + math.Abs(float64(p.G)-float64(q.G)) + math.Abs(float64(p.B)-float64(q.B))
+		}
+	}
+
+	return sum / float64(3*255*r.Dx()*r.Dy())
+}
+
+// TestReference compares sigils drawn with the built-in symbols against those
+// drawn by sigil-js. The reference sigils in testdata/reference are SVG files
+// made with sigil-js, white on black and 128 pixels square, each named after
+// its ship, such as sampel-palnet.svg. They are drawn with the package's own
+// rasterizer, so that only the symbols and their layout are compared.
+func TestReference(t *testing.T) {
+
+	s, err := Builtin()
+	if err != nil {
+		t.Skip(err)
+	}
+	paths, err := filepath.Glob(filepath.Join("testdata", "reference", "*.svg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Skip("no reference sigils in testdata/reference")
+	}
+
+	for _, path := range paths {
+		name := "~" + strings.TrimSuffix(filepath.Base(path), ".svg")
+		ship, err := co.Describe(name)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		got, err := s.Image(ship, DefaultOptions)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		want := drawSVG(t, path, int(DefaultOptions.Size))
+		if d := difference(got, want); d > 0.01 {
+			t.Errorf("%s: differs from the sigil-js sigil by %.4f", name, d)
+		}
+	}
+}